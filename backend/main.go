@@ -78,7 +78,7 @@ func main() {
 		loggerInstance.Panic("Error initializing application context", zap.Error(err))
 	}
 
-	// Close relation resource
+	// Close relation resource, runs after server shutdown so in-flight event handlers are drained before db closed
 	defer func() {
 		if err := appContext.Close(); err != nil {
 			loggerInstance.Error("Error closing application context", zap.Error(err))
@@ -94,6 +94,9 @@ func main() {
 	// setup scheduler
 	appContext.TaskScheduler.Start()
 
+	// setup outbox relay
+	appContext.OutboxRelay.Start()
+
 	// Start the server in a goroutine to enable capturing the shutdown signal
 	go func() {
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
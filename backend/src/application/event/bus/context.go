@@ -0,0 +1,19 @@
+package bus
+
+import "context"
+
+type correlationIDKey struct{}
+
+// WithCorrelationID 在 ctx 中携带关联ID，发布事件时写入消息，用于串联同一请求触发的事件
+func WithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, correlationID)
+}
+
+// CorrelationIDFromContext 读取 ctx 中的关联ID，没有时返回空字符串
+func CorrelationIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	correlationID, _ := ctx.Value(correlationIDKey{}).(string)
+	return correlationID
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/gbrayhan/microservices-go/src/application/event/model"
+	domainEventBus "github.com/gbrayhan/microservices-go/src/domain/sys/event_bus"
 )
 
 // EventBus 事件总线接口
@@ -12,3 +13,14 @@ type EventBus interface {
 	Subscribe(eventType string, handler model.EventHandler) error
 	Unsubscribe(eventType string, handler model.EventHandler) error
 }
+
+// SyncPublisher 同步执行订阅者并返回其错误，outbox relay 据此决定是否重试
+type SyncPublisher interface {
+	PublishSync(ctx context.Context, event model.ApplicationEvent) error
+}
+
+// DeadLetterStore 支持死信队列的事件总线实现
+type DeadLetterStore interface {
+	ListDeadLetters(limit int) ([]domainEventBus.DeadLetter, error)
+	ReplayDeadLetters(messageIDs []string, limit int) (int, error)
+}
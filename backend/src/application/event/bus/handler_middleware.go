@@ -0,0 +1,159 @@
+package bus
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/gbrayhan/microservices-go/src/application/event/model"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"go.uber.org/zap"
+)
+
+// HandlerFunc 函数形式的事件处理器
+type HandlerFunc func(event model.ApplicationEvent) error
+
+// Handle 执行处理函数
+func (f HandlerFunc) Handle(event model.ApplicationEvent) error {
+	return f(event)
+}
+
+// HandlerMiddleware 包装事件处理器，用于恢复 panic、重试、日志和指标
+type HandlerMiddleware func(next model.EventHandler) model.EventHandler
+
+// chainMiddlewares 按顺序包装，第一个中间件在最外层
+func chainMiddlewares(handler model.EventHandler, middlewares ...HandlerMiddleware) model.EventHandler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// RecoverMiddleware 把处理器中的 panic 转换为错误，避免拖垮 worker
+func RecoverMiddleware(loggerInstance *logger.Logger) HandlerMiddleware {
+	return func(next model.EventHandler) model.EventHandler {
+		return HandlerFunc(func(event model.ApplicationEvent) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					loggerInstance.Error("Event handler panicked",
+						zap.String("eventType", event.EventType()),
+						zap.String("eventID", event.EventID()),
+						zap.Any("panic", r),
+						zap.ByteString("stack", debug.Stack()))
+					err = fmt.Errorf("event handler panic: %v", r)
+				}
+			}()
+			return next.Handle(event)
+		})
+	}
+}
+
+// RetryMiddleware 处理失败后最多重试 maxRetries 次，间隔从 interval 开始翻倍；
+// stop 关闭后不再等待重试，直接返回最后一次的错误，stop 为 nil 时一直重试
+func RetryMiddleware(maxRetries int, interval time.Duration, stop <-chan struct{}) HandlerMiddleware {
+	return func(next model.EventHandler) model.EventHandler {
+		if maxRetries <= 0 {
+			return next
+		}
+		return HandlerFunc(func(event model.ApplicationEvent) error {
+			delay := interval
+			err := next.Handle(event)
+			for attempt := 0; err != nil && attempt < maxRetries; attempt++ {
+				timer := time.NewTimer(delay)
+				select {
+				case <-timer.C:
+				case <-stop:
+					timer.Stop()
+					return err
+				}
+				delay *= 2
+				err = next.Handle(event)
+			}
+			return err
+		})
+	}
+}
+
+// LoggingMiddleware 记录处理耗时和错误
+func LoggingMiddleware(loggerInstance *logger.Logger) HandlerMiddleware {
+	return func(next model.EventHandler) model.EventHandler {
+		return HandlerFunc(func(event model.ApplicationEvent) error {
+			start := time.Now()
+			err := next.Handle(event)
+			if err != nil {
+				loggerInstance.Error("Error handling event",
+					zap.String("eventType", event.EventType()),
+					zap.String("eventID", event.EventID()),
+					zap.Duration("duration", time.Since(start)),
+					zap.Error(err))
+				return err
+			}
+			loggerInstance.Debug("Event handled successfully",
+				zap.String("eventType", event.EventType()),
+				zap.String("eventID", event.EventID()),
+				zap.Duration("duration", time.Since(start)))
+			return nil
+		})
+	}
+}
+
+// HandlerStats 某个事件类型的处理统计
+type HandlerStats struct {
+	Handled       int64         `json:"handled"`
+	Failed        int64         `json:"failed"`
+	TotalDuration time.Duration `json:"total_duration"`
+	MaxDuration   time.Duration `json:"max_duration"`
+}
+
+// HandlerMetrics 按事件类型累计处理次数、失败次数和耗时
+type HandlerMetrics struct {
+	mutex sync.Mutex
+	stats map[string]*HandlerStats
+}
+
+// NewHandlerMetrics 创建处理器指标
+func NewHandlerMetrics() *HandlerMetrics {
+	return &HandlerMetrics{stats: make(map[string]*HandlerStats)}
+}
+
+func (m *HandlerMetrics) observe(eventType string, duration time.Duration, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	stats, exists := m.stats[eventType]
+	if !exists {
+		stats = &HandlerStats{}
+		m.stats[eventType] = stats
+	}
+	stats.Handled++
+	if err != nil {
+		stats.Failed++
+	}
+	stats.TotalDuration += duration
+	if duration > stats.MaxDuration {
+		stats.MaxDuration = duration
+	}
+}
+
+// Snapshot 当前统计的副本
+func (m *HandlerMetrics) Snapshot() map[string]HandlerStats {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	snapshot := make(map[string]HandlerStats, len(m.stats))
+	for eventType, stats := range m.stats {
+		snapshot[eventType] = *stats
+	}
+	return snapshot
+}
+
+// MetricsMiddleware 把每次处理结果计入 metrics
+func MetricsMiddleware(metrics *HandlerMetrics) HandlerMiddleware {
+	return func(next model.EventHandler) model.EventHandler {
+		return HandlerFunc(func(event model.ApplicationEvent) error {
+			start := time.Now()
+			err := next.Handle(event)
+			metrics.observe(event.EventType(), time.Since(start), err)
+			return err
+		})
+	}
+}
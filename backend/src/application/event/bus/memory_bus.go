@@ -2,26 +2,145 @@ package bus
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
 	"sync"
+	"time"
 
 	"github.com/gbrayhan/microservices-go/src/application/event/model"
 	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
 	"go.uber.org/zap"
 )
 
+// DispatchMode 内存事件总线的分发方式
+type DispatchMode string
+
+const (
+	DispatchSync    DispatchMode = "sync"    // 在 Publish 的 goroutine 中依次执行，返回处理器错误
+	DispatchAsync   DispatchMode = "async"   // 投递到有界 worker 池，队列满时 Publish 阻塞
+	DispatchOrdered DispatchMode = "ordered" // 同一 key 的事件固定到同一 worker，按发布顺序处理
+)
+
+// ErrEventBusClosed 总线关闭后不再接受事件
+var ErrEventBusClosed = errors.New("event bus is closed")
+
+// OrderedEvent 实现后按 OrderingKey 保证顺序，否则按事件类型
+type OrderedEvent interface {
+	OrderingKey() string
+}
+
+// InMemoryConfig 内存事件总线配置
+type InMemoryConfig struct {
+	Mode          DispatchMode
+	Workers       int                                 // async / ordered 模式的 worker 数
+	QueueSize     int                                 // 每个队列的容量
+	MaxRetries    int                                 // 处理失败后的重试次数
+	RetryInterval time.Duration                       // 首次重试间隔，之后翻倍
+	DrainTimeout  time.Duration                       // Close 时等待处理中事件的时间，0 表示一直等待
+	KeyFunc       func(model.ApplicationEvent) string // ordered 模式的分区 key
+	Middlewares   []HandlerMiddleware                 // 附加中间件，位于内置中间件内层
+}
+
+// DefaultInMemoryConfig 默认异步分发，不重试
+func DefaultInMemoryConfig() InMemoryConfig {
+	return InMemoryConfig{
+		Mode:          DispatchAsync,
+		Workers:       10,
+		QueueSize:     1024,
+		RetryInterval: 500 * time.Millisecond,
+		DrainTimeout:  30 * time.Second,
+	}
+}
+
+// dispatchJob 一次投递：async 模式每个处理器一个任务，ordered 模式一个事件的全部处理器一个任务
+type dispatchJob struct {
+	event    model.ApplicationEvent
+	handlers []model.EventHandler
+}
+
 // InMemoryEventBus 内存事件总线实现
 type InMemoryEventBus struct {
 	handlers map[string][]model.EventHandler
 	mutex    sync.RWMutex
 	logger   *logger.Logger
+	config   InMemoryConfig
+	metrics  *HandlerMetrics
+
+	middlewares []HandlerMiddleware
+	queues      []chan dispatchJob // async 模式只有一个共享队列
+	closeMutex  sync.RWMutex
+	closed      bool
+	closing     chan struct{}  // Close 时关闭，唤醒阻塞在入队和重试等待上的 goroutine
+	senders     sync.WaitGroup // 正在入队的 Publish，Close 等它们退出后才关闭队列
+	inFlight    sync.WaitGroup
 }
 
-// NewInMemoryEventBus 创建内存事件总线
+// NewInMemoryEventBus 创建默认配置的内存事件总线
 func NewInMemoryEventBus(logger *logger.Logger) EventBus {
-	return &InMemoryEventBus{
+	return NewInMemoryEventBusWithConfig(DefaultInMemoryConfig(), logger)
+}
+
+// NewInMemoryEventBusWithConfig 按配置创建内存事件总线并启动 worker
+func NewInMemoryEventBusWithConfig(config InMemoryConfig, loggerInstance *logger.Logger) *InMemoryEventBus {
+	if config.Mode == "" {
+		config.Mode = DispatchAsync
+	}
+	if config.Workers <= 0 {
+		config.Workers = 1
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 1
+	}
+	if config.KeyFunc == nil {
+		config.KeyFunc = defaultOrderingKey
+	}
+
+	eb := &InMemoryEventBus{
 		handlers: make(map[string][]model.EventHandler),
-		logger:   logger,
+		logger:   loggerInstance,
+		config:   config,
+		metrics:  NewHandlerMetrics(),
+		closing:  make(chan struct{}),
 	}
+	eb.middlewares = append([]HandlerMiddleware{
+		LoggingMiddleware(loggerInstance),
+		MetricsMiddleware(eb.metrics),
+		RetryMiddleware(config.MaxRetries, config.RetryInterval, eb.closing),
+		RecoverMiddleware(loggerInstance),
+	}, config.Middlewares...)
+
+	switch config.Mode {
+	case DispatchAsync:
+		queue := make(chan dispatchJob, config.QueueSize)
+		eb.queues = []chan dispatchJob{queue}
+		for i := 0; i < config.Workers; i++ {
+			eb.startWorker(queue)
+		}
+	case DispatchOrdered:
+		// 每个 worker 独占一个队列，同一 key 总是进入同一队列
+		for i := 0; i < config.Workers; i++ {
+			queue := make(chan dispatchJob, config.QueueSize)
+			eb.queues = append(eb.queues, queue)
+			eb.startWorker(queue)
+		}
+	}
+	return eb
+}
+
+func (eb *InMemoryEventBus) startWorker(queue chan dispatchJob) {
+	eb.inFlight.Add(1)
+	go func() {
+		defer eb.inFlight.Done()
+		for job := range queue {
+			eb.run(job.event, job.handlers)
+		}
+	}()
+}
+
+// Metrics 处理器统计
+func (eb *InMemoryEventBus) Metrics() *HandlerMetrics {
+	return eb.metrics
 }
 
 // Subscribe 订阅事件
@@ -66,48 +185,132 @@ func (eb *InMemoryEventBus) Unsubscribe(eventType string, handler model.EventHan
 	return nil
 }
 
-// Publish 发布事件 - 完全异步版本
+// Publish 按分发方式发布事件；sync 模式返回处理器错误，其余模式入队即返回
 func (eb *InMemoryEventBus) Publish(ctx context.Context, event model.ApplicationEvent) error {
 	eb.logger.Info("Publishing event",
 		zap.String("eventType", event.EventType()),
-		zap.String("eventID", event.EventID()))
+		zap.String("eventID", event.EventID()),
+		zap.String("mode", string(eb.config.Mode)))
 
-	eb.mutex.RLock()
-	defer eb.mutex.RUnlock()
-
-	handlers, exists := eb.handlers[event.EventType()]
-	if !exists || len(handlers) == 0 {
+	handlers := eb.handlersFor(event.EventType())
+	if len(handlers) == 0 {
 		eb.logger.Debug("No handlers found for event", zap.String("eventType", event.EventType()))
 		return nil
 	}
 
-	eb.logger.Info("Found handlers for event",
-		zap.String("eventType", event.EventType()),
-		zap.Int("handlerCount", len(handlers)))
+	switch eb.config.Mode {
+	case DispatchSync:
+		return eb.PublishSync(ctx, event)
+	case DispatchOrdered:
+		return eb.enqueue(ctx, eb.queueFor(event), dispatchJob{event: event, handlers: handlers})
+	default:
+		for _, handler := range handlers {
+			job := dispatchJob{event: event, handlers: []model.EventHandler{handler}}
+			if err := eb.enqueue(ctx, eb.queues[0], job); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// PublishSync 同步发布事件，依次执行所有订阅者，返回合并后的错误
+func (eb *InMemoryEventBus) PublishSync(ctx context.Context, event model.ApplicationEvent) error {
+	eb.closeMutex.RLock()
+	if eb.closed {
+		eb.closeMutex.RUnlock()
+		return ErrEventBusClosed
+	}
+	eb.inFlight.Add(1)
+	eb.closeMutex.RUnlock()
+	defer eb.inFlight.Done()
+
+	return eb.run(event, eb.handlersFor(event.EventType()))
+}
+
+// enqueue 队列满时阻塞，实现背压；ctx 取消或总线关闭时放弃。
+// 阻塞期间不持有 closeMutex，避免 Close 和其他 Publish 被一起卡住
+func (eb *InMemoryEventBus) enqueue(ctx context.Context, queue chan dispatchJob, job dispatchJob) error {
+	eb.closeMutex.RLock()
+	if eb.closed {
+		eb.closeMutex.RUnlock()
+		return ErrEventBusClosed
+	}
+	eb.senders.Add(1)
+	eb.closeMutex.RUnlock()
+	defer eb.senders.Done()
 
-	// 完全异步处理，不等待结果
+	select {
+	case queue <- job:
+		return nil
+	case <-eb.closing:
+		return ErrEventBusClosed
+	case <-ctx.Done():
+		return fmt.Errorf("enqueue %s event %s: %w", job.event.EventType(), job.event.EventID(), ctx.Err())
+	}
+}
+
+// run 依次执行处理器，每个处理器都经过中间件
+func (eb *InMemoryEventBus) run(event model.ApplicationEvent, handlers []model.EventHandler) error {
+	var errs []error
 	for _, handler := range handlers {
-		go func(h model.EventHandler) {
-			eb.logger.Debug("Handling event",
-				zap.String("eventType", event.EventType()),
-				zap.String("eventID", event.EventID()))
-
-			if err := h.Handle(event); err != nil {
-				eb.logger.Error("Error handling event",
-					zap.String("eventType", event.EventType()),
-					zap.String("eventID", event.EventID()),
-					zap.Error(err))
-			} else {
-				eb.logger.Debug("Event handled successfully",
-					zap.String("eventType", event.EventType()),
-					zap.String("eventID", event.EventID()))
-			}
-		}(handler)
+		if err := chainMiddlewares(handler, eb.middlewares...).Handle(event); err != nil {
+			errs = append(errs, err)
+		}
 	}
+	return errors.Join(errs...)
+}
 
-	eb.logger.Info("Event publishing initiated",
-		zap.String("eventType", event.EventType()),
-		zap.String("eventID", event.EventID()))
+func (eb *InMemoryEventBus) handlersFor(eventType string) []model.EventHandler {
+	eb.mutex.RLock()
+	defer eb.mutex.RUnlock()
+	return append([]model.EventHandler(nil), eb.handlers[eventType]...)
+}
 
-	return nil
+func (eb *InMemoryEventBus) queueFor(event model.ApplicationEvent) chan dispatchJob {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(eb.config.KeyFunc(event)))
+	return eb.queues[hash.Sum32()%uint32(len(eb.queues))]
+}
+
+// Close 停止接收新事件，等待队列中和处理中的事件完成
+func (eb *InMemoryEventBus) Close() error {
+	eb.closeMutex.Lock()
+	if eb.closed {
+		eb.closeMutex.Unlock()
+		return nil
+	}
+	eb.closed = true
+	close(eb.closing)
+	eb.closeMutex.Unlock()
+
+	// 等阻塞在入队上的 Publish 退出后再关闭队列，避免向已关闭的队列发送
+	eb.senders.Wait()
+	for _, queue := range eb.queues {
+		close(queue)
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		eb.inFlight.Wait()
+		close(drained)
+	}()
+	if eb.config.DrainTimeout <= 0 {
+		<-drained
+		return nil
+	}
+	select {
+	case <-drained:
+		eb.logger.Info("In-memory event bus drained")
+		return nil
+	case <-time.After(eb.config.DrainTimeout):
+		return fmt.Errorf("timed out after %s waiting for in-flight event handlers", eb.config.DrainTimeout)
+	}
+}
+
+func defaultOrderingKey(event model.ApplicationEvent) string {
+	if ordered, ok := event.(OrderedEvent); ok {
+		return ordered.OrderingKey()
+	}
+	return event.EventType()
 }
@@ -0,0 +1,153 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gbrayhan/microservices-go/src/application/event/model"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type orderedTestEvent struct {
+	model.GenericEvent
+	key string
+	seq int
+}
+
+func (e *orderedTestEvent) OrderingKey() string {
+	return e.key
+}
+
+func newTestMemoryBus(t *testing.T, config InMemoryConfig) *InMemoryEventBus {
+	loggerInstance, err := logger.NewLogger()
+	require.NoError(t, err)
+	eventBus := NewInMemoryEventBusWithConfig(config, loggerInstance)
+	t.Cleanup(func() { _ = eventBus.Close() })
+	return eventBus
+}
+
+func TestInMemorySyncDispatchReturnsErrorsAndRecoversPanics(t *testing.T) {
+	eventBus := newTestMemoryBus(t, InMemoryConfig{Mode: DispatchSync, MaxRetries: 1, RetryInterval: time.Millisecond})
+	var calls atomic.Int32
+	require.NoError(t, eventBus.Subscribe("order.paid", HandlerFunc(func(event model.ApplicationEvent) error {
+		calls.Add(1)
+		panic("boom")
+	})))
+
+	err := eventBus.Publish(context.Background(), &model.GenericEvent{ID: "evt-1", Type: "order.paid"})
+	assert.ErrorContains(t, err, "boom")
+	assert.Equal(t, int32(2), calls.Load(), "first attempt plus one retry")
+
+	stats := eventBus.Metrics().Snapshot()["order.paid"]
+	assert.Equal(t, int64(1), stats.Handled)
+	assert.Equal(t, int64(1), stats.Failed)
+}
+
+func TestInMemoryOrderedDispatchKeepsOrderPerKey(t *testing.T) {
+	eventBus := newTestMemoryBus(t, InMemoryConfig{Mode: DispatchOrdered, Workers: 4, QueueSize: 8})
+	var mutex sync.Mutex
+	received := map[string][]int{}
+	require.NoError(t, eventBus.Subscribe("order.paid", HandlerFunc(func(event model.ApplicationEvent) error {
+		ordered := event.(*orderedTestEvent)
+		time.Sleep(time.Duration(ordered.seq%3) * time.Millisecond)
+		mutex.Lock()
+		received[ordered.key] = append(received[ordered.key], ordered.seq)
+		mutex.Unlock()
+		return nil
+	})))
+
+	for seq := 0; seq < 20; seq++ {
+		for _, key := range []string{"a", "b", "c"} {
+			event := &orderedTestEvent{GenericEvent: model.GenericEvent{Type: "order.paid"}, key: key, seq: seq}
+			require.NoError(t, eventBus.Publish(context.Background(), event))
+		}
+	}
+	require.NoError(t, eventBus.Close())
+
+	for _, key := range []string{"a", "b", "c"} {
+		require.Len(t, received[key], 20)
+		for i, seq := range received[key] {
+			assert.Equal(t, i, seq, "key %s", key)
+		}
+	}
+}
+
+func TestInMemoryCloseDrainsInFlightHandlers(t *testing.T) {
+	eventBus := newTestMemoryBus(t, InMemoryConfig{Mode: DispatchAsync, Workers: 2, QueueSize: 16, DrainTimeout: 5 * time.Second})
+	var handled atomic.Int32
+	require.NoError(t, eventBus.Subscribe("order.paid", HandlerFunc(func(event model.ApplicationEvent) error {
+		time.Sleep(10 * time.Millisecond)
+		handled.Add(1)
+		return nil
+	})))
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, eventBus.Publish(context.Background(), &model.GenericEvent{Type: "order.paid"}))
+	}
+	require.NoError(t, eventBus.Close())
+	assert.Equal(t, int32(10), handled.Load())
+
+	err := eventBus.Publish(context.Background(), &model.GenericEvent{Type: "order.paid"})
+	assert.True(t, errors.Is(err, ErrEventBusClosed))
+}
+
+func TestInMemoryCloseReleasesBlockedPublish(t *testing.T) {
+	eventBus := newTestMemoryBus(t, InMemoryConfig{Mode: DispatchAsync, Workers: 1, QueueSize: 1, DrainTimeout: 5 * time.Second})
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	require.NoError(t, eventBus.Subscribe("order.paid", HandlerFunc(func(event model.ApplicationEvent) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	})))
+
+	// 第一个事件占住 worker，第二个占满队列，第三个阻塞在入队上
+	require.NoError(t, eventBus.Publish(context.Background(), &model.GenericEvent{Type: "order.paid"}))
+	<-started
+	require.NoError(t, eventBus.Publish(context.Background(), &model.GenericEvent{Type: "order.paid"}))
+	blocked := make(chan error, 1)
+	go func() {
+		blocked <- eventBus.Publish(context.Background(), &model.GenericEvent{Type: "order.paid"})
+	}()
+
+	closed := make(chan error, 1)
+	go func() { closed <- eventBus.Close() }()
+	select {
+	case err := <-blocked:
+		assert.True(t, errors.Is(err, ErrEventBusClosed))
+	case <-time.After(time.Second):
+		t.Fatal("blocked publish was not released by Close")
+	}
+
+	close(release)
+	<-started
+	require.NoError(t, <-closed)
+}
+
+func TestInMemoryCloseStopsRetryWait(t *testing.T) {
+	eventBus := newTestMemoryBus(t, InMemoryConfig{Mode: DispatchAsync, Workers: 1, QueueSize: 1, MaxRetries: 3, RetryInterval: time.Hour})
+	var calls atomic.Int32
+	require.NoError(t, eventBus.Subscribe("order.paid", HandlerFunc(func(event model.ApplicationEvent) error {
+		calls.Add(1)
+		return errors.New("boom")
+	})))
+
+	require.NoError(t, eventBus.Publish(context.Background(), &model.GenericEvent{Type: "order.paid"}))
+	require.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, 5*time.Millisecond)
+
+	closed := make(chan error, 1)
+	go func() { closed <- eventBus.Close() }()
+	select {
+	case err := <-closed:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Close waited for the retry interval")
+	}
+	assert.Equal(t, int32(1), calls.Load())
+}
@@ -0,0 +1,23 @@
+package bus
+
+import (
+	"context"
+
+	"github.com/gbrayhan/microservices-go/src/application/event/model"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"go.uber.org/zap"
+)
+
+// PublishBestEffort 业务数据已提交后发布通知事件，发布失败只记录日志，不影响业务结果；
+// eventBus 为 nil 时不发布
+func PublishBestEffort(ctx context.Context, eventBus EventBus, loggerInstance *logger.Logger, event model.ApplicationEvent) {
+	if eventBus == nil {
+		return
+	}
+	if err := eventBus.Publish(ctx, event); err != nil {
+		loggerInstance.Error("Failed to publish event",
+			zap.String("eventType", event.EventType()),
+			zap.String("eventID", event.EventID()),
+			zap.Error(err))
+	}
+}
@@ -3,6 +3,7 @@ package bus
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -10,7 +11,9 @@ import (
 	"time"
 
 	"github.com/gbrayhan/microservices-go/src/application/event/model"
+	domainEventBus "github.com/gbrayhan/microservices-go/src/domain/sys/event_bus"
 	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/gbrayhan/microservices-go/src/shared/utils"
 	"github.com/streadway/amqp"
 )
 
@@ -24,6 +27,14 @@ type RabbitMQEventBus struct {
 	handlers     map[string][]model.EventHandler
 	handlerMutex sync.RWMutex
 	logger       *logger.Logger
+	registry     *model.EventRegistry
+
+	// 失败重试与死信
+	retryQueue         string
+	deadLetterExchange string
+	deadLetterQueue    string
+	maxAttempts        int
+	retryDelay         time.Duration
 }
 
 // NewRabbitMQEventBus 创建RabbitMQ事件总线
@@ -44,6 +55,14 @@ func NewRabbitMQEventBus(logger *logger.Logger) (EventBus, error) {
 		queueName = "application_events_queue"
 	}
 
+	deadLetterExchange := utils.GetEnv("RABBITMQ_DEAD_LETTER_EXCHANGE", exchangeName+".dlx")
+	deadLetterQueue := utils.GetEnv("RABBITMQ_DEAD_LETTER_QUEUE", queueName+".dlq")
+	maxAttempts := utils.GetEnvAsInt("RABBITMQ_MAX_ATTEMPTS", 5)
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	retryDelay := time.Duration(utils.GetEnvAsInt("RABBITMQ_RETRY_DELAY_SECOND", 10)) * time.Second
+
 	// 建立连接
 	conn, err := amqp.Dial(amqpURL)
 	if err != nil {
@@ -84,13 +103,22 @@ func NewRabbitMQEventBus(logger *logger.Logger) (EventBus, error) {
 	}
 
 	eventBus := &RabbitMQEventBus{
-		connection:   conn,
-		channel:      ch,
-		exchangeName: exchangeName,
-		queueName:    queue.Name,
-		consumerTag:  "application-event-consumer",
-		handlers:     make(map[string][]model.EventHandler),
-		logger:       logger,
+		connection:         conn,
+		channel:            ch,
+		exchangeName:       exchangeName,
+		queueName:          queue.Name,
+		consumerTag:        "application-event-consumer",
+		handlers:           make(map[string][]model.EventHandler),
+		logger:             logger,
+		registry:           model.DefaultEventRegistry,
+		retryQueue:         queue.Name + ".retry",
+		deadLetterExchange: deadLetterExchange,
+		deadLetterQueue:    deadLetterQueue,
+		maxAttempts:        maxAttempts,
+		retryDelay:         retryDelay,
+	}
+	if err := eventBus.declareRetryTopology(); err != nil {
+		return nil, err
 	}
 
 	// 启动消费者
@@ -125,19 +153,24 @@ func (rb *RabbitMQEventBus) Subscribe(eventType string, handler model.EventHandl
 
 // Publish 发布事件
 func (rb *RabbitMQEventBus) Publish(ctx context.Context, event model.ApplicationEvent) error {
-	// 序列化事件
-	eventData, err := json.Marshal(event)
+	// 序列化事件信封
+	envelope, err := model.NewEnvelope(event, CorrelationIDFromContext(ctx))
+	if err != nil {
+		return err
+	}
+	eventData, err := json.Marshal(envelope)
 	if err != nil {
 		return fmt.Errorf("failed to marshal event: %v", err)
 	}
 
 	// 创建消息
 	msg := amqp.Publishing{
-		ContentType: "application/json",
-		Body:        eventData,
-		Timestamp:   time.Now(),
-		MessageId:   event.EventID(),
-		Type:        event.EventType(),
+		ContentType:   "application/json",
+		Body:          eventData,
+		Timestamp:     time.Now(),
+		MessageId:     event.EventID(),
+		CorrelationId: envelope.CorrelationID,
+		Type:          event.EventType(),
 	}
 
 	// 发布消息
@@ -202,32 +235,7 @@ func (rb *RabbitMQEventBus) startConsumer() {
 
 	go func() {
 		for d := range msgs {
-			// 反序列化事件
-			var event map[string]interface{}
-			if err := json.Unmarshal(d.Body, &event); err != nil {
-				log.Printf("Failed to unmarshal event: %v", err)
-				d.Nack(false, false) // 拒绝消息
-				continue
-			}
-
-			// 获取事件类型
-			eventType := d.Type
-			if eventType == "" {
-				// 如果Type字段为空，尝试从事件数据中获取
-				if et, ok := event["eventType"].(string); ok {
-					eventType = et
-				}
-			}
-
-			// 处理事件
-			if err := rb.handleEvent(eventType, event); err != nil {
-				log.Printf("Failed to handle event %s: %v", eventType, err)
-				d.Nack(false, true) // 重新入队
-				continue
-			}
-
-			// 确认消息
-			d.Ack(false)
+			rb.processDelivery(d)
 		}
 	}()
 
@@ -235,23 +243,63 @@ func (rb *RabbitMQEventBus) startConsumer() {
 	<-forever
 }
 
+// processDelivery 处理单条消息，失败时延迟重试，超过最大次数或无法解析时转入死信队列
+func (rb *RabbitMQEventBus) processDelivery(d amqp.Delivery) {
+	// 反序列化事件，无法还原的消息重试也不会成功
+	event, err := rb.decodeDelivery(d)
+	if err != nil {
+		log.Printf("Failed to decode event: %v", err)
+		rb.deadLetter(d, d.Type, domainEventBus.DeadLetterReasonMalformed, err)
+		return
+	}
+	eventType := event.EventType()
+
+	// 处理事件
+	if err := rb.handleEvent(event); err != nil {
+		log.Printf("Failed to handle event %s: %v", eventType, err)
+		rb.retryOrDeadLetter(d, eventType, err)
+		return
+	}
+
+	// 确认消息
+	d.Ack(false)
+}
+
+// decodeDelivery 按事件信封还原具体事件；消息体不是信封时按旧格式处理，整个消息体即载荷
+func (rb *RabbitMQEventBus) decodeDelivery(d amqp.Delivery) (model.ApplicationEvent, error) {
+	var envelope model.Envelope
+	if err := json.Unmarshal(d.Body, &envelope); err != nil {
+		return nil, err
+	}
+	if envelope.Type == "" || envelope.Payload == nil {
+		envelope = model.Envelope{
+			ID:            d.MessageId,
+			Type:          d.Type,
+			OccurredAt:    d.Timestamp,
+			CorrelationID: d.CorrelationId,
+			Payload:       d.Body,
+		}
+	}
+	if envelope.Type == "" {
+		return nil, errors.New("message has no event type")
+	}
+	if envelope.Version == 0 {
+		envelope.Version = model.DefaultEventVersion
+	}
+	return rb.registry.Decode(&envelope)
+}
+
 // handleEvent 处理事件
-func (rb *RabbitMQEventBus) handleEvent(eventType string, eventData map[string]interface{}) error {
+func (rb *RabbitMQEventBus) handleEvent(event model.ApplicationEvent) error {
 	rb.handlerMutex.RLock()
 	defer rb.handlerMutex.RUnlock()
 
-	handlers, exists := rb.handlers[eventType]
+	handlers, exists := rb.handlers[event.EventType()]
 	if !exists || len(handlers) == 0 {
 		// 没有处理器，但不认为是错误
 		return nil
 	}
 
-	// 创建事件对象
-	event := &RabbitMQApplicationEvent{
-		Data: eventData,
-		Type: eventType,
-	}
-
 	// 并发处理所有处理器
 	var wg sync.WaitGroup
 	errChan := make(chan error, len(handlers))
@@ -269,12 +317,14 @@ func (rb *RabbitMQEventBus) handleEvent(eventType string, eventData map[string]i
 	wg.Wait()
 	close(errChan)
 
-	// 处理错误
+	// 处理错误，任一处理器失败都会重投整条消息，处理器需幂等
+	var errs []error
 	for err := range errChan {
 		log.Printf("Error in event handler: %v", err)
+		errs = append(errs, err)
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
 
 // Close 关闭连接
@@ -293,40 +343,3 @@ func (rb *RabbitMQEventBus) Close() error {
 
 	return nil
 }
-
-// RabbitMQApplicationEvent RabbitMQ应用事件实现
-type RabbitMQApplicationEvent struct {
-	Data map[string]interface{}
-	Type string
-}
-
-// EventID 获取事件ID
-func (e *RabbitMQApplicationEvent) EventID() string {
-	if id, ok := e.Data["id"].(string); ok {
-		return id
-	}
-	if id, ok := e.Data["eventID"].(string); ok {
-		return id
-	}
-	return ""
-}
-
-// EventType 获取事件类型
-func (e *RabbitMQApplicationEvent) EventType() string {
-	return e.Type
-}
-
-// Timestamp 获取事件时间戳
-func (e *RabbitMQApplicationEvent) Timestamp() time.Time {
-	if timestampStr, ok := e.Data["timestamp"].(string); ok {
-		if t, err := time.Parse(time.RFC3339, timestampStr); err == nil {
-			return t
-		}
-	}
-	return time.Now()
-}
-
-// Payload 获取事件载荷
-func (e *RabbitMQApplicationEvent) Payload() interface{} {
-	return e.Data
-}
@@ -0,0 +1,228 @@
+package bus
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	domainEventBus "github.com/gbrayhan/microservices-go/src/domain/sys/event_bus"
+	"github.com/streadway/amqp"
+	"go.uber.org/zap"
+)
+
+// 重试与死信相关的消息头
+const (
+	HeaderRetryCount       = "x-retry-count"
+	HeaderLastError        = "x-last-error"
+	HeaderDeadLetterReason = "x-dead-letter-reason"
+	HeaderDeadLetteredAt   = "x-dead-lettered-at"
+)
+
+// 单次列出或重放时最多从死信队列取出的消息数
+const maxDeadLetterScan = 1000
+
+// declareRetryTopology 声明死信交换机/队列和延迟重试队列。
+// 重试队列没有消费者，消息 TTL 到期后经默认交换机直接回到主队列，不会再投递给绑定同一事件的其他队列；
+// 修改 RABBITMQ_RETRY_DELAY_SECOND 后需删除旧的重试队列，否则声明参数不一致会失败
+func (rb *RabbitMQEventBus) declareRetryTopology() error {
+	if err := rb.channel.ExchangeDeclare(rb.deadLetterExchange, "topic", true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare dead letter exchange: %v", err)
+	}
+	if _, err := rb.channel.QueueDeclare(rb.deadLetterQueue, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare dead letter queue: %v", err)
+	}
+	if err := rb.channel.QueueBind(rb.deadLetterQueue, "#", rb.deadLetterExchange, false, nil); err != nil {
+		return fmt.Errorf("failed to bind dead letter queue: %v", err)
+	}
+	_, err := rb.channel.QueueDeclare(rb.retryQueue, true, false, false, false, amqp.Table{
+		"x-message-ttl":             int32(rb.retryDelay / time.Millisecond),
+		"x-dead-letter-exchange":    "",
+		"x-dead-letter-routing-key": rb.queueName,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to declare retry queue: %v", err)
+	}
+	return nil
+}
+
+// retryOrDeadLetter 处理失败的消息：未达到最大次数时放入重试队列，否则转入死信队列
+func (rb *RabbitMQEventBus) retryOrDeadLetter(d amqp.Delivery, eventType string, handleErr error) {
+	attempts := retryCount(d.Headers) + 1
+	if attempts >= rb.maxAttempts {
+		rb.deadLetter(d, eventType, domainEventBus.DeadLetterReasonMaxAttempts, handleErr)
+		return
+	}
+
+	msg := republishing(d, eventType)
+	msg.Headers[HeaderRetryCount] = int32(attempts)
+	msg.Headers[HeaderLastError] = handleErr.Error()
+	if err := rb.channel.Publish("", rb.retryQueue, false, false, msg); err != nil {
+		rb.logger.Error("Failed to publish message to retry queue, requeue it",
+			zap.String("eventType", eventType), zap.String("messageID", d.MessageId), zap.Error(err))
+		d.Nack(false, true)
+		return
+	}
+	rb.logger.Warn("Event handling failed, scheduled for retry",
+		zap.String("eventType", eventType),
+		zap.String("messageID", d.MessageId),
+		zap.Int("attempt", attempts),
+		zap.Duration("delay", rb.retryDelay),
+		zap.Error(handleErr))
+	d.Ack(false)
+}
+
+// deadLetter 把消息连同失败原因转入死信交换机
+func (rb *RabbitMQEventBus) deadLetter(d amqp.Delivery, eventType, reason string, cause error) {
+	msg := republishing(d, eventType)
+	msg.Headers[HeaderRetryCount] = int32(retryCount(d.Headers))
+	msg.Headers[HeaderDeadLetterReason] = reason
+	msg.Headers[HeaderLastError] = cause.Error()
+	msg.Headers[HeaderDeadLetteredAt] = time.Now().UTC().Format(time.RFC3339)
+
+	routingKey := eventType
+	if routingKey == "" {
+		routingKey = "unknown"
+	}
+	if err := rb.channel.Publish(rb.deadLetterExchange, routingKey, false, false, msg); err != nil {
+		rb.logger.Error("Failed to publish message to dead letter exchange, requeue it",
+			zap.String("eventType", eventType), zap.String("messageID", d.MessageId), zap.Error(err))
+		d.Nack(false, true)
+		return
+	}
+	rb.logger.Error("Event moved to dead letter queue",
+		zap.String("eventType", eventType),
+		zap.String("messageID", d.MessageId),
+		zap.String("reason", reason),
+		zap.Error(cause))
+	d.Ack(false)
+}
+
+// ListDeadLetters 查看死信队列中最早的 limit 条消息，不会移除消息
+func (rb *RabbitMQEventBus) ListDeadLetters(limit int) ([]domainEventBus.DeadLetter, error) {
+	ch, err := rb.connection.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open a channel: %v", err)
+	}
+	// 关闭通道时未确认的消息会按原顺序回到队列
+	defer ch.Close()
+
+	deadLetters := make([]domainEventBus.DeadLetter, 0)
+	for len(deadLetters) < limit {
+		d, ok, err := ch.Get(rb.deadLetterQueue, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read dead letter queue: %v", err)
+		}
+		if !ok {
+			break
+		}
+		deadLetters = append(deadLetters, toDeadLetter(d))
+	}
+	return deadLetters, nil
+}
+
+// ReplayDeadLetters 把死信重新投递到主队列并重置重试次数。
+// messageIDs 为空时重放最早的 limit 条，否则只重放匹配的消息
+func (rb *RabbitMQEventBus) ReplayDeadLetters(messageIDs []string, limit int) (int, error) {
+	ch, err := rb.connection.Channel()
+	if err != nil {
+		return 0, fmt.Errorf("failed to open a channel: %v", err)
+	}
+	defer ch.Close()
+
+	wanted := make(map[string]bool, len(messageIDs))
+	for _, id := range messageIDs {
+		wanted[id] = true
+	}
+
+	replayed := 0
+	for scanned := 0; scanned < maxDeadLetterScan && replayed < limit; scanned++ {
+		d, ok, err := ch.Get(rb.deadLetterQueue, false)
+		if err != nil {
+			return replayed, fmt.Errorf("failed to read dead letter queue: %v", err)
+		}
+		if !ok {
+			break
+		}
+		// 不匹配的消息保持未确认，关闭通道时回到队列
+		if len(wanted) > 0 && !wanted[d.MessageId] {
+			continue
+		}
+
+		msg := republishing(d, d.Type)
+		msg.Headers[HeaderRetryCount] = int32(0)
+		delete(msg.Headers, HeaderDeadLetterReason)
+		delete(msg.Headers, HeaderDeadLetteredAt)
+		if err := ch.Publish("", rb.queueName, false, false, msg); err != nil {
+			return replayed, fmt.Errorf("failed to replay dead letter %s: %v", d.MessageId, err)
+		}
+		if err := d.Ack(false); err != nil {
+			return replayed, fmt.Errorf("failed to ack dead letter %s: %v", d.MessageId, err)
+		}
+		replayed++
+		rb.logger.Info("Dead letter replayed", zap.String("eventType", d.Type), zap.String("messageID", d.MessageId))
+	}
+	return replayed, nil
+}
+
+// republishing 复制消息内容和消息头，用于重试、死信和重放
+func republishing(d amqp.Delivery, eventType string) amqp.Publishing {
+	headers := amqp.Table{}
+	for key, value := range d.Headers {
+		headers[key] = value
+	}
+	return amqp.Publishing{
+		Headers:      headers,
+		ContentType:  d.ContentType,
+		DeliveryMode: amqp.Persistent,
+		Body:         d.Body,
+		Timestamp:    d.Timestamp,
+		MessageId:    d.MessageId,
+		Type:         eventType,
+	}
+}
+
+// retryCount 读取已重试次数，不同客户端写入的整数类型可能不同
+func retryCount(headers amqp.Table) int {
+	switch v := headers[HeaderRetryCount].(type) {
+	case int:
+		return v
+	case int8:
+		return int(v)
+	case int16:
+		return int(v)
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case uint8:
+		return int(v)
+	case uint16:
+		return int(v)
+	case uint32:
+		return int(v)
+	default:
+		return 0
+	}
+}
+
+func toDeadLetter(d amqp.Delivery) domainEventBus.DeadLetter {
+	deadLetter := domainEventBus.DeadLetter{
+		MessageID:  d.MessageId,
+		EventType:  d.Type,
+		RetryCount: retryCount(d.Headers),
+	}
+	deadLetter.Reason, _ = d.Headers[HeaderDeadLetterReason].(string)
+	deadLetter.LastError, _ = d.Headers[HeaderLastError].(string)
+	if value, ok := d.Headers[HeaderDeadLetteredAt].(string); ok {
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			deadLetter.DeadLetteredAt = &t
+		}
+	}
+	if json.Valid(d.Body) {
+		deadLetter.Payload = d.Body
+	} else {
+		// 无法解析的消息按字符串返回
+		deadLetter.Payload, _ = json.Marshal(string(d.Body))
+	}
+	return deadLetter
+}
@@ -0,0 +1,86 @@
+package bus
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gbrayhan/microservices-go/src/application/event/model"
+	domainEventBus "github.com/gbrayhan/microservices-go/src/domain/sys/event_bus"
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingHandler struct {
+	err error
+}
+
+func (h *failingHandler) Handle(event model.ApplicationEvent) error {
+	return h.err
+}
+
+func TestRetryCountAcceptsIntegerHeaderTypes(t *testing.T) {
+	assert.Equal(t, 0, retryCount(nil))
+	assert.Equal(t, 0, retryCount(amqp.Table{HeaderRetryCount: "3"}))
+	assert.Equal(t, 3, retryCount(amqp.Table{HeaderRetryCount: int32(3)}))
+	assert.Equal(t, 4, retryCount(amqp.Table{HeaderRetryCount: int64(4)}))
+	assert.Equal(t, 2, retryCount(amqp.Table{HeaderRetryCount: uint8(2)}))
+}
+
+func TestToDeadLetter(t *testing.T) {
+	deadLetter := toDeadLetter(amqp.Delivery{
+		MessageId: "evt-1",
+		Type:      model.ForgetPasswordEventType,
+		Body:      []byte(`{"to":"a@example.com"}`),
+		Headers: amqp.Table{
+			HeaderRetryCount:       int32(4),
+			HeaderDeadLetterReason: domainEventBus.DeadLetterReasonMaxAttempts,
+			HeaderLastError:        "smtp unavailable",
+			HeaderDeadLetteredAt:   "2024-05-01T08:00:00Z",
+		},
+	})
+	assert.Equal(t, "evt-1", deadLetter.MessageID)
+	assert.Equal(t, 4, deadLetter.RetryCount)
+	assert.Equal(t, domainEventBus.DeadLetterReasonMaxAttempts, deadLetter.Reason)
+	assert.Equal(t, "smtp unavailable", deadLetter.LastError)
+	assert.NotNil(t, deadLetter.DeadLetteredAt)
+	assert.JSONEq(t, `{"to":"a@example.com"}`, string(deadLetter.Payload))
+
+	malformed := toDeadLetter(amqp.Delivery{Body: []byte("not json")})
+	assert.JSONEq(t, `"not json"`, string(malformed.Payload))
+}
+
+func TestHandleEventReturnsHandlerErrors(t *testing.T) {
+	rb := &RabbitMQEventBus{handlers: map[string][]model.EventHandler{
+		model.ForgetPasswordEventType: {&failingHandler{}, &failingHandler{err: errors.New("smtp unavailable")}},
+	}}
+	err := rb.handleEvent(&model.ForgetPasswordEvent{ID: "evt-1"})
+	assert.EqualError(t, err, "smtp unavailable")
+	assert.NoError(t, rb.handleEvent(&model.TaskAlertEvent{ID: "evt-2"}))
+}
+
+func TestDecodeDeliveryRestoresTypedEvent(t *testing.T) {
+	rb := &RabbitMQEventBus{registry: model.DefaultEventRegistry}
+
+	event, err := rb.decodeDelivery(amqp.Delivery{
+		Type: model.ForgetPasswordEventType,
+		Body: []byte(`{"id":"evt-1","type":"ForgetPassword","version":1,"occurred_at":"2024-05-01T08:00:00Z","correlation_id":"req-1","payload":{"to":"a@example.com","subject":"Reset Password","body":"link"}}`),
+	})
+	assert.NoError(t, err)
+	forgetPassword, ok := event.(*model.ForgetPasswordEvent)
+	assert.True(t, ok)
+	assert.Equal(t, "evt-1", forgetPassword.ID)
+	assert.Equal(t, "a@example.com", forgetPassword.To)
+	assert.Equal(t, "req-1", forgetPassword.CorrelationID)
+
+	// 升级前发布的消息：消息体是事件结构体本身
+	legacy, err := rb.decodeDelivery(amqp.Delivery{
+		MessageId: "evt-2",
+		Type:      model.ForgetPasswordEventType,
+		Body:      []byte(`{"ID":"evt-2","To":"b@example.com","Subject":"Reset Password","Body":"link"}`),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "b@example.com", legacy.(*model.ForgetPasswordEvent).To)
+
+	_, err = rb.decodeDelivery(amqp.Delivery{Body: []byte(`{"id":"evt-3","type":"ForgetPassword","version":9,"payload":{}}`)})
+	assert.ErrorIs(t, err, model.ErrUnsupportedEventVersion)
+}
@@ -4,98 +4,195 @@ package bus
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"sync"
 	"time"
 
 	"github.com/ThreeDotsLabs/watermill"
 	"github.com/ThreeDotsLabs/watermill/message"
-	"github.com/ThreeDotsLabs/watermill/pubsub/gochannel"
+	"github.com/ThreeDotsLabs/watermill/message/router/middleware"
 	"github.com/gbrayhan/microservices-go/src/application/event/model"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"go.uber.org/zap"
 )
 
-// WatermillEventBus 基于Watermill的事件总线
+// 消息 metadata 中的事件信息
+const (
+	WatermillEventIDKey    = "event_id"
+	WatermillEventTypeKey  = "event_type"
+	WatermillOccurredAtKey = "occurred_at"
+)
+
+// errMalformedEvent 消息无法解析，重试也不会成功
+var errMalformedEvent = errors.New("malformed event")
+
+// WatermillConfig 路由中间件配置
+type WatermillConfig struct {
+	MaxRetries      int           // 处理失败后的重试次数
+	RetryInterval   time.Duration // 首次重试间隔，之后翻倍
+	PoisonTopic     string        // 重试耗尽后消息转入的 topic
+	CloseTimeout    time.Duration // 关闭时等待处理中消息的时间
+	HandlerTimeout  time.Duration // 单条消息处理超时，0 表示不限制
+	RouterNamespace string        // 处理器名前缀，同一进程多个总线时区分
+}
+
+// WatermillEventBus 基于 Watermill Router 的事件总线，每个事件类型只订阅一次，
+// 由路由中间件负责重试、poison queue 和关联ID
 type WatermillEventBus struct {
-	publisher    message.Publisher
-	subscriber   message.Subscriber
-	logger       watermill.LoggerAdapter
+	publisher  message.Publisher
+	subscriber message.Subscriber
+	router     *message.Router
+	logger     *logger.Logger
+	config     WatermillConfig
+	registry   *model.EventRegistry
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
 	handlerMutex sync.RWMutex
 	handlers     map[string][]model.EventHandler // 跟踪每个事件类型的所有处理器
 }
 
-// NewWatermillEventBus 创建Watermill事件总线
-func NewWatermillEventBus() (*WatermillEventBus, error) {
-	logger := watermill.NewStdLogger(false, false)
+// NewWatermillEventBus 创建并启动 Watermill 事件总线，publisher/subscriber 决定底层传输
+func NewWatermillEventBus(publisher message.Publisher, subscriber message.Subscriber, config WatermillConfig, loggerInstance *logger.Logger) (*WatermillEventBus, error) {
+	if config.PoisonTopic == "" {
+		config.PoisonTopic = "poison_events"
+	}
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = 500 * time.Millisecond
+	}
+	if config.CloseTimeout <= 0 {
+		config.CloseTimeout = 30 * time.Second
+	}
+	if config.RouterNamespace == "" {
+		config.RouterNamespace = "event"
+	}
 
-	pubSub := gochannel.NewGoChannel(
-		gochannel.Config{},
-		logger,
-	)
+	wmLogger := logger.NewWatermillLogger(loggerInstance.Log)
+	router, err := message.NewRouter(message.RouterConfig{CloseTimeout: config.CloseTimeout}, wmLogger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create watermill router: %w", err)
+	}
 
-	return &WatermillEventBus{
-		publisher:  pubSub,
-		subscriber: pubSub,
-		logger:     logger,
+	poisonQueue, err := middleware.PoisonQueue(publisher, config.PoisonTopic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create poison queue middleware: %w", err)
+	}
+	// 先添加的在外层：关联ID -> poison queue -> 重试 -> 超时 -> panic 恢复
+	router.AddMiddleware(
+		middleware.CorrelationID,
+		poisonQueue,
+		middleware.Retry{
+			MaxRetries:      config.MaxRetries,
+			InitialInterval: config.RetryInterval,
+			Multiplier:      2,
+			ShouldRetry: func(params middleware.RetryParams) bool {
+				return !errors.Is(params.Err, errMalformedEvent)
+			},
+			Logger: wmLogger,
+		}.Middleware,
+	)
+	if config.HandlerTimeout > 0 {
+		router.AddMiddleware(middleware.Timeout(config.HandlerTimeout))
+	}
+	router.AddMiddleware(middleware.Recoverer)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	eb := &WatermillEventBus{
+		publisher:  publisher,
+		subscriber: subscriber,
+		router:     router,
+		logger:     loggerInstance,
+		config:     config,
+		registry:   model.DefaultEventRegistry,
+		ctx:        ctx,
+		cancel:     cancel,
+		done:       make(chan struct{}),
 		handlers:   make(map[string][]model.EventHandler),
-	}, nil
+	}
+
+	go func() {
+		defer close(eb.done)
+		if err := router.Run(ctx); err != nil {
+			loggerInstance.Error("Watermill router stopped", zap.Error(err))
+		}
+	}()
+	<-router.Running()
+
+	return eb, nil
 }
 
-// Subscribe 订阅事件
+// Subscribe 订阅事件，同一事件类型只在第一次订阅时创建路由处理器
 func (eb *WatermillEventBus) Subscribe(eventType string, handler model.EventHandler) error {
 	eb.handlerMutex.Lock()
+	defer eb.handlerMutex.Unlock()
+
+	_, subscribed := eb.handlers[eventType]
 	eb.handlers[eventType] = append(eb.handlers[eventType], handler)
-	eb.handlerMutex.Unlock()
+	if subscribed {
+		return nil
+	}
 
-	messages, err := eb.subscriber.Subscribe(context.Background(), eventType)
-	if err != nil {
-		return err
+	eb.router.AddNoPublisherHandler(
+		eb.config.RouterNamespace+"."+eventType,
+		eventType,
+		eb.subscriber,
+		eb.dispatch(eventType),
+	)
+	if err := eb.router.RunHandlers(eb.ctx); err != nil {
+		return fmt.Errorf("failed to start handler for %s: %w", eventType, err)
 	}
+	eb.logger.Info("Subscribed to event", zap.String("eventType", eventType))
+	return nil
+}
 
-	go func() {
-		for msg := range messages {
-			var eventModel map[string]interface{}
-			if err := json.Unmarshal(msg.Payload, &eventModel); err != nil {
-				eb.logger.Error("Cannot unmarshal event", err, nil)
-				msg.Nack()
-				continue
-			}
+// dispatch 依次执行该事件类型的所有处理器，任一失败则整条消息按中间件重试
+func (eb *WatermillEventBus) dispatch(eventType string) message.NoPublishHandlerFunc {
+	return func(msg *message.Message) error {
+		event, _, err := eb.registry.DecodeJSON(msg.Payload)
+		if err != nil {
+			// 不重试，直接进入 poison queue
+			return fmt.Errorf("%w: cannot decode message %s: %v", errMalformedEvent, msg.UUID, err)
+		}
 
-			event := &GenericApplicationEvent{
-				Data: eventModel,
-				Type: eventType,
-			}
+		eb.handlerMutex.RLock()
+		handlers := append([]model.EventHandler(nil), eb.handlers[eventType]...)
+		eb.handlerMutex.RUnlock()
 
-			// 执行所有处理器
-			eb.handlerMutex.RLock()
-			handlers := eb.handlers[eventType]
-			eb.handlerMutex.RUnlock()
-
-			for _, h := range handlers {
-				if err := h.Handle(event); err != nil {
-					eb.logger.Error("Error handling event", err, nil)
-					msg.Nack()
-					continue
-				}
+		var errs []error
+		for _, h := range handlers {
+			if err := h.Handle(event); err != nil {
+				errs = append(errs, err)
 			}
-
-			msg.Ack()
 		}
-	}()
-
-	return nil
+		return errors.Join(errs...)
+	}
 }
 
-// Publish 发布事件
+// Publish 以事件信封发布，ctx 中有关联ID时沿用，否则使用事件ID
 func (eb *WatermillEventBus) Publish(ctx context.Context, event model.ApplicationEvent) error {
-	payload, err := json.Marshal(event.Payload())
+	envelope, err := model.NewEnvelope(event, CorrelationIDFromContext(ctx))
 	if err != nil {
 		return err
 	}
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+
+	// 事件ID不一定符合 uuid 长度限制，单独放在 metadata 中
+	msg := message.NewMessage(watermill.NewUUID(), payload)
+	msg.Metadata.Set(WatermillEventIDKey, event.EventID())
+	msg.Metadata.Set(WatermillEventTypeKey, event.EventType())
+	msg.Metadata.Set(WatermillOccurredAtKey, envelope.OccurredAt.Format(time.RFC3339Nano))
+	middleware.SetCorrelationID(envelope.CorrelationID, msg)
 
-	msg := message.NewMessage(event.EventID(), payload)
 	return eb.publisher.Publish(event.EventType(), msg)
 }
 
-// Unsubscribe 取消订阅指定事件类型
+// Unsubscribe 取消订阅指定事件类型，订阅本身保留，没有处理器时消息直接确认
 func (eb *WatermillEventBus) Unsubscribe(eventType string, handler model.EventHandler) error {
 	eb.handlerMutex.Lock()
 	defer eb.handlerMutex.Unlock()
@@ -115,36 +212,16 @@ func (eb *WatermillEventBus) Unsubscribe(eventType string, handler model.EventHa
 	return nil
 }
 
-// GenericApplicationEvent 通用应用事件实现
-type GenericApplicationEvent struct {
-	Data map[string]interface{}
-	Type string
-	ID   string
-	Time int64
-}
-
-func (e *GenericApplicationEvent) EventID() string {
-	if e.ID != "" {
-		return e.ID
+// Close 停止路由，等待处理中的消息完成后关闭传输
+func (eb *WatermillEventBus) Close() error {
+	err := eb.router.Close()
+	eb.cancel()
+	<-eb.done
+	if closeErr := eb.subscriber.Close(); closeErr != nil {
+		err = errors.Join(err, closeErr)
 	}
-	id, ok := e.Data["id"].(string)
-	if !ok {
-		return ""
+	if closeErr := eb.publisher.Close(); closeErr != nil {
+		err = errors.Join(err, closeErr)
 	}
-	return id
-}
-
-func (e *GenericApplicationEvent) EventType() string {
-	return e.Type
-}
-
-func (e *GenericApplicationEvent) Timestamp() time.Time {
-	if e.Time != 0 {
-		return time.Unix(e.Time, 0)
-	}
-	return time.Now()
-}
-
-func (e *GenericApplicationEvent) Payload() interface{} {
-	return e.Data
+	return err
 }
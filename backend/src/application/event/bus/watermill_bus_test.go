@@ -0,0 +1,108 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/ThreeDotsLabs/watermill/message/router/middleware"
+	"github.com/ThreeDotsLabs/watermill/pubsub/gochannel"
+	"github.com/gbrayhan/microservices-go/src/application/event/model"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingHandler struct {
+	mu     sync.Mutex
+	events []model.ApplicationEvent
+	err    error
+	calls  atomic.Int32
+	done   chan struct{}
+}
+
+func newRecordingHandler(err error) *recordingHandler {
+	return &recordingHandler{err: err, done: make(chan struct{}, 10)}
+}
+
+func (h *recordingHandler) Handle(event model.ApplicationEvent) error {
+	h.calls.Add(1)
+	h.mu.Lock()
+	h.events = append(h.events, event)
+	h.mu.Unlock()
+	h.done <- struct{}{}
+	return h.err
+}
+
+func newTestWatermillBus(t *testing.T) (*WatermillEventBus, *gochannel.GoChannel) {
+	loggerInstance, err := logger.NewLogger()
+	require.NoError(t, err)
+	pubSub := gochannel.NewGoChannel(gochannel.Config{OutputChannelBuffer: 16}, logger.NewWatermillLogger(loggerInstance.Log))
+	eventBus, err := NewWatermillEventBus(pubSub, pubSub, WatermillConfig{
+		MaxRetries:    2,
+		RetryInterval: time.Millisecond,
+		PoisonTopic:   "poison_test",
+		CloseTimeout:  time.Second,
+	}, loggerInstance)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = eventBus.Close() })
+	return eventBus, pubSub
+}
+
+func waitCalled(t *testing.T, h *recordingHandler) {
+	select {
+	case <-h.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler was not called")
+	}
+}
+
+func TestWatermillEventBusDeliversOncePerHandler(t *testing.T) {
+	eventBus, _ := newTestWatermillBus(t)
+	first, second := newRecordingHandler(nil), newRecordingHandler(nil)
+	require.NoError(t, eventBus.Subscribe(model.ForgetPasswordEventType, first))
+	require.NoError(t, eventBus.Subscribe(model.ForgetPasswordEventType, second))
+
+	ctx := WithCorrelationID(context.Background(), "req-1")
+	require.NoError(t, eventBus.Publish(ctx, &model.ForgetPasswordEvent{ID: "evt-1", To: "a@example.com", RegisteredAt: time.Now()}))
+	waitCalled(t, first)
+	waitCalled(t, second)
+	time.Sleep(50 * time.Millisecond)
+
+	assert.Equal(t, int32(1), first.calls.Load())
+	assert.Equal(t, int32(1), second.calls.Load())
+	event := first.events[0]
+	assert.Equal(t, "evt-1", event.EventID())
+	assert.Equal(t, model.ForgetPasswordEventType, event.EventType())
+	forgetPassword, ok := event.(*model.ForgetPasswordEvent)
+	require.True(t, ok, "handlers receive the concrete event type")
+	assert.Equal(t, "a@example.com", forgetPassword.To)
+	assert.Equal(t, "req-1", forgetPassword.CorrelationID)
+}
+
+func TestWatermillEventBusRetriesThenPoisons(t *testing.T) {
+	eventBus, pubSub := newTestWatermillBus(t)
+	poisoned, err := pubSub.Subscribe(context.Background(), "poison_test")
+	require.NoError(t, err)
+	failing := newRecordingHandler(errors.New("smtp unavailable"))
+	require.NoError(t, eventBus.Subscribe(model.TaskAlertEventType, failing))
+
+	ctx := WithCorrelationID(context.Background(), "req-2")
+	require.NoError(t, eventBus.Publish(ctx, &model.TaskAlertEvent{ID: "evt-2", RegisteredAt: time.Now()}))
+
+	var msg *message.Message
+	select {
+	case msg = <-poisoned:
+		msg.Ack()
+	case <-time.After(5 * time.Second):
+		t.Fatal("message was not moved to the poison queue")
+	}
+	assert.Equal(t, int32(3), failing.calls.Load(), "first attempt plus two retries")
+	assert.Equal(t, "req-2", middleware.MessageCorrelationID(msg))
+	assert.Equal(t, "evt-2", msg.Metadata.Get(WatermillEventIDKey))
+	assert.Contains(t, msg.Metadata.Get(middleware.ReasonForPoisonedKey), "smtp unavailable")
+}
@@ -1,6 +1,7 @@
 package factory
 
 import (
+	"database/sql"
 	"os"
 
 	"github.com/gbrayhan/microservices-go/src/application/event/bus"
@@ -8,22 +9,26 @@ import (
 	"go.uber.org/zap"
 )
 
-// CreateEventBus 根据环境创建事件总线
-func CreateEventBus(logger *logger.Logger) bus.EventBus {
+// CreateEventBus 根据环境创建事件总线，db 供 watermill 的 sql 传输使用
+func CreateEventBus(db *sql.DB, logger *logger.Logger) bus.EventBus {
 	eventBusType := os.Getenv("SERVER_EVENT_BUS")
 
 	switch eventBusType {
 	case "watermill":
-		// return CreateWatermillEventBus()
-		return nil
+		watermillBus, err := CreateWatermillEventBus(db, logger)
+		if err != nil {
+			logger.Error("Failed to create Watermill event bus, falling back to in-memory", zap.Error(err))
+			return CreateInMemoryEventBus(logger)
+		}
+		return watermillBus
 	case "rabbitmq":
 		rabbitBus, err := bus.NewRabbitMQEventBus(logger)
 		if err != nil {
 			logger.Error("Failed to create RabbitMQ event bus: %v, falling back to in-memory", zap.Error(err))
-			return bus.NewInMemoryEventBus(logger)
+			return CreateInMemoryEventBus(logger)
 		}
 		return rabbitBus
 	default:
-		return bus.NewInMemoryEventBus(logger)
+		return CreateInMemoryEventBus(logger)
 	}
 }
@@ -0,0 +1,21 @@
+package factory
+
+import (
+	"time"
+
+	"github.com/gbrayhan/microservices-go/src/application/event/bus"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/gbrayhan/microservices-go/src/shared/utils"
+)
+
+// CreateInMemoryEventBus 按 MEMORY_BUS_* 配置创建内存事件总线
+func CreateInMemoryEventBus(loggerInstance *logger.Logger) *bus.InMemoryEventBus {
+	config := bus.DefaultInMemoryConfig()
+	config.Mode = bus.DispatchMode(utils.GetEnv("MEMORY_BUS_DISPATCH_MODE", string(config.Mode)))
+	config.Workers = utils.GetEnvAsInt("MEMORY_BUS_WORKERS", config.Workers)
+	config.QueueSize = utils.GetEnvAsInt("MEMORY_BUS_QUEUE_SIZE", config.QueueSize)
+	config.MaxRetries = utils.GetEnvAsInt("MEMORY_BUS_MAX_RETRIES", config.MaxRetries)
+	config.RetryInterval = time.Duration(utils.GetEnvAsInt("MEMORY_BUS_RETRY_INTERVAL_MS", int(config.RetryInterval/time.Millisecond))) * time.Millisecond
+	config.DrainTimeout = time.Duration(utils.GetEnvAsInt("MEMORY_BUS_DRAIN_TIMEOUT_SECOND", int(config.DrainTimeout/time.Second))) * time.Second
+	return bus.NewInMemoryEventBusWithConfig(config, loggerInstance)
+}
@@ -0,0 +1,76 @@
+package factory
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	watermillSQL "github.com/ThreeDotsLabs/watermill-sql/v3/pkg/sql"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/ThreeDotsLabs/watermill/pubsub/gochannel"
+	"github.com/gbrayhan/microservices-go/src/application/event/bus"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/gbrayhan/microservices-go/src/shared/utils"
+)
+
+// Watermill 传输方式
+const (
+	WatermillTransportGoChannel = "gochannel" // 进程内，不持久化
+	WatermillTransportSQL       = "sql"       // 基于 Postgres 表，进程重启后继续消费
+)
+
+// CreateWatermillEventBus 按 WATERMILL_TRANSPORT 创建 Watermill 事件总线
+func CreateWatermillEventBus(db *sql.DB, loggerInstance *logger.Logger) (*bus.WatermillEventBus, error) {
+	publisher, subscriber, err := newWatermillTransport(utils.GetEnv("WATERMILL_TRANSPORT", WatermillTransportGoChannel), db, loggerInstance)
+	if err != nil {
+		return nil, err
+	}
+	return bus.NewWatermillEventBus(publisher, subscriber, bus.WatermillConfig{
+		MaxRetries:     utils.GetEnvAsInt("WATERMILL_MAX_RETRIES", 3),
+		RetryInterval:  time.Duration(utils.GetEnvAsInt("WATERMILL_RETRY_INTERVAL_MS", 500)) * time.Millisecond,
+		PoisonTopic:    utils.GetEnv("WATERMILL_POISON_TOPIC", "poison_events"),
+		HandlerTimeout: time.Duration(utils.GetEnvAsInt("WATERMILL_HANDLER_TIMEOUT_SECOND", 0)) * time.Second,
+	}, loggerInstance)
+}
+
+func newWatermillTransport(transport string, db *sql.DB, loggerInstance *logger.Logger) (message.Publisher, message.Subscriber, error) {
+	wmLogger := logger.NewWatermillLogger(loggerInstance.Log)
+	switch transport {
+	case WatermillTransportGoChannel:
+		pubSub := gochannel.NewGoChannel(gochannel.Config{
+			OutputChannelBuffer: int64(utils.GetEnvAsInt("WATERMILL_CHANNEL_BUFFER", 256)),
+		}, wmLogger)
+		return pubSub, pubSub, nil
+	case WatermillTransportSQL:
+		return newWatermillSQLTransport(db, wmLogger)
+	default:
+		return nil, nil, fmt.Errorf("unknown watermill transport %q", transport)
+	}
+}
+
+// newWatermillSQLTransport 每个事件类型一张 watermill_<topic> 表，消费进度按消费组记录在 offsets 表
+func newWatermillSQLTransport(db *sql.DB, wmLogger *logger.WatermillZapLogger) (message.Publisher, message.Subscriber, error) {
+	if db == nil {
+		return nil, nil, errors.New("watermill sql transport requires a database connection")
+	}
+	schemaAdapter := watermillSQL.DefaultPostgreSQLSchema{}
+	publisher, err := watermillSQL.NewPublisher(db, watermillSQL.PublisherConfig{
+		SchemaAdapter:        schemaAdapter,
+		AutoInitializeSchema: true,
+	}, wmLogger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create watermill sql publisher: %w", err)
+	}
+	subscriber, err := watermillSQL.NewSubscriber(db, watermillSQL.SubscriberConfig{
+		ConsumerGroup:    utils.GetEnv("WATERMILL_CONSUMER_GROUP", "application"),
+		PollInterval:     time.Duration(utils.GetEnvAsInt("WATERMILL_POLL_INTERVAL_MS", 1000)) * time.Millisecond,
+		SchemaAdapter:    schemaAdapter,
+		OffsetsAdapter:   watermillSQL.DefaultPostgreSQLOffsetsAdapter{},
+		InitializeSchema: true,
+	}, wmLogger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create watermill sql subscriber: %w", err)
+	}
+	return publisher, subscriber, nil
+}
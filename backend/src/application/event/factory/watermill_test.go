@@ -0,0 +1,61 @@
+package factory
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gbrayhan/microservices-go/src/application/event/model"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/require"
+)
+
+type channelHandler chan model.ApplicationEvent
+
+func (h channelHandler) Handle(event model.ApplicationEvent) error {
+	h <- event
+	return nil
+}
+
+// 需要 Postgres：WATERMILL_TEST_POSTGRES_DSN=postgres://user:pw@localhost:5432/db?sslmode=disable
+func TestWatermillSQLTransport(t *testing.T) {
+	dsn := os.Getenv("WATERMILL_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("WATERMILL_TEST_POSTGRES_DSN not set")
+	}
+	db, err := sql.Open("pgx", dsn)
+	require.NoError(t, err)
+	defer db.Close()
+	loggerInstance, err := logger.NewLogger()
+	require.NoError(t, err)
+
+	t.Setenv("WATERMILL_TRANSPORT", WatermillTransportSQL)
+	t.Setenv("WATERMILL_POLL_INTERVAL_MS", "100")
+	t.Setenv("WATERMILL_CONSUMER_GROUP", "test_"+time.Now().Format("150405.000"))
+	eventBus, err := CreateWatermillEventBus(db, loggerInstance)
+	require.NoError(t, err)
+	defer eventBus.Close()
+
+	received := make(channelHandler, 1)
+	require.NoError(t, eventBus.Subscribe("WatermillSQLTest", received))
+	eventID := "evt-" + time.Now().Format("150405.000000")
+	require.NoError(t, eventBus.Publish(context.Background(), &model.GenericEvent{
+		ID: eventID, Type: "WatermillSQLTest", Data: map[string]interface{}{"k": "v"}, OccurredAt: time.Now(),
+	}))
+
+	for {
+		select {
+		case event := <-received:
+			// 表中可能有之前运行留下的消息，找到本次发布的即可
+			if event.EventID() == eventID {
+				require.Equal(t, "v", event.Payload().(map[string]interface{})["k"])
+				return
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatal("event was not delivered through the sql transport")
+		}
+	}
+}
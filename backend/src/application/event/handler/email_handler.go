@@ -2,73 +2,99 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/gbrayhan/microservices-go/src/application/event/model"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/lib/email"
 )
 
+// ResetLinkIssuer 发送忘记密码邮件时生成重置链接
+type ResetLinkIssuer interface {
+	IssueResetLink(userID int64) (string, error)
+}
+
 // EmailEventHandler 邮件事件处理器
 type EmailEventHandler struct {
 	emailService email.EmailService
+	resetLinks   ResetLinkIssuer
 }
 
 // NewEmailEventHandler 创建邮件事件处理器
-func NewEmailEventHandler() *EmailEventHandler {
+func NewEmailEventHandler(resetLinks ResetLinkIssuer) *EmailEventHandler {
 	return &EmailEventHandler{
 		emailService: email.NewSMTPEmailService(),
+		resetLinks:   resetLinks,
 	}
 }
 
-// Handle 处理事件
+// Handle 处理事件，各事件总线都会把消息还原为具体的事件类型
 func (h *EmailEventHandler) Handle(event model.ApplicationEvent) error {
-	switch event.EventType() {
-	case model.ForgetPasswordEventType:
-		return h.handleForgetPassword(event)
+	switch e := event.(type) {
+	case *model.ForgetPasswordEvent:
+		return h.handleForgetPassword(e)
+	case *model.TaskAlertEvent:
+		return h.handleTaskAlert(e)
 	default:
 		return nil
 	}
 }
 
-func (h *EmailEventHandler) handleForgetPassword(event model.ApplicationEvent) error {
+func (h *EmailEventHandler) handleForgetPassword(event *model.ForgetPasswordEvent) error {
 	log.Println("Handling forget password event")
-	payload, ok := event.Payload().(map[string]interface{})
-	if !ok {
-		return fmt.Errorf("invalid payload format for forget password event")
+	if event.To == "" {
+		return fmt.Errorf("missing recipient in forget password event %s", event.ID)
 	}
-
-	to, ok := getStringValue(payload, "to")
-	if !ok {
-		return fmt.Errorf("missing 'to' field in payload")
+	body := event.Body
+	if body == "" {
+		// 重复投递时会重新生成令牌，之前邮件中的链接随之失效，最后一封邮件的链接有效
+		if event.UserID == 0 || h.resetLinks == nil {
+			return fmt.Errorf("missing user in forget password event %s", event.ID)
+		}
+		resetLink, err := h.resetLinks.IssueResetLink(event.UserID)
+		if err != nil {
+			return fmt.Errorf("issue reset link for forget password event %s: %w", event.ID, err)
+		}
+		body = "Please click the link to reset your password: " + resetLink
 	}
 
-	subject, _ := getStringValue(payload, "subject")
+	subject := event.Subject
 	if subject == "" {
 		subject = "密码重置"
 	}
 
-	body, ok := getStringValue(payload, "body")
-	if !ok {
-		return fmt.Errorf("missing 'body' field in payload")
-	}
-
-	log.Printf("Sending forget password email to %s", to)
-	res := h.emailService.SendEmail(to, subject, body)
+	log.Printf("Sending forget password email to %s", event.To)
+	res := h.emailService.SendEmail(event.To, subject, body)
 	log.Printf("Email sent: %v", res)
 	return res
 }
 
-// getStringValue 安全地从map中获取字符串值
-func getStringValue(data map[string]interface{}, key string) (string, bool) {
-	value, exists := data[key]
-	if !exists || value == nil {
-		return "", false
+func (h *EmailEventHandler) handleTaskAlert(event *model.TaskAlertEvent) error {
+	// 未配置告警邮箱时不发送
+	recipients := make([]string, 0, len(event.Recipients))
+	for _, recipient := range event.Recipients {
+		if recipient = strings.TrimSpace(recipient); recipient != "" {
+			recipients = append(recipients, recipient)
+		}
+	}
+	if len(recipients) == 0 {
+		return nil
 	}
 
-	if str, ok := value.(string); ok {
-		return str, true
+	subject := fmt.Sprintf("[Scheduled Task Alert] %s", event.TaskName)
+	body := event.Message
+	if event.Error != "" {
+		body += "\n\nLast error: " + event.Error
 	}
 
-	return fmt.Sprintf("%v", value), true
+	var errs []error
+	for _, to := range recipients {
+		log.Printf("Sending task alert email to %s", to)
+		if err := h.emailService.SendEmail(to, subject, body); err != nil {
+			errs = append(errs, fmt.Errorf("send to %s: %w", to, err))
+		}
+	}
+	return errors.Join(errs...)
 }
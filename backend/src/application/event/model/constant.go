@@ -4,4 +4,17 @@ const (
 	UserRegisteredEventType = "UserRegistered"
 	OrderCreatedEventType   = "OrderCreated"
 	ForgetPasswordEventType = "ForgetPassword"
+	TaskAlertEventType      = "TaskAlert"
+	TaskFailedEventType     = "TaskFailed"
+
+	// 领域事件，业务数据变更后发布，供缓存失效、审计、通知等模块订阅
+	UserCreatedEventType                = "UserCreated"
+	UserUpdatedEventType                = "UserUpdated"
+	UserDeletedEventType                = "UserDeleted"
+	UserRolesBoundEventType             = "UserRolesBound"
+	RoleApiRulesChangedEventType        = "RoleApiRulesChanged"
+	RoleMenuPermissionsChangedEventType = "RoleMenuPermissionsChanged"
+	ConfigUpdatedEventType              = "ConfigUpdated"
+	TaskEnabledEventType                = "TaskEnabled"
+	TaskDisabledEventType               = "TaskDisabled"
 )
@@ -0,0 +1,132 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DomainEventBase 领域事件的公共字段，载荷只包含具体事件的业务字段
+type DomainEventBase struct {
+	EventMetadata
+	ID         string    `json:"-"`
+	OccurredAt time.Time `json:"-"`
+}
+
+// NewDomainEventBase 生成事件ID，发生时间为当前时间
+func NewDomainEventBase() DomainEventBase {
+	return DomainEventBase{ID: uuid.New().String(), OccurredAt: time.Now()}
+}
+
+// EventID 事件ID
+func (e *DomainEventBase) EventID() string {
+	return e.ID
+}
+
+// Timestamp 事件时间戳
+func (e *DomainEventBase) Timestamp() time.Time {
+	return e.OccurredAt
+}
+
+// ApplyEnvelope 从信封还原事件ID、时间和关联ID
+func (e *DomainEventBase) ApplyEnvelope(envelope *Envelope) {
+	e.ID = envelope.ID
+	e.OccurredAt = envelope.OccurredAt
+	e.CorrelationID = envelope.CorrelationID
+}
+
+// UserCreatedEvent 后台创建用户
+type UserCreatedEvent struct {
+	DomainEventBase
+	UserID   int64  `json:"userID"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+func (e *UserCreatedEvent) EventType() string    { return UserCreatedEventType }
+func (e *UserCreatedEvent) Payload() interface{} { return e }
+
+// UserUpdatedEvent 用户信息或密码变更，Fields 为变更的字段名，不包含字段值
+type UserUpdatedEvent struct {
+	DomainEventBase
+	UserID int64    `json:"userID"`
+	Fields []string `json:"fields"`
+}
+
+func (e *UserUpdatedEvent) EventType() string    { return UserUpdatedEventType }
+func (e *UserUpdatedEvent) Payload() interface{} { return e }
+
+// UserDeletedEvent 删除用户
+type UserDeletedEvent struct {
+	DomainEventBase
+	UserID int64 `json:"userID"`
+}
+
+func (e *UserDeletedEvent) EventType() string    { return UserDeletedEventType }
+func (e *UserDeletedEvent) Payload() interface{} { return e }
+
+// UserRolesBoundEvent 用户绑定的角色被整体替换
+type UserRolesBoundEvent struct {
+	DomainEventBase
+	UserID  int64    `json:"userID"`
+	RoleIDs []string `json:"roleIDs"`
+}
+
+func (e *UserRolesBoundEvent) EventType() string    { return UserRolesBoundEventType }
+func (e *UserRolesBoundEvent) Payload() interface{} { return e }
+
+// RoleApiRulesChangedEvent 角色的 Casbin API 规则被整体替换，ApiPaths 格式为 path---method
+type RoleApiRulesChangedEvent struct {
+	DomainEventBase
+	RoleID   int      `json:"roleID"`
+	ApiPaths []string `json:"apiPaths"`
+}
+
+func (e *RoleApiRulesChangedEvent) EventType() string    { return RoleApiRulesChangedEventType }
+func (e *RoleApiRulesChangedEvent) Payload() interface{} { return e }
+
+// 菜单权限变更范围
+const (
+	MenuPermissionScopeMenus   = "menus"   // 角色可见菜单
+	MenuPermissionScopeButtons = "buttons" // 某个菜单下的按钮
+)
+
+// RoleMenuPermissionsChangedEvent 角色的菜单或按钮权限变更，Scope 为 buttons 时 MenuID 为按钮所属菜单
+type RoleMenuPermissionsChangedEvent struct {
+	DomainEventBase
+	RoleID  int64   `json:"roleID"`
+	Scope   string  `json:"scope"`
+	MenuID  int64   `json:"menuID,omitempty"`
+	MenuIDs []int64 `json:"menuIDs,omitempty"`
+	BtnIDs  []int64 `json:"btnIDs,omitempty"`
+}
+
+func (e *RoleMenuPermissionsChangedEvent) EventType() string {
+	return RoleMenuPermissionsChangedEventType
+}
+func (e *RoleMenuPermissionsChangedEvent) Payload() interface{} { return e }
+
+// ConfigUpdatedEvent 配置模块更新，只记录变更的配置项名称，避免把密钥写入事件
+type ConfigUpdatedEvent struct {
+	DomainEventBase
+	Module string   `json:"module"`
+	Keys   []string `json:"keys"`
+}
+
+func (e *ConfigUpdatedEvent) EventType() string    { return ConfigUpdatedEventType }
+func (e *ConfigUpdatedEvent) Payload() interface{} { return e }
+
+// TaskStatusChangedEvent 定时任务启用或停用，事件类型由 Enabled 决定
+type TaskStatusChangedEvent struct {
+	DomainEventBase
+	TaskID  int  `json:"taskID"`
+	Enabled bool `json:"enabled"`
+}
+
+func (e *TaskStatusChangedEvent) EventType() string {
+	if e.Enabled {
+		return TaskEnabledEventType
+	}
+	return TaskDisabledEventType
+}
+func (e *TaskStatusChangedEvent) Payload() interface{} { return e }
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DefaultEventVersion 未实现 VersionedEvent 的事件按版本 1 处理
+const DefaultEventVersion = 1
+
+// Envelope 跨事件总线传输的事件信封，各后端都以它的 JSON 作为消息体，
+// 消费端通过 EventRegistry 按 type + version 还原为具体的事件类型
+type Envelope struct {
+	ID            string          `json:"id"`
+	Type          string          `json:"type"`
+	Version       int             `json:"version"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	CorrelationID string          `json:"correlation_id,omitempty"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// VersionedEvent 载荷结构发生不兼容变化时递增版本，旧版本由注册的解码器升级
+type VersionedEvent interface {
+	EventVersion() int
+}
+
+// EnvelopeReceiver 解码后由信封写入事件ID、时间和关联ID
+type EnvelopeReceiver interface {
+	ApplyEnvelope(envelope *Envelope)
+}
+
+// EventMetadata 嵌入到具体事件中，记录信封里的关联ID
+type EventMetadata struct {
+	CorrelationID string `json:"-"`
+}
+
+// EventCorrelationID 关联ID，同一请求触发的事件共用
+func (m *EventMetadata) EventCorrelationID() string {
+	return m.CorrelationID
+}
+
+// NewEnvelope 把事件装入信封，correlationID 为空时沿用事件自带的关联ID，仍为空则使用事件ID
+func NewEnvelope(event ApplicationEvent, correlationID string) (*Envelope, error) {
+	payload, err := json.Marshal(event.Payload())
+	if err != nil {
+		return nil, fmt.Errorf("marshal %s event payload: %w", event.EventType(), err)
+	}
+	version := DefaultEventVersion
+	if versioned, ok := event.(VersionedEvent); ok {
+		version = versioned.EventVersion()
+	}
+	if correlationID == "" {
+		if correlated, ok := event.(interface{ EventCorrelationID() string }); ok {
+			correlationID = correlated.EventCorrelationID()
+		}
+	}
+	if correlationID == "" {
+		correlationID = event.EventID()
+	}
+	return &Envelope{
+		ID:            event.EventID(),
+		Type:          event.EventType(),
+		Version:       version,
+		OccurredAt:    event.Timestamp(),
+		CorrelationID: correlationID,
+		Payload:       payload,
+	}, nil
+}
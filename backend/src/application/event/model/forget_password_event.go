@@ -4,13 +4,16 @@ import (
 	"time"
 )
 
-// ForgetPasswordEvent 发送邮件事件
+// ForgetPasswordEvent 发送忘记密码邮件事件，经发件箱投递；
+// 载荷只包含用户ID和邮箱，重置令牌由处理器发送邮件时生成，不会明文落库
 type ForgetPasswordEvent struct {
-	ID           string
-	To           string
-	Subject      string
-	Body         string
-	RegisteredAt time.Time
+	EventMetadata
+	ID           string    `json:"-"`
+	UserID       int64     `json:"userID"`
+	To           string    `json:"to"`
+	Subject      string    `json:"subject"`
+	Body         string    `json:"body"` // 升级前发布的事件直接携带包含重置链接的正文
+	RegisteredAt time.Time `json:"-"`
 }
 
 // EventID 事件ID
@@ -30,9 +33,20 @@ func (e *ForgetPasswordEvent) Timestamp() time.Time {
 
 // Payload 事件载荷
 func (e *ForgetPasswordEvent) Payload() interface{} {
-	return map[string]interface{}{
+	payload := map[string]interface{}{
+		"userID":  e.UserID,
 		"to":      e.To,
 		"subject": e.Subject,
-		"body":    e.Body,
 	}
+	if e.Body != "" {
+		payload["body"] = e.Body
+	}
+	return payload
+}
+
+// ApplyEnvelope 从信封还原事件ID、时间和关联ID
+func (e *ForgetPasswordEvent) ApplyEnvelope(envelope *Envelope) {
+	e.ID = envelope.ID
+	e.RegisteredAt = envelope.OccurredAt
+	e.CorrelationID = envelope.CorrelationID
 }
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"time"
+)
+
+// GenericEvent 通用事件，类型和载荷由发布方指定，如定时任务的 publish_event
+type GenericEvent struct {
+	EventMetadata
+	ID         string
+	Type       string
+	Data       map[string]interface{}
+	OccurredAt time.Time
+}
+
+// EventID 事件ID
+func (e *GenericEvent) EventID() string {
+	return e.ID
+}
+
+// EventType 事件类型
+func (e *GenericEvent) EventType() string {
+	return e.Type
+}
+
+// Timestamp 事件时间戳
+func (e *GenericEvent) Timestamp() time.Time {
+	return e.OccurredAt
+}
+
+// Payload 事件载荷
+func (e *GenericEvent) Payload() interface{} {
+	if e.Data == nil {
+		return map[string]interface{}{}
+	}
+	return e.Data
+}
+
+// ApplyEnvelope 从信封还原事件ID、时间和关联ID
+func (e *GenericEvent) ApplyEnvelope(envelope *Envelope) {
+	e.ID = envelope.ID
+	e.OccurredAt = envelope.OccurredAt
+	e.CorrelationID = envelope.CorrelationID
+}
@@ -0,0 +1,141 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrUnsupportedEventVersion 事件类型已注册但没有对应版本的解码器，重试不会成功
+var ErrUnsupportedEventVersion = errors.New("unsupported event version")
+
+// EventDecoder 把信封还原为具体事件
+type EventDecoder func(envelope *Envelope) (ApplicationEvent, error)
+
+// EventRegistry 按事件类型和版本登记解码器
+type EventRegistry struct {
+	mutex    sync.RWMutex
+	decoders map[string]map[int]EventDecoder
+}
+
+// NewEventRegistry 创建空的事件注册表
+func NewEventRegistry() *EventRegistry {
+	return &EventRegistry{decoders: make(map[string]map[int]EventDecoder)}
+}
+
+// DefaultEventRegistry 登记了内置事件，各事件总线默认使用
+var DefaultEventRegistry = newDefaultEventRegistry()
+
+func newDefaultEventRegistry() *EventRegistry {
+	registry := NewEventRegistry()
+	registry.Register(UserRegisteredEventType, 1, JSONDecoder(func() ApplicationEvent { return &UserRegisteredEvent{} }))
+	registry.Register(ForgetPasswordEventType, 1, JSONDecoder(func() ApplicationEvent { return &ForgetPasswordEvent{} }))
+	registry.Register(TaskAlertEventType, 1, JSONDecoder(func() ApplicationEvent { return &TaskAlertEvent{} }))
+	registry.Register(TaskFailedEventType, 1, JSONDecoder(func() ApplicationEvent { return &TaskFailedEvent{} }))
+	registry.Register(UserCreatedEventType, 1, JSONDecoder(func() ApplicationEvent { return &UserCreatedEvent{} }))
+	registry.Register(UserUpdatedEventType, 1, JSONDecoder(func() ApplicationEvent { return &UserUpdatedEvent{} }))
+	registry.Register(UserDeletedEventType, 1, JSONDecoder(func() ApplicationEvent { return &UserDeletedEvent{} }))
+	registry.Register(UserRolesBoundEventType, 1, JSONDecoder(func() ApplicationEvent { return &UserRolesBoundEvent{} }))
+	registry.Register(RoleApiRulesChangedEventType, 1, JSONDecoder(func() ApplicationEvent { return &RoleApiRulesChangedEvent{} }))
+	registry.Register(RoleMenuPermissionsChangedEventType, 1, JSONDecoder(func() ApplicationEvent { return &RoleMenuPermissionsChangedEvent{} }))
+	registry.Register(ConfigUpdatedEventType, 1, JSONDecoder(func() ApplicationEvent { return &ConfigUpdatedEvent{} }))
+	registry.Register(TaskEnabledEventType, 1, JSONDecoder(func() ApplicationEvent { return &TaskStatusChangedEvent{Enabled: true} }))
+	registry.Register(TaskDisabledEventType, 1, JSONDecoder(func() ApplicationEvent { return &TaskStatusChangedEvent{} }))
+	return registry
+}
+
+// Register 登记事件类型某个版本的解码器，重复登记时覆盖
+func (r *EventRegistry) Register(eventType string, version int, decoder EventDecoder) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.decoders[eventType] == nil {
+		r.decoders[eventType] = make(map[int]EventDecoder)
+	}
+	r.decoders[eventType][version] = decoder
+}
+
+// EventTypes 已登记的事件类型，按名称排序
+func (r *EventRegistry) EventTypes() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	eventTypes := make([]string, 0, len(r.decoders))
+	for eventType := range r.decoders {
+		eventTypes = append(eventTypes, eventType)
+	}
+	sort.Strings(eventTypes)
+	return eventTypes
+}
+
+// IsRegistered 事件类型是否已登记
+func (r *EventRegistry) IsRegistered(eventType string) bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	_, exists := r.decoders[eventType]
+	return exists
+}
+
+// Decode 还原信封中的事件。未登记的事件类型（如定时任务 publish_event 发布的自定义事件）
+// 还原为 GenericEvent，载荷为 map
+func (r *EventRegistry) Decode(envelope *Envelope) (ApplicationEvent, error) {
+	r.mutex.RLock()
+	versions, registered := r.decoders[envelope.Type]
+	decoder := versions[envelope.Version]
+	r.mutex.RUnlock()
+
+	if !registered {
+		return decodeGenericEvent(envelope)
+	}
+	if decoder == nil {
+		return nil, fmt.Errorf("%w: %s v%d", ErrUnsupportedEventVersion, envelope.Type, envelope.Version)
+	}
+	event, err := decoder(envelope)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s v%d event %s: %w", envelope.Type, envelope.Version, envelope.ID, err)
+	}
+	return event, nil
+}
+
+// DecodeJSON 解析信封 JSON 并还原事件
+func (r *EventRegistry) DecodeJSON(data []byte) (ApplicationEvent, *Envelope, error) {
+	var envelope Envelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, nil, fmt.Errorf("unmarshal event envelope: %w", err)
+	}
+	if envelope.Type == "" {
+		return nil, nil, errors.New("event envelope has no type")
+	}
+	if envelope.Version == 0 {
+		envelope.Version = DefaultEventVersion
+	}
+	event, err := r.Decode(&envelope)
+	return event, &envelope, err
+}
+
+// JSONDecoder 把载荷反序列化到 newEvent 创建的结构体，字段按 json tag 对应 Payload() 的键
+func JSONDecoder(newEvent func() ApplicationEvent) EventDecoder {
+	return func(envelope *Envelope) (ApplicationEvent, error) {
+		event := newEvent()
+		if len(envelope.Payload) > 0 {
+			if err := json.Unmarshal(envelope.Payload, event); err != nil {
+				return nil, err
+			}
+		}
+		if receiver, ok := event.(EnvelopeReceiver); ok {
+			receiver.ApplyEnvelope(envelope)
+		}
+		return event, nil
+	}
+}
+
+func decodeGenericEvent(envelope *Envelope) (ApplicationEvent, error) {
+	event := &GenericEvent{Type: envelope.Type, Data: map[string]interface{}{}}
+	if len(envelope.Payload) > 0 && string(envelope.Payload) != "null" {
+		if err := json.Unmarshal(envelope.Payload, &event.Data); err != nil {
+			return nil, fmt.Errorf("decode %s event %s: %w", envelope.Type, envelope.ID, err)
+		}
+	}
+	event.ApplyEnvelope(envelope)
+	return event, nil
+}
@@ -0,0 +1,77 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvelopeRoundTripRestoresConcreteEvent(t *testing.T) {
+	occurredAt := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	envelope, err := NewEnvelope(&TaskAlertEvent{
+		ID:           "evt-1",
+		TaskID:       3,
+		TaskName:     "backup",
+		Rule:         "consecutive_failures",
+		Recipients:   []string{"ops@example.com"},
+		RegisteredAt: occurredAt,
+	}, "req-1")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultEventVersion, envelope.Version)
+
+	data, err := json.Marshal(envelope)
+	require.NoError(t, err)
+	event, decoded, err := DefaultEventRegistry.DecodeJSON(data)
+	require.NoError(t, err)
+	assert.Equal(t, "req-1", decoded.CorrelationID)
+
+	alert, ok := event.(*TaskAlertEvent)
+	require.True(t, ok)
+	assert.Equal(t, "evt-1", alert.ID)
+	assert.Equal(t, 3, alert.TaskID)
+	assert.Equal(t, []string{"ops@example.com"}, alert.Recipients)
+	assert.True(t, occurredAt.Equal(alert.Timestamp()))
+	assert.Equal(t, "req-1", alert.CorrelationID)
+}
+
+func TestEventRegistryDecode(t *testing.T) {
+	registry := NewEventRegistry()
+	registry.Register(ForgetPasswordEventType, 1, JSONDecoder(func() ApplicationEvent { return &ForgetPasswordEvent{} }))
+
+	// 未登记的类型还原为 GenericEvent
+	event, err := registry.Decode(&Envelope{ID: "evt-2", Type: "order.paid", Version: 1, Payload: json.RawMessage(`{"amount":10}`)})
+	require.NoError(t, err)
+	generic, ok := event.(*GenericEvent)
+	require.True(t, ok)
+	assert.Equal(t, "evt-2", generic.EventID())
+	assert.Equal(t, float64(10), generic.Data["amount"])
+
+	_, err = registry.Decode(&Envelope{ID: "evt-3", Type: ForgetPasswordEventType, Version: 2, Payload: json.RawMessage(`{}`)})
+	assert.ErrorIs(t, err, ErrUnsupportedEventVersion)
+
+	_, _, err = registry.DecodeJSON([]byte(`{"id":"evt-4","payload":{}}`))
+	assert.Error(t, err)
+}
+
+func TestDomainEventsRoundTrip(t *testing.T) {
+	for _, event := range []ApplicationEvent{
+		&UserUpdatedEvent{DomainEventBase: NewDomainEventBase(), UserID: 7, Fields: []string{"email"}},
+		&TaskStatusChangedEvent{DomainEventBase: NewDomainEventBase(), TaskID: 3},
+		&TaskStatusChangedEvent{DomainEventBase: NewDomainEventBase(), TaskID: 3, Enabled: true},
+	} {
+		envelope, err := NewEnvelope(event, "")
+		require.NoError(t, err)
+		assert.NotContains(t, string(envelope.Payload), event.EventID(), "payload carries business fields only")
+
+		decoded, err := DefaultEventRegistry.Decode(envelope)
+		require.NoError(t, err)
+		assert.Equal(t, event.EventType(), decoded.EventType())
+		assert.Equal(t, event.EventID(), decoded.EventID())
+		payload, err := json.Marshal(decoded.Payload())
+		require.NoError(t, err)
+		assert.JSONEq(t, string(envelope.Payload), string(payload))
+	}
+}
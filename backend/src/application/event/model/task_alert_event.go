@@ -0,0 +1,61 @@
+package model
+
+import (
+	"time"
+)
+
+// TaskAlertEvent 定时任务告警事件，任务命中告警规则时发布
+type TaskAlertEvent struct {
+	EventMetadata
+	ID                  string    `json:"-"`
+	TaskID              int       `json:"taskID"`
+	TaskName            string    `json:"taskName"`
+	Rule                string    `json:"rule"` // consecutive_failures / duration_exceeded
+	Message             string    `json:"message"`
+	ExecutionID         string    `json:"executionID"`
+	ConsecutiveFailures int       `json:"consecutiveFailures"`
+	DurationMs          int64     `json:"durationMs"`
+	Error               string    `json:"error"`
+	Recipients          []string  `json:"recipients"` // 告警通知邮箱
+	RegisteredAt        time.Time `json:"occurredAt"`
+}
+
+// EventID 事件ID
+func (e *TaskAlertEvent) EventID() string {
+	return e.ID
+}
+
+// EventType 事件类型
+func (e *TaskAlertEvent) EventType() string {
+	return TaskAlertEventType
+}
+
+// Timestamp 事件时间戳
+func (e *TaskAlertEvent) Timestamp() time.Time {
+	return e.RegisteredAt
+}
+
+// Payload 事件载荷
+func (e *TaskAlertEvent) Payload() interface{} {
+	return map[string]interface{}{
+		"taskID":              e.TaskID,
+		"taskName":            e.TaskName,
+		"rule":                e.Rule,
+		"message":             e.Message,
+		"executionID":         e.ExecutionID,
+		"consecutiveFailures": e.ConsecutiveFailures,
+		"durationMs":          e.DurationMs,
+		"error":               e.Error,
+		"recipients":          e.Recipients,
+		"occurredAt":          e.RegisteredAt,
+	}
+}
+
+// ApplyEnvelope 从信封还原事件ID和关联ID
+func (e *TaskAlertEvent) ApplyEnvelope(envelope *Envelope) {
+	e.ID = envelope.ID
+	e.CorrelationID = envelope.CorrelationID
+	if e.RegisteredAt.IsZero() {
+		e.RegisteredAt = envelope.OccurredAt
+	}
+}
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"time"
+)
+
+// TaskFailedEvent 定时任务一次触发（含重试）最终失败时发布，不依赖告警规则
+type TaskFailedEvent struct {
+	EventMetadata
+	ID           string    `json:"-"`
+	TaskID       int       `json:"taskID"`
+	TaskName     string    `json:"taskName"`
+	ExecutionID  string    `json:"executionID"`
+	DurationMs   int64     `json:"durationMs"`
+	Error        string    `json:"error"`
+	RegisteredAt time.Time `json:"occurredAt"`
+}
+
+// EventID 事件ID
+func (e *TaskFailedEvent) EventID() string {
+	return e.ID
+}
+
+// EventType 事件类型
+func (e *TaskFailedEvent) EventType() string {
+	return TaskFailedEventType
+}
+
+// Timestamp 事件时间戳
+func (e *TaskFailedEvent) Timestamp() time.Time {
+	return e.RegisteredAt
+}
+
+// Payload 事件载荷
+func (e *TaskFailedEvent) Payload() interface{} {
+	return map[string]interface{}{
+		"taskID":      e.TaskID,
+		"taskName":    e.TaskName,
+		"executionID": e.ExecutionID,
+		"durationMs":  e.DurationMs,
+		"error":       e.Error,
+		"occurredAt":  e.RegisteredAt,
+	}
+}
+
+// ApplyEnvelope 从信封还原事件ID和关联ID
+func (e *TaskFailedEvent) ApplyEnvelope(envelope *Envelope) {
+	e.ID = envelope.ID
+	e.CorrelationID = envelope.CorrelationID
+	if e.RegisteredAt.IsZero() {
+		e.RegisteredAt = envelope.OccurredAt
+	}
+}
@@ -6,11 +6,12 @@ import (
 
 // UserRegisteredEvent 用户注册事件
 type UserRegisteredEvent struct {
-	ID           string
-	UserID       string
-	Username     string
-	Email        string
-	RegisteredAt time.Time
+	EventMetadata
+	ID           string    `json:"-"`
+	UserID       string    `json:"userID"`
+	Username     string    `json:"username"`
+	Email        string    `json:"email"`
+	RegisteredAt time.Time `json:"registeredAt"`
 }
 
 // EventID 事件ID
@@ -37,3 +38,12 @@ func (e *UserRegisteredEvent) Payload() interface{} {
 		"registeredAt": e.RegisteredAt,
 	}
 }
+
+// ApplyEnvelope 从信封还原事件ID和关联ID
+func (e *UserRegisteredEvent) ApplyEnvelope(envelope *Envelope) {
+	e.ID = envelope.ID
+	e.CorrelationID = envelope.CorrelationID
+	if e.RegisteredAt.IsZero() {
+		e.RegisteredAt = envelope.OccurredAt
+	}
+}
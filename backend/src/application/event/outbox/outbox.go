@@ -0,0 +1,84 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/gbrayhan/microservices-go/src/application/event/bus"
+	"github.com/gbrayhan/microservices-go/src/application/event/model"
+	domainOutbox "github.com/gbrayhan/microservices-go/src/domain/sys/outbox"
+	outboxRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/outbox"
+	"gorm.io/datatypes"
+)
+
+// NewOutboxEvent 把应用事件装入信封并转换为发件箱记录
+func NewOutboxEvent(event model.ApplicationEvent, correlationID string) (*domainOutbox.OutboxEvent, error) {
+	envelope, err := model.NewEnvelope(event, correlationID)
+	if err != nil {
+		return nil, err
+	}
+	return &domainOutbox.OutboxEvent{
+		EventID:       envelope.ID,
+		EventType:     envelope.Type,
+		EventVersion:  envelope.Version,
+		CorrelationID: envelope.CorrelationID,
+		Payload:       datatypes.JSON(envelope.Payload),
+		OccurredAt:    envelope.OccurredAt,
+		Status:        domainOutbox.StatusPending,
+	}, nil
+}
+
+// ToApplicationEvent 通过事件注册表把发件箱记录还原为具体的事件类型
+func ToApplicationEvent(event *domainOutbox.OutboxEvent) (model.ApplicationEvent, error) {
+	payload := json.RawMessage(event.Payload)
+	if len(payload) == 0 {
+		payload = json.RawMessage("{}")
+	}
+	version := event.EventVersion
+	if version == 0 {
+		version = model.DefaultEventVersion
+	}
+	applicationEvent, err := model.DefaultEventRegistry.Decode(&model.Envelope{
+		ID:            event.EventID,
+		Type:          event.EventType,
+		Version:       version,
+		OccurredAt:    event.OccurredAt,
+		CorrelationID: event.CorrelationID,
+		Payload:       payload,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("decode outbox event %s: %w", event.EventID, err)
+	}
+	return applicationEvent, nil
+}
+
+// EventBus 发布时只写入发件箱，由 Relay 投递到真正的事件总线；订阅直接交给底层总线
+type EventBus struct {
+	repo     outboxRepo.IOutboxRepository
+	delegate bus.EventBus
+}
+
+// NewEventBus 创建写发件箱的事件总线
+func NewEventBus(repo outboxRepo.IOutboxRepository, delegate bus.EventBus) bus.EventBus {
+	return &EventBus{repo: repo, delegate: delegate}
+}
+
+// Publish 写入发件箱，返回 nil 即表示事件已持久化
+func (b *EventBus) Publish(ctx context.Context, event model.ApplicationEvent) error {
+	row, err := NewOutboxEvent(event, bus.CorrelationIDFromContext(ctx))
+	if err != nil {
+		return err
+	}
+	return b.repo.Create(row)
+}
+
+// Subscribe 订阅事件
+func (b *EventBus) Subscribe(eventType string, handler model.EventHandler) error {
+	return b.delegate.Subscribe(eventType, handler)
+}
+
+// Unsubscribe 取消订阅事件
+func (b *EventBus) Unsubscribe(eventType string, handler model.EventHandler) error {
+	return b.delegate.Unsubscribe(eventType, handler)
+}
@@ -0,0 +1,188 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gbrayhan/microservices-go/src/application/event/bus"
+	domainOutbox "github.com/gbrayhan/microservices-go/src/domain/sys/outbox"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	outboxRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/outbox"
+	"github.com/gbrayhan/microservices-go/src/shared/utils"
+	"go.uber.org/zap"
+)
+
+// RelayConfig 发件箱投递配置
+type RelayConfig struct {
+	PollInterval time.Duration // 轮询间隔
+	BatchSize    int           // 每次领取的事件数
+	MaxAttempts  int           // 超过后标记为 failed
+	Lease        time.Duration // 领取后多久未确认可被重新领取
+	RetryBackoff time.Duration // 首次重试间隔，之后按指数递增
+	MaxBackoff   time.Duration
+}
+
+// DefaultRelayConfig 读取 OUTBOX_* 环境变量
+func DefaultRelayConfig() RelayConfig {
+	return RelayConfig{
+		PollInterval: time.Duration(utils.GetEnvAsInt("OUTBOX_POLL_INTERVAL_SECOND", 2)) * time.Second,
+		BatchSize:    utils.GetEnvAsInt("OUTBOX_BATCH_SIZE", 100),
+		MaxAttempts:  utils.GetEnvAsInt("OUTBOX_MAX_ATTEMPTS", 10),
+		Lease:        time.Duration(utils.GetEnvAsInt("OUTBOX_LEASE_SECOND", 60)) * time.Second,
+		RetryBackoff: time.Duration(utils.GetEnvAsInt("OUTBOX_RETRY_BACKOFF_SECOND", 5)) * time.Second,
+		MaxBackoff:   10 * time.Minute,
+	}
+}
+
+// Relay 轮询发件箱，把待投递事件发布到事件总线，失败按指数退避重试。
+// 投递成功但标记完成前崩溃时事件会再次投递，订阅者需按 EventID 幂等
+type Relay struct {
+	repo     outboxRepo.IOutboxRepository
+	eventBus bus.EventBus
+	logger   *logger.Logger
+	config   RelayConfig
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewRelay 创建发件箱投递器
+func NewRelay(repo outboxRepo.IOutboxRepository, eventBus bus.EventBus, loggerInstance *logger.Logger, config RelayConfig) *Relay {
+	if config.PollInterval <= 0 {
+		config.PollInterval = 2 * time.Second
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 10
+	}
+	if config.Lease <= 0 {
+		config.Lease = time.Minute
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = 5 * time.Second
+	}
+	if config.MaxBackoff < config.RetryBackoff {
+		config.MaxBackoff = config.RetryBackoff
+	}
+	return &Relay{repo: repo, eventBus: eventBus, logger: loggerInstance, config: config}
+}
+
+// Start 启动后台投递
+func (r *Relay) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		failures := 0
+		for {
+			wait := r.config.PollInterval
+			// 一批领满时说明还有积压，不等下一个周期
+			for ctx.Err() == nil {
+				claimed, err := r.RelayOnce(ctx)
+				if err != nil {
+					// 数据库不可用时按指数退避，避免每个轮询周期都打满错误日志
+					failures++
+					wait = r.backoff(failures)
+					r.logger.Error("Failed to claim outbox events, backing off", zap.Duration("wait", wait), zap.Error(err))
+					break
+				}
+				failures = 0
+				if claimed < r.config.BatchSize {
+					break
+				}
+			}
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+	}()
+	r.logger.Info("Outbox relay started", zap.Duration("poll_interval", r.config.PollInterval))
+}
+
+// Stop 停止投递并等待当前批次结束
+func (r *Relay) Stop() {
+	if r.cancel == nil {
+		return
+	}
+	r.cancel()
+	r.wg.Wait()
+	r.logger.Info("Outbox relay stopped")
+}
+
+// RelayOnce 领取并投递一批事件，返回领取的数量；领取失败时返回错误，由调用方退避
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	events, err := r.repo.ClaimPending(time.Now(), r.config.Lease, r.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	for i := range *events {
+		if ctx.Err() != nil {
+			// 未投递的事件租约到期后会被重新领取
+			break
+		}
+		r.deliver(ctx, &(*events)[i])
+	}
+	return len(*events), nil
+}
+
+func (r *Relay) deliver(ctx context.Context, event *domainOutbox.OutboxEvent) {
+	err := r.publish(ctx, event)
+	if err == nil {
+		if err := r.repo.MarkDone(event.ID); err != nil {
+			r.logger.Error("Failed to mark outbox event done", zap.Int64("id", event.ID), zap.Error(err))
+		}
+		return
+	}
+
+	fields := []zap.Field{
+		zap.Int64("id", event.ID),
+		zap.String("event_id", event.EventID),
+		zap.String("event_type", event.EventType),
+		zap.Int("attempts", event.Attempts),
+		zap.Error(err),
+	}
+	if event.Attempts >= r.config.MaxAttempts {
+		r.logger.Error("Outbox event exceeded max attempts, giving up", fields...)
+		if err := r.repo.MarkFailed(event.ID, err.Error()); err != nil {
+			r.logger.Error("Failed to mark outbox event failed", zap.Int64("id", event.ID), zap.Error(err))
+		}
+		return
+	}
+	r.logger.Warn("Failed to publish outbox event, will retry", fields...)
+	if err := r.repo.MarkRetry(event.ID, time.Now().Add(r.backoff(event.Attempts)), err.Error()); err != nil {
+		r.logger.Error("Failed to schedule outbox event retry", zap.Int64("id", event.ID), zap.Error(err))
+	}
+}
+
+// publish 总线支持同步发布时等待订阅者处理完成，订阅者出错也会重试
+func (r *Relay) publish(ctx context.Context, event *domainOutbox.OutboxEvent) error {
+	applicationEvent, err := ToApplicationEvent(event)
+	if err != nil {
+		return err
+	}
+	ctx = bus.WithCorrelationID(ctx, event.CorrelationID)
+	if syncBus, ok := r.eventBus.(bus.SyncPublisher); ok {
+		return syncBus.PublishSync(ctx, applicationEvent)
+	}
+	return r.eventBus.Publish(ctx, applicationEvent)
+}
+
+// backoff 第 n 次失败后的等待时间：RetryBackoff * 2^(n-1)，不超过 MaxBackoff
+func (r *Relay) backoff(attempts int) time.Duration {
+	delay := r.config.RetryBackoff
+	for i := 1; i < attempts && delay < r.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > r.config.MaxBackoff {
+		delay = r.config.MaxBackoff
+	}
+	return delay
+}
@@ -0,0 +1,173 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gbrayhan/microservices-go/src/application/event/bus"
+	"github.com/gbrayhan/microservices-go/src/application/event/model"
+	domainOutbox "github.com/gbrayhan/microservices-go/src/domain/sys/outbox"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryOutboxRepository struct {
+	events   []domainOutbox.OutboxEvent
+	claimErr error
+}
+
+func (r *memoryOutboxRepository) Create(event *domainOutbox.OutboxEvent) error {
+	event.ID = int64(len(r.events) + 1)
+	event.Status = domainOutbox.StatusPending
+	r.events = append(r.events, *event)
+	return nil
+}
+
+func (r *memoryOutboxRepository) ClaimPending(now time.Time, lease time.Duration, limit int) (*[]domainOutbox.OutboxEvent, error) {
+	if r.claimErr != nil {
+		return nil, r.claimErr
+	}
+	claimed := []domainOutbox.OutboxEvent{}
+	for i := range r.events {
+		event := &r.events[i]
+		if len(claimed) == limit || event.Status != domainOutbox.StatusPending || event.NextAttemptAt.After(now) {
+			continue
+		}
+		event.Attempts++
+		event.NextAttemptAt = now.Add(lease)
+		claimed = append(claimed, *event)
+	}
+	return &claimed, nil
+}
+
+func (r *memoryOutboxRepository) MarkDone(id int64) error {
+	r.events[id-1].Status = domainOutbox.StatusDone
+	return nil
+}
+
+func (r *memoryOutboxRepository) MarkRetry(id int64, nextAttemptAt time.Time, lastError string) error {
+	r.events[id-1].NextAttemptAt = nextAttemptAt
+	r.events[id-1].LastError = lastError
+	return nil
+}
+
+func (r *memoryOutboxRepository) MarkFailed(id int64, lastError string) error {
+	r.events[id-1].Status = domainOutbox.StatusFailed
+	r.events[id-1].LastError = lastError
+	return nil
+}
+
+func (r *memoryOutboxRepository) PurgeDone(before time.Time) (int64, error) {
+	return 0, nil
+}
+
+type handlerFunc func(event model.ApplicationEvent) error
+
+func (f handlerFunc) Handle(event model.ApplicationEvent) error {
+	return f(event)
+}
+
+func newTestRelay(t *testing.T, repo *memoryOutboxRepository, maxAttempts int) (*Relay, bus.EventBus) {
+	loggerInstance, err := logger.NewLogger()
+	require.NoError(t, err)
+	eventBus := bus.NewInMemoryEventBus(loggerInstance)
+	return NewRelay(repo, eventBus, loggerInstance, RelayConfig{MaxAttempts: maxAttempts, RetryBackoff: time.Millisecond}), eventBus
+}
+
+func TestRelayDeliversOutboxEvent(t *testing.T) {
+	repo := &memoryOutboxRepository{}
+	relay, eventBus := newTestRelay(t, repo, 3)
+	var received []model.ApplicationEvent
+	require.NoError(t, eventBus.Subscribe(model.UserRegisteredEventType, handlerFunc(func(event model.ApplicationEvent) error {
+		received = append(received, event)
+		return nil
+	})))
+
+	outboxBus := NewEventBus(repo, eventBus)
+	require.NoError(t, outboxBus.Publish(context.Background(), &model.UserRegisteredEvent{
+		ID: "evt-1", UserID: "7", Email: "a@example.com", RegisteredAt: time.Now(),
+	}))
+	assert.Empty(t, received, "publish only writes the outbox")
+
+	claimed, err := relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, claimed)
+	require.Len(t, received, 1)
+	assert.Equal(t, "evt-1", received[0].EventID())
+	assert.Equal(t, model.UserRegisteredEventType, received[0].EventType())
+	registered, ok := received[0].(*model.UserRegisteredEvent)
+	require.True(t, ok, "relay restores the concrete event type")
+	assert.Equal(t, "a@example.com", registered.Email)
+	assert.Equal(t, "evt-1", registered.CorrelationID)
+	assert.Equal(t, domainOutbox.StatusDone, repo.events[0].Status)
+	claimed, err = relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, claimed)
+}
+
+func TestForgetPasswordOutboxEventHasNoResetToken(t *testing.T) {
+	repo := &memoryOutboxRepository{}
+	relay, eventBus := newTestRelay(t, repo, 3)
+	var received []model.ApplicationEvent
+	require.NoError(t, eventBus.Subscribe(model.ForgetPasswordEventType, handlerFunc(func(event model.ApplicationEvent) error {
+		received = append(received, event)
+		return nil
+	})))
+
+	require.NoError(t, NewEventBus(repo, eventBus).Publish(context.Background(), &model.ForgetPasswordEvent{
+		ID: "evt-1", UserID: 7, To: "a@example.com", Subject: "Reset Password", RegisteredAt: time.Now(),
+	}))
+	require.Len(t, repo.events, 1)
+	assert.JSONEq(t, `{"userID": 7, "to": "a@example.com", "subject": "Reset Password"}`, string(repo.events[0].Payload))
+
+	_, err := relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+	require.Len(t, received, 1)
+	forgetPassword, ok := received[0].(*model.ForgetPasswordEvent)
+	require.True(t, ok)
+	assert.Equal(t, int64(7), forgetPassword.UserID)
+	assert.Empty(t, forgetPassword.Body)
+}
+
+func TestRelayRetriesHandlerErrorsUntilMaxAttempts(t *testing.T) {
+	repo := &memoryOutboxRepository{}
+	relay, eventBus := newTestRelay(t, repo, 2)
+	calls := 0
+	require.NoError(t, eventBus.Subscribe(model.UserRegisteredEventType, handlerFunc(func(event model.ApplicationEvent) error {
+		calls++
+		return errors.New("smtp unavailable")
+	})))
+	row, err := NewOutboxEvent(&model.UserRegisteredEvent{ID: "evt-2", UserID: "7", RegisteredAt: time.Now()}, "")
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(row))
+
+	_, err = relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, domainOutbox.StatusPending, repo.events[0].Status)
+	assert.Equal(t, "smtp unavailable", repo.events[0].LastError)
+
+	time.Sleep(5 * time.Millisecond)
+	_, err = relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, domainOutbox.StatusFailed, repo.events[0].Status)
+}
+
+func TestRelayOnceReturnsClaimError(t *testing.T) {
+	repo := &memoryOutboxRepository{claimErr: errors.New("connection refused")}
+	relay, _ := newTestRelay(t, repo, 3)
+
+	claimed, err := relay.RelayOnce(context.Background())
+	assert.EqualError(t, err, "connection refused")
+	assert.Equal(t, 0, claimed)
+}
+
+func TestRelayBackoffIsCapped(t *testing.T) {
+	relay := NewRelay(&memoryOutboxRepository{}, nil, nil, RelayConfig{RetryBackoff: time.Second, MaxBackoff: 5 * time.Second})
+	assert.Equal(t, time.Second, relay.backoff(1))
+	assert.Equal(t, 4*time.Second, relay.backoff(3))
+	assert.Equal(t, 5*time.Second, relay.backoff(10))
+}
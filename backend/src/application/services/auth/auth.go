@@ -4,11 +4,15 @@ import (
 	"context"
 	"errors"
 	"os"
+	"strconv"
 	"time"
 
+	eventModel "github.com/gbrayhan/microservices-go/src/application/event/model"
+	"github.com/gbrayhan/microservices-go/src/application/event/outbox"
 	"github.com/gbrayhan/microservices-go/src/domain"
 	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
 	jwtBlacklistDomain "github.com/gbrayhan/microservices-go/src/domain/jwt_blacklist"
+	domainOutbox "github.com/gbrayhan/microservices-go/src/domain/sys/outbox"
 	domainRole "github.com/gbrayhan/microservices-go/src/domain/sys/role"
 	domainUser "github.com/gbrayhan/microservices-go/src/domain/user"
 	captchaLib "github.com/gbrayhan/microservices-go/src/infrastructure/lib/captcha"
@@ -88,14 +92,18 @@ func (s *AuthUseCase) SwitchRole(userId int, roleId int64) (*domainUser.User, *A
 		s.Logger.Error("Error getting role for switch", zap.Error(err), zap.Int("roleId", int(roleId)))
 		return nil, nil, nil, err
 	}
-	accessTokenClaims, err := s.JWTService.GenerateJWTToken(user.ID, roleId, "access")
+	refreshTokenClaims, err := s.JWTService.GenerateJWTToken(user.ID, roleId, "refresh")
 	if err != nil {
-		s.Logger.Error("Error generating access token", zap.Error(err), zap.Int64("userID", user.ID))
+		s.Logger.Error("Error generating refresh token", zap.Error(err), zap.Int64("userID", user.ID))
 		return nil, nil, nil, err
 	}
-	refreshTokenClaims, err := s.JWTService.GenerateJWTToken(user.ID, roleId, "refresh")
+	accessTokenClaims, err := s.JWTService.GenerateAccessToken(user.ID, roleId, refreshTokenClaims.FamilyID)
 	if err != nil {
-		s.Logger.Error("Error generating refresh token", zap.Error(err), zap.Int64("userID", user.ID))
+		s.Logger.Error("Error generating access token", zap.Error(err), zap.Int64("userID", user.ID))
+		return nil, nil, nil, err
+	}
+	if err := s.startTokenFamily(refreshTokenClaims); err != nil {
+		s.Logger.Error("Error saving refresh token family", zap.Error(err), zap.Int64("userID", user.ID))
 		return nil, nil, nil, err
 	}
 
@@ -143,14 +151,18 @@ func (s *AuthUseCase) Login(username, password, captchaId, CaptchaAnswer string,
 		roleId = user.Roles[0].ID
 		role = user.Roles[0]
 	}
-	accessTokenClaims, err := s.JWTService.GenerateJWTToken(user.ID, roleId, "access")
+	refreshTokenClaims, err := s.JWTService.GenerateJWTToken(user.ID, roleId, "refresh")
+	if err != nil {
+		s.Logger.Error("Error generating refresh token", zap.Error(err), zap.Int64("userID", user.ID))
+		return nil, nil, nil, err
+	}
+	accessTokenClaims, err := s.JWTService.GenerateAccessToken(user.ID, roleId, refreshTokenClaims.FamilyID)
 	if err != nil {
 		s.Logger.Error("Error generating access token", zap.Error(err), zap.Int64("userID", user.ID))
 		return nil, nil, nil, err
 	}
-	refreshTokenClaims, err := s.JWTService.GenerateJWTToken(user.ID, roleId, "refresh")
-	if err != nil {
-		s.Logger.Error("Error generating refresh token", zap.Error(err), zap.Int64("userID", user.ID))
+	if err := s.startTokenFamily(refreshTokenClaims); err != nil {
+		s.Logger.Error("Error saving refresh token family", zap.Error(err), zap.Int64("userID", user.ID))
 		return nil, nil, nil, err
 	}
 
@@ -167,6 +179,9 @@ func (s *AuthUseCase) Login(username, password, captchaId, CaptchaAnswer string,
 	s.Logger.Info("User login successful", zap.String("username", username), zap.Int64("userID", user.ID))
 	return user, authTokens, &role, nil
 }
+
+// AccessTokenByRefreshToken 每次刷新都轮换刷新令牌，旧令牌随即失效；
+// 已轮换的令牌再次出现时吊销整个令牌族
 func (s *AuthUseCase) AccessTokenByRefreshToken(refreshToken string) (*domainUser.User, *AuthTokens, error) {
 	s.Logger.Info("Refreshing access token")
 
@@ -179,6 +194,8 @@ func (s *AuthUseCase) AccessTokenByRefreshToken(refreshToken string) (*domainUse
 
 	userID := int(claimsMap["id"].(float64))
 	roleId := int64(claimsMap["role_id"].(float64))
+	jti, _ := claimsMap["jti"].(string)
+	familyID, _ := claimsMap["fid"].(string)
 
 	// 检查刷新令牌是否在黑名单中
 	exists, err := s.jwtBlacklistRepository.IsJwtInBlacklist(refreshToken)
@@ -192,8 +209,21 @@ func (s *AuthUseCase) AccessTokenByRefreshToken(refreshToken string) (*domainUse
 		return nil, nil, domainErrors.NewAppError(errors.New("refresh token has been revoked"), domainErrors.TokenError)
 	}
 
-	// 检查是否启用了单点登录，并验证是否为当前活跃会话
-	if os.Getenv("SERVER_SINGLE_SIGN_ON") == "true" {
+	// 检查令牌族，需在单点登录检查之前，否则被重放的旧令牌只会被当作已替换
+	state := tokenFamilyCurrent
+	if familyID != "" {
+		state, err = s.checkTokenFamily(familyID, jti)
+		if err != nil {
+			s.Logger.Error("Error checking refresh token family", zap.Error(err), zap.Int("userID", userID))
+			return nil, nil, domainErrors.NewAppError(err, domainErrors.TokenError)
+		}
+		if err := s.tokenFamilyError(int64(userID), familyID, refreshToken, state); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// 检查是否启用了单点登录，并验证是否为当前活跃会话；宽限期内的上一个令牌已被同一会话替换，不在此拒绝
+	if os.Getenv("SERVER_SINGLE_SIGN_ON") == "true" && state != tokenFamilyRotated {
 		// 获取用户当前的活跃刷新令牌
 		currentRefreshToken, err := s.RedisClient.Get(context.Background(), GetUserRefreshTokenKey(int64(userID))).Result()
 		if err == nil && currentRefreshToken != refreshToken {
@@ -211,23 +241,28 @@ func (s *AuthUseCase) AccessTokenByRefreshToken(refreshToken string) (*domainUse
 		return nil, nil, err
 	}
 
-	// 生成新的访问令牌
-	accessTokenClaims, err := s.JWTService.GenerateJWTToken(user.ID, roleId, "access")
+	// 轮换刷新令牌
+	refreshTokenClaims, err := s.rotateRefreshToken(user.ID, roleId, familyID, jti, refreshToken)
 	if err != nil {
-		s.Logger.Error("Error generating new access token", zap.Error(err), zap.Int64("userID", user.ID))
 		return nil, nil, err
 	}
 
-	var expTime = int64(claimsMap["exp"].(float64))
+	// 生成新的访问令牌，与刷新令牌属于同一令牌族
+	accessTokenClaims, err := s.JWTService.GenerateAccessToken(user.ID, roleId, refreshTokenClaims.FamilyID)
+	if err != nil {
+		s.Logger.Error("Error generating new access token", zap.Error(err), zap.Int64("userID", user.ID))
+		return nil, nil, err
+	}
 
 	authTokens := &AuthTokens{
 		AccessToken:               accessTokenClaims.Token,
 		ExpirationAccessDateTime:  accessTokenClaims.ExpirationTime,
-		RefreshToken:              refreshToken,
-		ExpirationRefreshDateTime: time.Unix(expTime, 0),
+		RefreshToken:              refreshTokenClaims.Token,
+		ExpirationRefreshDateTime: refreshTokenClaims.ExpirationTime,
 	}
 	ctx := context.Background()
 	s.RedisClient.Set(ctx, GetUserTokenKey(user.ID), accessTokenClaims.Token, UserTokenExpireDuration)
+	s.RedisClient.Set(ctx, GetUserRefreshTokenKey(user.ID), refreshTokenClaims.Token, RefreshTokenExpireDuration)
 
 	s.Logger.Info("Access token refreshed successfully", zap.Int64("userID", user.ID))
 	return user, authTokens, nil
@@ -262,7 +297,16 @@ func (s *AuthUseCase) Register(user RegisterUser) (*domain.CommonResponse[Securi
 	userRepo.UUID = uuid.New().String()
 	userRepo.Status = 1
 
-	res, err := s.UserRepository.Create(&userRepo)
+	// 注册事件与用户在同一事务中写入发件箱，由 relay 投递
+	res, err := s.UserRepository.CreateWithOutbox(&userRepo, func(created *domainUser.User) (*domainOutbox.OutboxEvent, error) {
+		return outbox.NewOutboxEvent(&eventModel.UserRegisteredEvent{
+			ID:           uuid.New().String(),
+			UserID:       strconv.FormatInt(created.ID, 10),
+			Username:     created.UserName,
+			Email:        created.Email,
+			RegisteredAt: time.Now(),
+		}, "")
+	})
 	if err != nil {
 		return &domain.CommonResponse[SecurityRegisterUser]{}, err
 	}
@@ -296,6 +340,7 @@ func (s *AuthUseCase) Logout(jwtToken string) (*domain.CommonResponse[string], e
 	}
 
 	userID := int64(claimsMap["id"].(float64))
+	familyID, _ := claimsMap["fid"].(string)
 
 	// 检查token是否已经在黑名单中
 	exist, err := s.jwtBlacklistRepository.IsJwtInBlacklist(jwtToken)
@@ -313,6 +358,14 @@ func (s *AuthUseCase) Logout(jwtToken string) (*domain.CommonResponse[string], e
 		return nil, domainErrors.NewAppError(err, domainErrors.TokenError)
 	}
 
+	// 吊销本次登录的令牌族，该会话轮换出的刷新令牌都随之失效；升级前签发的令牌没有令牌族
+	if familyID != "" {
+		if err := s.revokeTokenFamily(familyID); err != nil {
+			s.Logger.Error("Error revoking refresh token family on logout", zap.Error(err), zap.Int64("userID", userID))
+			return nil, domainErrors.NewAppError(err, domainErrors.TokenError)
+		}
+	}
+
 	// 获取并加入刷新令牌到黑名单
 	if refreshToken, err := s.RedisClient.Get(ctx, GetUserRefreshTokenKey(userID)).Result(); err == nil {
 		if refreshToken != "" && refreshToken != jwtToken {
@@ -10,11 +10,17 @@ import (
 const (
 	UserTokenKeyPrefix        = "user_token:%d"
 	UserRefreshTokenKeyPrefix = "user_refresh_token:%d"
+	// 令牌族当前有效的刷新令牌 jti
+	RefreshTokenFamilyKeyPrefix = "refresh_token_family:%s"
+	// 宽限期内旧 jti 对应的继任刷新令牌
+	RefreshTokenSuccessorKeyPrefix = "refresh_token_family:%s:successor:%s"
 )
 
 var (
 	UserTokenExpireDuration    = time.Minute * getEnvAsInt64OrDefault("JWT_ACCESS_TIME_MINUTE", 60)
 	RefreshTokenExpireDuration = getEnvAsInt64OrDefault("JWT_REFRESH_TIME_HOUR", 24) * time.Hour
+	// 刷新令牌轮换后，旧令牌在该时间内再次使用时返回同一个继任令牌，不视为重放；0 表示关闭
+	RefreshTokenReuseGrace = getEnvAsInt64OrDefault("JWT_REFRESH_REUSE_GRACE_SECOND", 10) * time.Second
 )
 
 func GetUserTokenKey(userID int64) string {
@@ -25,6 +31,14 @@ func GetUserRefreshTokenKey(userID int64) string {
 	return fmt.Sprintf(UserRefreshTokenKeyPrefix, userID)
 }
 
+func GetRefreshTokenFamilyKey(familyID string) string {
+	return fmt.Sprintf(RefreshTokenFamilyKeyPrefix, familyID)
+}
+
+func GetRefreshTokenSuccessorKey(familyID string, jti string) string {
+	return fmt.Sprintf(RefreshTokenSuccessorKeyPrefix, familyID, jti)
+}
+
 func getEnvAsInt64OrDefault(key string, defaultValue int64) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		if intValue, err := strconv.ParseInt(value, 10, 64); err == nil {
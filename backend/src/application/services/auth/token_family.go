@@ -0,0 +1,175 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/security"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// 令牌族状态
+const (
+	tokenFamilyRevoked = -1 // 不存在：已吊销或已过期
+	tokenFamilyReused  = 0  // jti 不是当前令牌：已轮换过的令牌被再次使用
+	tokenFamilyCurrent = 1
+	tokenFamilyRotated = 2 // jti 刚被轮换（如两个标签页同时刷新），宽限期内返回已签发的继任令牌
+)
+
+// rotateTokenFamilyScript 当前 jti 匹配时切换到新 jti 并续期，同时在宽限期内记录旧 jti 的继任令牌；
+// jti 不匹配但继任令牌仍在时返回继任令牌。返回 {状态, 继任令牌}
+var rotateTokenFamilyScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if not current then
+    return {-1, ''}
+end
+if current ~= ARGV[1] then
+    local successor = redis.call('GET', KEYS[2])
+    if successor then
+        return {2, successor}
+    end
+    return {0, ''}
+end
+redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+if tonumber(ARGV[5]) > 0 then
+    redis.call('SET', KEYS[2], ARGV[4], 'PX', ARGV[5])
+end
+return {1, ''}
+`)
+
+// startTokenFamily 登录时登记新的令牌族
+func (s *AuthUseCase) startTokenFamily(refreshToken *security.AppToken) error {
+	return s.RedisClient.Set(context.Background(), GetRefreshTokenFamilyKey(refreshToken.FamilyID),
+		refreshToken.JTI, RefreshTokenExpireDuration).Err()
+}
+
+// checkTokenFamily 判断 jti 是否为令牌族当前的刷新令牌，或仍在宽限期内的上一个令牌
+func (s *AuthUseCase) checkTokenFamily(familyID string, jti string) (int, error) {
+	ctx := context.Background()
+	current, err := s.RedisClient.Get(ctx, GetRefreshTokenFamilyKey(familyID)).Result()
+	if err == redis.Nil {
+		return tokenFamilyRevoked, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if current == jti {
+		return tokenFamilyCurrent, nil
+	}
+	exists, err := s.RedisClient.Exists(ctx, GetRefreshTokenSuccessorKey(familyID, jti)).Result()
+	if err != nil {
+		return 0, err
+	}
+	if exists > 0 {
+		return tokenFamilyRotated, nil
+	}
+	return tokenFamilyReused, nil
+}
+
+// rotateTokenFamily 原子地把令牌族从 jti 切换到新令牌，并发刷新时只有一个请求轮换，其余在宽限期内拿到同一个继任令牌
+func (s *AuthUseCase) rotateTokenFamily(familyID string, jti string, next *security.AppToken) (int, string, error) {
+	result, err := rotateTokenFamilyScript.Run(context.Background(), s.RedisClient,
+		[]string{GetRefreshTokenFamilyKey(familyID), GetRefreshTokenSuccessorKey(familyID, jti)},
+		jti, next.JTI, RefreshTokenExpireDuration.Milliseconds(), next.Token, RefreshTokenReuseGrace.Milliseconds()).Slice()
+	if err != nil {
+		return 0, "", err
+	}
+	if len(result) != 2 {
+		return 0, "", errors.New("unexpected token family rotation result")
+	}
+	state, _ := result[0].(int64)
+	successor, _ := result[1].(string)
+	return int(state), successor, nil
+}
+
+// revokeTokenFamily 删除令牌族，之后该族的刷新令牌都无法再使用
+func (s *AuthUseCase) revokeTokenFamily(familyID string) error {
+	return s.RedisClient.Del(context.Background(), GetRefreshTokenFamilyKey(familyID)).Err()
+}
+
+// revokeReusedTokenFamily 已轮换的刷新令牌在宽限期后被再次使用，视为令牌被盗：吊销整个令牌族并让用户所有设备下线
+func (s *AuthUseCase) revokeReusedTokenFamily(userID int64, familyID string, refreshToken string) {
+	s.Logger.Warn("Refresh token reuse detected, revoking token family",
+		zap.Int64("userID", userID), zap.String("familyID", familyID))
+	if err := s.revokeTokenFamily(familyID); err != nil {
+		s.Logger.Error("Error revoking refresh token family", zap.Error(err), zap.String("familyID", familyID))
+	}
+	if err := s.jwtBlacklistRepository.AddToBlacklist(refreshToken); err != nil {
+		s.Logger.Error("Error adding reused refresh token to blacklist", zap.Error(err), zap.Int64("userID", userID))
+	}
+	s.sessionManager.ForceLogout(userID, "Your session has been revoked for security reasons, please log in again")
+}
+
+// rotateRefreshToken 签发同一令牌族的新刷新令牌；升级前签发的令牌没有令牌族，加入黑名单并开启新的令牌族
+func (s *AuthUseCase) rotateRefreshToken(userID int64, roleID int64, familyID string, jti string, refreshToken string) (*security.AppToken, error) {
+	if familyID == "" {
+		next, err := s.JWTService.GenerateJWTToken(userID, roleID, security.Refresh)
+		if err == nil {
+			err = s.startTokenFamily(next)
+		}
+		if err == nil {
+			err = s.jwtBlacklistRepository.AddToBlacklist(refreshToken)
+		}
+		if err != nil {
+			s.Logger.Error("Error starting refresh token family", zap.Error(err), zap.Int64("userID", userID))
+			return nil, domainErrors.NewAppError(err, domainErrors.TokenError)
+		}
+		return next, nil
+	}
+
+	next, err := s.JWTService.GenerateRefreshToken(userID, roleID, familyID)
+	if err != nil {
+		s.Logger.Error("Error generating refresh token", zap.Error(err), zap.Int64("userID", userID))
+		return nil, err
+	}
+	state, successor, err := s.rotateTokenFamily(familyID, jti, next)
+	if err != nil {
+		s.Logger.Error("Error rotating refresh token family", zap.Error(err), zap.Int64("userID", userID))
+		return nil, domainErrors.NewAppError(err, domainErrors.TokenError)
+	}
+	// 检查之后被并发请求抢先轮换：宽限期内复用继任令牌，否则视为重放
+	if err := s.tokenFamilyError(userID, familyID, refreshToken, state); err != nil {
+		return nil, err
+	}
+	if state == tokenFamilyRotated {
+		return s.successorToken(userID, familyID, successor)
+	}
+	return next, nil
+}
+
+// successorToken 还原宽限期内已签发的继任令牌
+func (s *AuthUseCase) successorToken(userID int64, familyID string, successor string) (*security.AppToken, error) {
+	claims, err := s.JWTService.GetClaimsAndVerifyToken(successor, security.Refresh)
+	if err != nil {
+		s.Logger.Error("Error verifying successor refresh token", zap.Error(err), zap.Int64("userID", userID))
+		return nil, domainErrors.NewAppError(err, domainErrors.TokenError)
+	}
+	s.Logger.Info("Refresh token was just rotated, returning its successor",
+		zap.Int64("userID", userID), zap.String("familyID", familyID))
+	jti, _ := claims["jti"].(string)
+	exp, _ := claims["exp"].(float64)
+	return &security.AppToken{
+		Token:          successor,
+		TokenType:      security.Refresh,
+		ExpirationTime: time.Unix(int64(exp), 0),
+		JTI:            jti,
+		FamilyID:       familyID,
+	}, nil
+}
+
+// tokenFamilyError 令牌族状态对应的错误，重放时先吊销令牌族
+func (s *AuthUseCase) tokenFamilyError(userID int64, familyID string, refreshToken string, state int) error {
+	switch state {
+	case tokenFamilyCurrent, tokenFamilyRotated:
+		return nil
+	case tokenFamilyReused:
+		s.revokeReusedTokenFamily(userID, familyID, refreshToken)
+		return domainErrors.NewAppError(errors.New("refresh token reuse detected, please log in again"), domainErrors.TokenError)
+	default:
+		s.Logger.Warn("Refresh token family has been revoked", zap.Int64("userID", userID), zap.String("familyID", familyID))
+		return domainErrors.NewAppError(errors.New("refresh token has been revoked"), domainErrors.TokenError)
+	}
+}
@@ -0,0 +1,222 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	domainUser "github.com/gbrayhan/microservices-go/src/domain/user"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	ws "github.com/gbrayhan/microservices-go/src/infrastructure/lib/websocket"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/user"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/security"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testJWTConfig = security.JWTConfig{
+	AccessSecret:  "access",
+	RefreshSecret: "refresh",
+	AccessTime:    60,
+	RefreshTime:   24,
+}
+
+type memoryBlacklist struct {
+	mutex  sync.Mutex
+	tokens map[string]bool
+}
+
+func (b *memoryBlacklist) AddToBlacklist(jwtToken string) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.tokens[jwtToken] = true
+	return nil
+}
+
+func (b *memoryBlacklist) IsJwtInBlacklist(token string) (bool, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.tokens[token], nil
+}
+
+type fakeUserRepository struct {
+	user.UserRepositoryInterface
+}
+
+func (r fakeUserRepository) GetByID(id int) (*domainUser.User, error) {
+	return &domainUser.User{ID: int64(id)}, nil
+}
+
+func newTestAuthUseCase(t *testing.T) (*AuthUseCase, *miniredis.Miniredis, *memoryBlacklist) {
+	t.Helper()
+	loggerInstance, err := logger.NewLogger()
+	require.NoError(t, err)
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	blacklist := &memoryBlacklist{tokens: make(map[string]bool)}
+	return &AuthUseCase{
+		UserRepository:         fakeUserRepository{},
+		JWTService:             security.NewJWTServiceWithConfig(testJWTConfig),
+		Logger:                 loggerInstance,
+		jwtBlacklistRepository: blacklist,
+		RedisClient:            client,
+		sessionManager:         ws.NewSessionManager(),
+	}, server, blacklist
+}
+
+// startTestFamily 模拟登录，返回登记了令牌族的刷新令牌
+func startTestFamily(t *testing.T, s *AuthUseCase) *security.AppToken {
+	t.Helper()
+	refreshToken, err := s.JWTService.GenerateJWTToken(1, 2, security.Refresh)
+	require.NoError(t, err)
+	require.NoError(t, s.startTokenFamily(refreshToken))
+	return refreshToken
+}
+
+// connectTestSession 为用户登记一个 websocket 会话，返回客户端连接
+func connectTestSession(t *testing.T, sessionManager *ws.SessionManager, userID int64) *websocket.Conn {
+	t.Helper()
+	added := make(chan struct{})
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		sessionManager.AddSession(userID, "device-1", conn)
+		close(added)
+	}))
+	t.Cleanup(server.Close)
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	<-added
+	return client
+}
+
+func TestRefreshRotatesTokenFamily(t *testing.T) {
+	s, server, _ := newTestAuthUseCase(t)
+	first := startTestFamily(t, s)
+
+	_, tokens, err := s.AccessTokenByRefreshToken(first.Token)
+	require.NoError(t, err)
+	claims, err := s.JWTService.GetClaimsAndVerifyToken(tokens.RefreshToken, security.Refresh)
+	require.NoError(t, err)
+	assert.Equal(t, first.FamilyID, claims["fid"])
+	assert.NotEqual(t, first.JTI, claims["jti"])
+	current, err := server.Get(GetRefreshTokenFamilyKey(first.FamilyID))
+	require.NoError(t, err)
+	assert.Equal(t, claims["jti"], current)
+
+	accessClaims, err := s.JWTService.GetClaimsAndVerifyToken(tokens.AccessToken, security.Access)
+	require.NoError(t, err)
+	assert.Equal(t, first.FamilyID, accessClaims["fid"], "access token is bound to the family")
+
+	_, _, err = s.AccessTokenByRefreshToken(tokens.RefreshToken)
+	require.NoError(t, err)
+}
+
+func TestReplayedRefreshTokenRevokesFamily(t *testing.T) {
+	s, server, blacklist := newTestAuthUseCase(t)
+	client := connectTestSession(t, s.sessionManager, 1)
+	first := startTestFamily(t, s)
+
+	_, tokens, err := s.AccessTokenByRefreshToken(first.Token)
+	require.NoError(t, err)
+	// 宽限期过后再使用已轮换的令牌
+	server.FastForward(RefreshTokenReuseGrace + time.Second)
+
+	_, _, err = s.AccessTokenByRefreshToken(first.Token)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "reuse detected")
+	assert.False(t, server.Exists(GetRefreshTokenFamilyKey(first.FamilyID)), "family is deleted")
+	assert.True(t, blacklist.tokens[first.Token])
+
+	require.NoError(t, client.SetReadDeadline(time.Now().Add(2*time.Second)))
+	var message map[string]interface{}
+	require.NoError(t, client.ReadJSON(&message))
+	assert.Equal(t, "FORCE_LOGOUT", message["type"])
+
+	// 令牌族被吊销后，攻击者和用户手里的令牌都不能再刷新
+	_, _, err = s.AccessTokenByRefreshToken(tokens.RefreshToken)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "revoked")
+}
+
+func TestConcurrentRefreshReturnsSameSuccessor(t *testing.T) {
+	s, server, _ := newTestAuthUseCase(t)
+	first := startTestFamily(t, s)
+
+	const tabs = 5
+	results := make([]*AuthTokens, tabs)
+	errs := make([]error, tabs)
+	var wg sync.WaitGroup
+	for i := 0; i < tabs; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, results[i], errs[i] = s.AccessTokenByRefreshToken(first.Token)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < tabs; i++ {
+		require.NoError(t, errs[i], "tab %d", i)
+		assert.Equal(t, results[0].RefreshToken, results[i].RefreshToken, "all tabs share the successor")
+	}
+	assert.True(t, server.Exists(GetRefreshTokenFamilyKey(first.FamilyID)), "family is not revoked")
+
+	// 宽限期内顺序重试同样返回继任令牌
+	_, retried, err := s.AccessTokenByRefreshToken(first.Token)
+	require.NoError(t, err)
+	assert.Equal(t, results[0].RefreshToken, retried.RefreshToken)
+}
+
+func TestLegacyRefreshTokenStartsFamily(t *testing.T) {
+	s, server, blacklist := newTestAuthUseCase(t)
+	// 升级前签发的刷新令牌没有 jti 和 fid
+	legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &security.Claims{
+		ID:     1,
+		RoleID: 2,
+		Type:   security.Refresh,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}).SignedString([]byte(testJWTConfig.RefreshSecret))
+	require.NoError(t, err)
+
+	_, tokens, err := s.AccessTokenByRefreshToken(legacy)
+	require.NoError(t, err)
+	claims, err := s.JWTService.GetClaimsAndVerifyToken(tokens.RefreshToken, security.Refresh)
+	require.NoError(t, err)
+	familyID, _ := claims["fid"].(string)
+	require.NotEmpty(t, familyID)
+	assert.True(t, server.Exists(GetRefreshTokenFamilyKey(familyID)))
+	assert.True(t, blacklist.tokens[legacy], "legacy token can only be used once")
+
+	_, _, err = s.AccessTokenByRefreshToken(legacy)
+	assert.Error(t, err)
+}
+
+func TestLogoutRevokesTokenFamily(t *testing.T) {
+	s, server, _ := newTestAuthUseCase(t)
+	first := startTestFamily(t, s)
+	_, tokens, err := s.AccessTokenByRefreshToken(first.Token)
+	require.NoError(t, err)
+
+	_, err = s.Logout(tokens.AccessToken)
+	require.NoError(t, err)
+	assert.False(t, server.Exists(GetRefreshTokenFamilyKey(first.FamilyID)))
+
+	_, _, err = s.AccessTokenByRefreshToken(tokens.RefreshToken)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "revoked")
+}
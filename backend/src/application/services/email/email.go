@@ -18,6 +18,7 @@ import (
 
 type IEmailService interface {
 	SendForgetPasswordEmail(userName string) error
+	IssueResetLink(userID int64) (string, error)
 }
 
 type EmailUseCase struct {
@@ -43,33 +44,36 @@ func NewEmailUseCase(
 	}
 }
 
-// SendEmail implements IEmailService.
+// SendEmail implements IEmailService. eventBus 写入发件箱，事件只包含用户ID和邮箱，发送邮件时再生成重置链接
 func (e *EmailUseCase) SendForgetPasswordEmail(email string) error {
 	user, err := e.userRepository.GetByEmail(email)
 	if err != nil || user == nil {
 		return errors.New("place enter a valid email")
 	}
-	resetLink, err := e.generateResetLink(user.ID)
-	if err != nil {
-		return err
-	}
 	event := &model.ForgetPasswordEvent{
 		ID:           uuid.New().String(),
+		UserID:       user.ID,
 		To:           email,
 		Subject:      "Reset Password",
-		Body:         "Please click the link to reset your password: " + resetLink,
 		RegisteredAt: time.Now(),
 	}
 	return e.eventBus.Publish(context.Background(), event)
 }
 
+// IssueResetLink implements IEmailService，生成并保存重置令牌，返回重置链接
+func (e *EmailUseCase) IssueResetLink(userID int64) (string, error) {
+	return e.generateResetLink(userID)
+}
+
 func (e *EmailUseCase) generateResetLink(userId int64) (string, error) {
 	token, err := e.generateSecureToken(userId)
 	if err != nil {
 		return "", err
 	}
 	//  将token存储到数据库或缓存中
-	e.RedisClient.Set(context.Background(), GetUserIdTokenKey(userId), token, UserTokenExpireDuration)
+	if err := e.RedisClient.Set(context.Background(), GetUserIdTokenKey(userId), token, UserTokenExpireDuration).Err(); err != nil {
+		return "", err
+	}
 
 	// 返回包含token的链接
 	return fmt.Sprintf("%s/#/auth/reset-password?token=%s", os.Getenv("SERVER_FRONTEND_URL"), token), nil
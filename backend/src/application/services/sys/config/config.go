@@ -2,11 +2,15 @@
 package config
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 
+	"github.com/gbrayhan/microservices-go/src/application/event/bus"
+	"github.com/gbrayhan/microservices-go/src/application/event/model"
 	configDomain "github.com/gbrayhan/microservices-go/src/domain/sys/config"
 	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
 
@@ -22,16 +26,19 @@ type ISysConfigService interface {
 
 type SysConfigUseCase struct {
 	Logger     *logger.Logger
+	eventBus   bus.EventBus
 	configPath string
 	configData map[string]map[string]interface{}
 	mutex      sync.RWMutex
 }
 
 func NewSysConfigUseCase(
+	eventBus bus.EventBus,
 	loggerInstance *logger.Logger) ISysConfigService {
 
 	service := &SysConfigUseCase{
 		Logger:     loggerInstance,
+		eventBus:   eventBus,
 		configPath: "config.yaml", // 默认配置文件路径
 		configData: make(map[string]map[string]interface{}),
 	}
@@ -42,8 +49,27 @@ func NewSysConfigUseCase(
 	return service
 }
 
-// Update 更新配置文件中指定模块的配置
+// Update 更新配置文件中指定模块的配置，保存成功后发布 ConfigUpdated 事件
 func (s *SysConfigUseCase) Update(module string, dataMap map[string]interface{}) error {
+	if err := s.updateModule(module, dataMap); err != nil {
+		return err
+	}
+
+	// 事件只带配置项名称，配置值可能包含密钥
+	keys := make([]string, 0, len(dataMap))
+	for key := range dataMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	bus.PublishBestEffort(context.Background(), s.eventBus, s.Logger, &model.ConfigUpdatedEvent{
+		DomainEventBase: model.NewDomainEventBase(),
+		Module:          module,
+		Keys:            keys,
+	})
+	return nil
+}
+
+func (s *SysConfigUseCase) updateModule(module string, dataMap map[string]interface{}) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
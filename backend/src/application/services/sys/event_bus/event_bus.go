@@ -0,0 +1,80 @@
+package event_bus
+
+import (
+	"errors"
+
+	"github.com/gbrayhan/microservices-go/src/application/event/bus"
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainEventBus "github.com/gbrayhan/microservices-go/src/domain/sys/event_bus"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"go.uber.org/zap"
+)
+
+// 单次列出/重放的默认与最大条数
+const (
+	defaultDeadLetterLimit = 50
+	maxDeadLetterLimit     = 500
+)
+
+type IEventBusService interface {
+	ListDeadLetters(limit int) (*[]domainEventBus.DeadLetter, error)
+	ReplayDeadLetters(messageIDs []string, limit int) (*domainEventBus.ReplayResult, error)
+}
+
+type EventBusUseCase struct {
+	eventBus bus.EventBus
+	Logger   *logger.Logger
+}
+
+func NewEventBusUseCase(eventBus bus.EventBus, loggerInstance *logger.Logger) IEventBusService {
+	return &EventBusUseCase{eventBus: eventBus, Logger: loggerInstance}
+}
+
+func (s *EventBusUseCase) ListDeadLetters(limit int) (*[]domainEventBus.DeadLetter, error) {
+	store, err := s.deadLetterStore()
+	if err != nil {
+		return nil, err
+	}
+	deadLetters, err := store.ListDeadLetters(normalizeLimit(limit))
+	if err != nil {
+		s.Logger.Error("Error listing dead letters", zap.Error(err))
+		return nil, domainErrors.NewAppError(err, domainErrors.UnknownError)
+	}
+	return &deadLetters, nil
+}
+
+func (s *EventBusUseCase) ReplayDeadLetters(messageIDs []string, limit int) (*domainEventBus.ReplayResult, error) {
+	store, err := s.deadLetterStore()
+	if err != nil {
+		return nil, err
+	}
+	if len(messageIDs) > 0 {
+		limit = len(messageIDs)
+	}
+	replayed, err := store.ReplayDeadLetters(messageIDs, normalizeLimit(limit))
+	if err != nil {
+		s.Logger.Error("Error replaying dead letters", zap.Error(err), zap.Int("replayed", replayed))
+		return nil, domainErrors.NewAppError(err, domainErrors.UnknownError)
+	}
+	s.Logger.Info("Dead letters replayed", zap.Int("replayed", replayed))
+	return &domainEventBus.ReplayResult{Replayed: replayed}, nil
+}
+
+// deadLetterStore 只有 RabbitMQ 事件总线有死信队列
+func (s *EventBusUseCase) deadLetterStore() (bus.DeadLetterStore, error) {
+	store, ok := s.eventBus.(bus.DeadLetterStore)
+	if !ok {
+		return nil, domainErrors.NewAppError(errors.New("the configured event bus does not support dead letters"), domainErrors.ValidationError)
+	}
+	return store, nil
+}
+
+func normalizeLimit(limit int) int {
+	if limit <= 0 {
+		return defaultDeadLetterLimit
+	}
+	if limit > maxDeadLetterLimit {
+		return maxDeadLetterLimit
+	}
+	return limit
+}
@@ -1,8 +1,12 @@
 package role
 
 import (
+	"context"
+	"fmt"
 	"strconv"
 
+	"github.com/gbrayhan/microservices-go/src/application/event/bus"
+	"github.com/gbrayhan/microservices-go/src/application/event/model"
 	menuRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/base_menu"
 
 	"github.com/gbrayhan/microservices-go/src/domain"
@@ -41,6 +45,7 @@ type SysRoleUseCase struct {
 	sysMenuRepository     menuRepo.MenuRepositoryInterface
 	casbinRuleRepo        casbinRepo.ICasbinRuleRepository
 	sysRoleBtnRepo        roleBtnRepo.ISysRoleBtnRepository
+	eventBus              bus.EventBus
 
 	Logger *logger.Logger
 }
@@ -51,6 +56,7 @@ func NewSysRoleUseCase(
 	casbinRuleRepo casbinRepo.ICasbinRuleRepository,
 	sysMenuRepository menuRepo.MenuRepositoryInterface,
 	sysRoleBtnRepo roleBtnRepo.ISysRoleBtnRepository,
+	eventBus bus.EventBus,
 	loggerInstance *logger.Logger) ISysRoleService {
 	return &SysRoleUseCase{
 		sysRoleRepository:     sysRoleRepository,
@@ -58,6 +64,7 @@ func NewSysRoleUseCase(
 		sysMenuRepository:     sysMenuRepository,
 		sysRoleBtnRepo:        sysRoleBtnRepo,
 		casbinRuleRepo:        casbinRuleRepo,
+		eventBus:              eventBus,
 		Logger:                loggerInstance,
 	}
 }
@@ -219,16 +226,62 @@ func (s *SysRoleUseCase) GetRoleMenuIds(id int64) (map[int][]int, map[int64][]in
 }
 
 func (s *SysRoleUseCase) UpdateRoleMenuIds(id int, updateMap map[string]any) error {
-	return s.sysRoleMenuRepository.Insert(id, updateMap)
+	if err := s.sysRoleMenuRepository.Insert(id, updateMap); err != nil {
+		return err
+	}
+	s.publish(&model.RoleMenuPermissionsChangedEvent{
+		DomainEventBase: model.NewDomainEventBase(),
+		RoleID:          int64(id),
+		Scope:           model.MenuPermissionScopeMenus,
+		MenuIDs:         toInt64Slice(updateMap["menuIds"]),
+	})
+	return nil
 }
 func (s *SysRoleUseCase) GetApiRuleList(roleId int) ([]string, error) {
 	return s.casbinRuleRepo.GetByRoleId(roleId)
 
 }
 func (s *SysRoleUseCase) BindApiRule(roleId int, updateMap map[string]interface{}) error {
-	return s.casbinRuleRepo.Insert(roleId, updateMap)
+	if err := s.casbinRuleRepo.Insert(roleId, updateMap); err != nil {
+		return err
+	}
+	apiPaths := make([]string, 0)
+	if items, ok := updateMap["apiPaths"].([]interface{}); ok {
+		for _, item := range items {
+			apiPaths = append(apiPaths, fmt.Sprint(item))
+		}
+	}
+	s.publish(&model.RoleApiRulesChangedEvent{DomainEventBase: model.NewDomainEventBase(), RoleID: roleId, ApiPaths: apiPaths})
+	return nil
 }
 
 func (s *SysRoleUseCase) BindRoleMenuBtns(roleId int64, updateMap map[string]interface{}) error {
-	return s.sysRoleBtnRepo.Insert(roleId, updateMap)
+	if err := s.sysRoleBtnRepo.Insert(roleId, updateMap); err != nil {
+		return err
+	}
+	menuID, _ := updateMap["menuId"].(float64)
+	s.publish(&model.RoleMenuPermissionsChangedEvent{
+		DomainEventBase: model.NewDomainEventBase(),
+		RoleID:          roleId,
+		Scope:           model.MenuPermissionScopeButtons,
+		MenuID:          int64(menuID),
+		BtnIDs:          toInt64Slice(updateMap["btnIds"]),
+	})
+	return nil
+}
+
+func (s *SysRoleUseCase) publish(event model.ApplicationEvent) {
+	bus.PublishBestEffort(context.Background(), s.eventBus, s.Logger, event)
+}
+
+// toInt64Slice 请求体中的 ID 数组，JSON 数字解码为 float64
+func toInt64Slice(value interface{}) []int64 {
+	items, _ := value.([]interface{})
+	ids := make([]int64, 0, len(items))
+	for _, item := range items {
+		if id, ok := item.(float64); ok {
+			ids = append(ids, int64(id))
+		}
+	}
+	return ids
 }
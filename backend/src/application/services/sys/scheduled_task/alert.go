@@ -0,0 +1,149 @@
+package scheduled_task
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/gbrayhan/microservices-go/src/application/event/bus"
+	"github.com/gbrayhan/microservices-go/src/application/event/model"
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	scheduledTaskDomain "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	taskExecutionLogRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/task_execution_log"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// TaskAlertObserver 任务执行结束后检查告警规则，命中时在事件总线上发布 TaskAlert 事件，
+// 由邮件或 webhook 等订阅者通知任务负责人；每次执行失败还会发布 TaskFailed 事件
+type TaskAlertObserver struct {
+	executionLogRepository taskExecutionLogRepo.ITaskExecutionLogRepository
+	eventBus               bus.EventBus
+	Logger                 *logger.Logger
+}
+
+func NewTaskAlertObserver(
+	executionLogRepository taskExecutionLogRepo.ITaskExecutionLogRepository,
+	eventBus bus.EventBus,
+	loggerInstance *logger.Logger,
+) *TaskAlertObserver {
+	return &TaskAlertObserver{
+		executionLogRepository: executionLogRepository,
+		eventBus:               eventBus,
+		Logger:                 loggerInstance,
+	}
+}
+
+// OnExecutionFinished 实现 scheduler.ExecutionObserver
+func (o *TaskAlertObserver) OnExecutionFinished(task *scheduledTaskDomain.ScheduledTask, executionID string, duration time.Duration, err error) {
+	if err != nil {
+		o.publishFailed(task, executionID, duration, err)
+	}
+
+	if task.AlertMaxDurationSeconds > 0 && duration > time.Duration(task.AlertMaxDurationSeconds)*time.Second {
+		o.publish(task, &model.TaskAlertEvent{
+			Rule:        scheduledTaskDomain.AlertRuleDurationExceeded,
+			Message:     fmt.Sprintf("Task %q took %s, exceeding the limit of %ds", task.TaskName, duration.Round(time.Millisecond), task.AlertMaxDurationSeconds),
+			ExecutionID: executionID,
+			DurationMs:  duration.Milliseconds(),
+			Error:       errorMessage(err),
+		})
+	}
+
+	if err == nil || task.AlertConsecutiveFailures <= 0 {
+		return
+	}
+	failures, loadErr := o.consecutiveFailures(task)
+	if loadErr != nil {
+		o.Logger.Error("Failed to load execution logs for task alert", zap.Int("task_id", task.ID), zap.Error(loadErr))
+		return
+	}
+	// 达到阈值时只告警一次，成功执行后重新计数
+	if failures != task.AlertConsecutiveFailures {
+		return
+	}
+	o.publish(task, &model.TaskAlertEvent{
+		Rule:                scheduledTaskDomain.AlertRuleConsecutiveFailures,
+		Message:             fmt.Sprintf("Task %q failed %d times in a row", task.TaskName, failures),
+		ExecutionID:         executionID,
+		ConsecutiveFailures: failures,
+		DurationMs:          duration.Milliseconds(),
+		Error:               errorMessage(err),
+	})
+}
+
+// consecutiveFailures 读取最近的执行日志计算连续失败次数，重试和跳过的日志也会占用条数，因此多读一些
+func (o *TaskAlertObserver) consecutiveFailures(task *scheduledTaskDomain.ScheduledTask) (int, error) {
+	attempts := task.RetryMaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	limit := (task.AlertConsecutiveFailures+1)*attempts*2 + 10
+	logs, err := o.executionLogRepository.GetByTaskID(uint(task.ID), limit)
+	if err != nil {
+		return 0, err
+	}
+	return consecutiveFailures(runOutcomes(*logs)), nil
+}
+
+func (o *TaskAlertObserver) publish(task *scheduledTaskDomain.ScheduledTask, event *model.TaskAlertEvent) {
+	event.ID = uuid.New().String()
+	event.TaskID = task.ID
+	event.TaskName = task.TaskName
+	event.Recipients = splitAlertEmails(task.AlertEmails)
+	event.RegisteredAt = time.Now()
+
+	o.Logger.Warn("Task alert triggered",
+		zap.Int("task_id", task.ID),
+		zap.String("rule", event.Rule),
+		zap.String("message", event.Message))
+	if err := o.eventBus.Publish(context.Background(), event); err != nil {
+		o.Logger.Error("Failed to publish task alert event", zap.Int("task_id", task.ID), zap.Error(err))
+	}
+}
+
+// publishFailed 发布 TaskFailed 事件，供 webhook 等订阅每次失败
+func (o *TaskAlertObserver) publishFailed(task *scheduledTaskDomain.ScheduledTask, executionID string, duration time.Duration, err error) {
+	event := &model.TaskFailedEvent{
+		ID:           uuid.New().String(),
+		TaskID:       task.ID,
+		TaskName:     task.TaskName,
+		ExecutionID:  executionID,
+		DurationMs:   duration.Milliseconds(),
+		Error:        errorMessage(err),
+		RegisteredAt: time.Now(),
+	}
+	if err := o.eventBus.Publish(context.Background(), event); err != nil {
+		o.Logger.Error("Failed to publish task failed event", zap.Int("task_id", task.ID), zap.Error(err))
+	}
+}
+
+// validateAlertEmails 校验告警邮箱，逗号分隔
+func validateAlertEmails(emails string) error {
+	for _, address := range splitAlertEmails(emails) {
+		if _, err := mail.ParseAddress(address); err != nil {
+			return domainErrors.NewAppError(fmt.Errorf("invalid alert email %q", address), domainErrors.ValidationError)
+		}
+	}
+	return nil
+}
+
+func splitAlertEmails(emails string) []string {
+	var result []string
+	for _, address := range strings.Split(emails, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			result = append(result, address)
+		}
+	}
+	return result
+}
+
+func errorMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,51 @@
+package scheduled_task
+
+import (
+	"time"
+
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	scheduledTaskDomain "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/lib/scheduler"
+	"go.uber.org/zap"
+)
+
+// 预览默认返回的触发次数
+const defaultCronPreviewCount = 5
+
+// PreviewCron implements IScheduledTaskService.
+func (s *ScheduledTaskUseCase) PreviewCron(expression string, timezone string, count int) (*scheduledTaskDomain.CronPreview, error) {
+	s.Logger.Info("Previewing cron expression", zap.String("cron", expression), zap.String("timezone", timezone))
+	loc := s.scheduler.Location()
+	if timezone != "" {
+		var err error
+		if loc, err = scheduler.LoadTimezone(timezone); err != nil {
+			return nil, domainErrors.NewAppError(err, domainErrors.ValidationError)
+		}
+	}
+	if count == 0 {
+		count = defaultCronPreviewCount
+	}
+
+	description, err := scheduler.DescribeCronExpression(expression)
+	if err != nil {
+		return nil, domainErrors.NewAppError(err, domainErrors.ValidationError)
+	}
+	nextRuns, err := scheduler.NextFireTimes(expression, loc, time.Now(), count)
+	if err != nil {
+		return nil, domainErrors.NewAppError(err, domainErrors.ValidationError)
+	}
+	return &scheduledTaskDomain.CronPreview{
+		CronExpression: expression,
+		Description:    description,
+		Timezone:       loc.String(),
+		NextRuns:       nextRuns,
+	}, nil
+}
+
+// validateCronExpression 保存前校验 cron 表达式，避免任务保存成功却永远不会被调度
+func validateCronExpression(expression string) error {
+	if err := scheduler.ValidateCronExpression(expression); err != nil {
+		return domainErrors.NewAppError(err, domainErrors.ValidationError)
+	}
+	return nil
+}
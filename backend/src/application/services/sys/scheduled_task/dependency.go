@@ -0,0 +1,168 @@
+package scheduled_task
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	scheduledTaskDomain "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
+	"go.uber.org/zap"
+)
+
+// GetDAG implements IScheduledTaskService.
+func (s *ScheduledTaskUseCase) GetDAG() (*scheduledTaskDomain.TaskDAG, error) {
+	tasks, err := s.scheduledTaskRepository.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	dependencies, err := s.dependencyRepository.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	dag := &scheduledTaskDomain.TaskDAG{
+		Nodes: make([]scheduledTaskDomain.TaskDAGNode, 0, len(*tasks)),
+		Edges: *dependencies,
+	}
+	for _, task := range *tasks {
+		dag.Nodes = append(dag.Nodes, scheduledTaskDomain.TaskDAGNode{
+			ID:             task.ID,
+			TaskName:       task.TaskName,
+			TaskType:       task.TaskType,
+			CronExpression: task.CronExpression,
+			Status:         task.Status,
+		})
+	}
+	return dag, nil
+}
+
+// validateDependencies 校验上游任务和触发条件，环检测在保存依赖的事务中由 checkDependencyCycle 完成
+func (s *ScheduledTaskUseCase) validateDependencies(taskID int, dependencies []scheduledTaskDomain.TaskDependency) error {
+	seen := make(map[int]bool, len(dependencies))
+	for i := range dependencies {
+		dependency := &dependencies[i]
+		dependency.TaskID = taskID
+		if dependency.TriggerCondition == "" {
+			dependency.TriggerCondition = scheduledTaskDomain.TriggerOnSuccess
+		}
+		switch dependency.TriggerCondition {
+		case scheduledTaskDomain.TriggerOnSuccess, scheduledTaskDomain.TriggerOnFailure, scheduledTaskDomain.TriggerAlways:
+		default:
+			return domainErrors.NewAppError(
+				fmt.Errorf("invalid trigger condition %q", dependency.TriggerCondition), domainErrors.ValidationError)
+		}
+		if dependency.UpstreamTaskID == taskID {
+			return domainErrors.NewAppError(fmt.Errorf("task cannot depend on itself"), domainErrors.ValidationError)
+		}
+		if seen[dependency.UpstreamTaskID] {
+			return domainErrors.NewAppError(
+				fmt.Errorf("duplicate upstream task %d", dependency.UpstreamTaskID), domainErrors.ValidationError)
+		}
+		seen[dependency.UpstreamTaskID] = true
+		if _, err := s.scheduledTaskRepository.GetByID(dependency.UpstreamTaskID); err != nil {
+			return domainErrors.NewAppError(
+				fmt.Errorf("upstream task %d not found", dependency.UpstreamTaskID), domainErrors.ValidationError)
+		}
+	}
+
+	return nil
+}
+
+// checkDependencyCycle 用 existing 中其他任务的依赖加上 taskID 新的依赖检查是否成环；新建任务没有下游，不会成环
+func (s *ScheduledTaskUseCase) checkDependencyCycle(taskID int, dependencies []scheduledTaskDomain.TaskDependency, existing []scheduledTaskDomain.TaskDependency) error {
+	if taskID == 0 {
+		return nil
+	}
+	edges := make([]scheduledTaskDomain.TaskDependency, 0, len(existing)+len(dependencies))
+	for _, dependency := range existing {
+		if dependency.TaskID != taskID {
+			edges = append(edges, dependency)
+		}
+	}
+	edges = append(edges, dependencies...)
+	if cycle := findDependencyCycle(edges); len(cycle) > 0 {
+		path := make([]string, len(cycle))
+		for i, id := range cycle {
+			path[i] = fmt.Sprintf("%d", id)
+		}
+		s.Logger.Warn("Task dependency cycle detected", zap.Int("id", taskID), zap.Ints("cycle", cycle))
+		return domainErrors.NewAppError(
+			fmt.Errorf("dependency cycle detected: %s", strings.Join(path, " -> ")), domainErrors.ValidationError)
+	}
+	return nil
+}
+
+// findDependencyCycle 检查依赖图是否有环，有环时返回环上的任务ID（首尾相同）
+func findDependencyCycle(edges []scheduledTaskDomain.TaskDependency) []int {
+	upstreams := make(map[int][]int)
+	for _, edge := range edges {
+		upstreams[edge.TaskID] = append(upstreams[edge.TaskID], edge.UpstreamTaskID)
+	}
+	nodes := make([]int, 0, len(upstreams))
+	for id := range upstreams {
+		nodes = append(nodes, id)
+	}
+	sort.Ints(nodes)
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[int]int)
+	var stack []int
+	var visit func(id int) []int
+	visit = func(id int) []int {
+		state[id] = visiting
+		stack = append(stack, id)
+		for _, next := range upstreams[id] {
+			switch state[next] {
+			case visiting:
+				for i, node := range stack {
+					if node == next {
+						return append(append([]int{}, stack[i:]...), next)
+					}
+				}
+			case unvisited:
+				if cycle := visit(next); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[id] = visited
+		return nil
+	}
+	for _, id := range nodes {
+		if state[id] == unvisited {
+			if cycle := visit(id); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// dependenciesFromMap 从更新请求中取出依赖配置，不存在时返回 nil
+func dependenciesFromMap(dataMap map[string]interface{}) ([]scheduledTaskDomain.TaskDependency, bool, error) {
+	raw, exists := dataMap["dependencies"]
+	if !exists {
+		return nil, false, nil
+	}
+	delete(dataMap, "dependencies")
+
+	dependencies := make([]scheduledTaskDomain.TaskDependency, 0)
+	if raw == nil {
+		return dependencies, true, nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, true, err
+	}
+	if err := json.Unmarshal(data, &dependencies); err != nil {
+		return nil, true, err
+	}
+	return dependencies, true, nil
+}
@@ -0,0 +1,58 @@
+package scheduled_task
+
+import (
+	"testing"
+
+	scheduledTaskDomain "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func edge(taskID, upstreamTaskID int) scheduledTaskDomain.TaskDependency {
+	return scheduledTaskDomain.TaskDependency{TaskID: taskID, UpstreamTaskID: upstreamTaskID}
+}
+
+func TestFindDependencyCycle(t *testing.T) {
+	// export(1) -> compress(2) -> email(3)，并且 3 同时依赖 1（fan-in）
+	acyclic := []scheduledTaskDomain.TaskDependency{edge(2, 1), edge(3, 2), edge(3, 1)}
+	assert.Nil(t, findDependencyCycle(acyclic))
+
+	cyclic := append(acyclic, edge(1, 3))
+	assert.Equal(t, []int{1, 3, 2, 1}, findDependencyCycle(cyclic))
+
+	assert.Equal(t, []int{4, 4}, findDependencyCycle([]scheduledTaskDomain.TaskDependency{edge(4, 4)}))
+}
+
+func TestCheckDependencyCycle(t *testing.T) {
+	loggerInstance, err := logger.NewLogger()
+	require.NoError(t, err)
+	s := &ScheduledTaskUseCase{Logger: loggerInstance}
+	// 另一个请求已提交 2 依赖 1，此时保存 1 依赖 2 会成环
+	existing := []scheduledTaskDomain.TaskDependency{edge(2, 1), edge(1, 3)}
+
+	assert.Error(t, s.checkDependencyCycle(1, []scheduledTaskDomain.TaskDependency{edge(1, 2)}, existing))
+	// 任务自己原有的依赖会被替换，不参与检测
+	assert.NoError(t, s.checkDependencyCycle(1, []scheduledTaskDomain.TaskDependency{edge(1, 4)}, existing))
+	assert.NoError(t, s.checkDependencyCycle(0, []scheduledTaskDomain.TaskDependency{edge(0, 2)}, existing))
+}
+
+func TestDependenciesFromMap(t *testing.T) {
+	dataMap := map[string]interface{}{
+		"task_name": "compress",
+		"dependencies": []interface{}{
+			map[string]interface{}{"upstream_task_id": float64(1), "trigger_condition": "always"},
+		},
+	}
+
+	dependencies, replace, err := dependenciesFromMap(dataMap)
+
+	assert.NoError(t, err)
+	assert.True(t, replace)
+	assert.Equal(t, []scheduledTaskDomain.TaskDependency{{UpstreamTaskID: 1, TriggerCondition: "always"}}, dependencies)
+	assert.NotContains(t, dataMap, "dependencies")
+
+	_, replace, err = dependenciesFromMap(map[string]interface{}{"task_name": "email"})
+	assert.NoError(t, err)
+	assert.False(t, replace)
+}
@@ -0,0 +1,77 @@
+package scheduled_task
+
+import (
+	"encoding/json"
+	"fmt"
+
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	scheduledTaskDomain "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
+	"gorm.io/datatypes"
+)
+
+// GetFunctions implements IScheduledTaskService.
+func (s *ScheduledTaskUseCase) GetFunctions() []scheduledTaskDomain.TaskFunctionInfo {
+	return s.functionExecutor.Functions()
+}
+
+// GetTaskTypes implements IScheduledTaskService.
+func (s *ScheduledTaskUseCase) GetTaskTypes() []scheduledTaskDomain.TaskTypeInfo {
+	return s.executorManager.Types()
+}
+
+// validateTaskParams 按任务类型（函数任务按函数）注册的 schema 校验 task_params
+func (s *ScheduledTaskUseCase) validateTaskParams(taskType string, taskParams []byte) error {
+	if err := s.executorManager.ValidateParams(taskType, taskParams); err != nil {
+		return domainErrors.NewAppError(err, domainErrors.ValidationError)
+	}
+	return nil
+}
+
+// validateTaskParamsUpdate 更新了 task_type 或 task_params 时，与库中的值合并后再校验；
+// 仍为脱敏占位值的敏感参数恢复为库中的值后写回 dataMap
+func (s *ScheduledTaskUseCase) validateTaskParamsUpdate(id int, dataMap map[string]interface{}) error {
+	rawType, typeChanged := dataMap["task_type"]
+	rawParams, paramsChanged := dataMap["task_params"]
+	if !typeChanged && !paramsChanged {
+		return nil
+	}
+	current, err := s.scheduledTaskRepository.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	taskType := current.TaskType
+	if typeChanged {
+		value, ok := rawType.(string)
+		if !ok {
+			return domainErrors.NewAppError(fmt.Errorf("task_type must be a string"), domainErrors.ValidationError)
+		}
+		taskType = value
+	}
+	taskParams := []byte(current.TaskParams)
+	if paramsChanged {
+		switch value := rawParams.(type) {
+		case string:
+			taskParams = []byte(value)
+		default:
+			if taskParams, err = json.Marshal(value); err != nil {
+				return domainErrors.NewAppError(err, domainErrors.ValidationError)
+			}
+		}
+		taskParams = s.executorManager.RestoreRedactedParams(taskType, current.TaskParams, taskParams)
+		dataMap["task_params"] = datatypes.JSON(taskParams)
+	}
+	return s.validateTaskParams(taskType, taskParams)
+}
+
+// presentTask 接口返回任务前转换时区并隐藏敏感参数
+func (s *ScheduledTaskUseCase) presentTask(task *scheduledTaskDomain.ScheduledTask) {
+	s.localizeTask(task)
+	task.TaskParams = s.executorManager.RedactParams(task.TaskType, task.TaskParams)
+}
+
+func (s *ScheduledTaskUseCase) presentTasks(tasks []scheduledTaskDomain.ScheduledTask) {
+	for i := range tasks {
+		s.presentTask(&tasks[i])
+	}
+}
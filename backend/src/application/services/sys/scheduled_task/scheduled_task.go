@@ -1,16 +1,25 @@
 package scheduled_task
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
+	"github.com/gbrayhan/microservices-go/src/application/event/bus"
+	"github.com/gbrayhan/microservices-go/src/application/event/model"
 	"github.com/gbrayhan/microservices-go/src/domain"
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
 	scheduledTaskDomain "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
 	scheduleTaskConstants "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task/constants"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/lib/executor"
 	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/lib/scheduler"
 	scheduledTaskRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/scheduled_task"
+	taskDependencyRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/task_dependency"
+	taskExecutionLogRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/task_execution_log"
 	"go.uber.org/zap"
+	"gorm.io/datatypes"
 )
 
 type IScheduledTaskService interface {
@@ -24,55 +33,204 @@ type IScheduledTaskService interface {
 	EnableTask(id int) error
 	DisableTask(id int) error
 	ReloadTasks() error
+	CancelTask(id int) error
+	RunTask(id int, params datatypes.JSON) (string, error)
+	GetDAG() (*scheduledTaskDomain.TaskDAG, error)
+	GetFunctions() []scheduledTaskDomain.TaskFunctionInfo
+	GetTaskTypes() []scheduledTaskDomain.TaskTypeInfo
+	PreviewCron(expression string, timezone string, count int) (*scheduledTaskDomain.CronPreview, error)
+	GetStats(id int, days int, lastN int) (*scheduledTaskDomain.TaskStats, error)
+	PauseTask(id int, until *time.Time) error
+	ResumeTask(id int) error
+	GetDashboard(hours int) (*scheduledTaskDomain.TaskDashboard, error)
 }
 
 type ScheduledTaskUseCase struct {
 	scheduledTaskRepository scheduledTaskRepo.IScheduledTaskRepository
+	dependencyRepository    taskDependencyRepo.ITaskDependencyRepository
+	executionLogRepository  taskExecutionLogRepo.ITaskExecutionLogRepository
 	Logger                  *logger.Logger
 	scheduler               *scheduler.TaskScheduler
+	executorManager         *executor.TaskExecutorManager
+	functionExecutor        *executor.FunctionExecutor
+	eventBus                bus.EventBus
 }
 
 func NewScheduledTaskUseCase(
 	scheduledTaskRepository scheduledTaskRepo.IScheduledTaskRepository,
+	dependencyRepository taskDependencyRepo.ITaskDependencyRepository,
+	executionLogRepository taskExecutionLogRepo.ITaskExecutionLogRepository,
 	loggerInstance *logger.Logger, scheduler *scheduler.TaskScheduler,
+	executorManager *executor.TaskExecutorManager,
+	functionExecutor *executor.FunctionExecutor,
+	eventBus bus.EventBus,
 ) IScheduledTaskService {
 	return &ScheduledTaskUseCase{
 		scheduledTaskRepository: scheduledTaskRepository,
+		dependencyRepository:    dependencyRepository,
+		executionLogRepository:  executionLogRepository,
 		Logger:                  loggerInstance,
 		scheduler:               scheduler,
+		executorManager:         executorManager,
+		functionExecutor:        functionExecutor,
+		eventBus:                eventBus,
 	}
 }
 
 func (s *ScheduledTaskUseCase) GetAll() (*[]scheduledTaskDomain.ScheduledTask, error) {
 	s.Logger.Info("Getting all tasks")
-	return s.scheduledTaskRepository.GetAll()
+	tasks, err := s.scheduledTaskRepository.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	s.presentTasks(*tasks)
+	return tasks, nil
 }
 
 func (s *ScheduledTaskUseCase) GetByID(id int) (*scheduledTaskDomain.ScheduledTask, error) {
 	s.Logger.Info("Getting task by ID", zap.Int("id", id))
-	return s.scheduledTaskRepository.GetByID(id)
+	task, err := s.scheduledTaskRepository.GetByID(id)
+	if err != nil {
+		return task, err
+	}
+	dependencies, err := s.dependencyRepository.GetByTaskID(id)
+	if err != nil {
+		return nil, err
+	}
+	task.Dependencies = *dependencies
+	s.presentTask(task)
+	return task, nil
 }
 
 func (s *ScheduledTaskUseCase) Create(newData *scheduledTaskDomain.ScheduledTask) (*scheduledTaskDomain.ScheduledTask, error) {
 	s.Logger.Info("Creating new task", zap.String("TaskName", newData.TaskName))
-	return s.scheduledTaskRepository.Create(newData)
+	if err := validateCronExpression(newData.CronExpression); err != nil {
+		return nil, err
+	}
+	if err := s.normalizeTimezone(&newData.Timezone); err != nil {
+		return nil, err
+	}
+	if err := s.validateTaskParams(newData.TaskType, newData.TaskParams); err != nil {
+		return nil, err
+	}
+	if err := validateAlertEmails(newData.AlertEmails); err != nil {
+		return nil, err
+	}
+	if err := validateBlackoutWindows(newData.BlackoutWindows); err != nil {
+		return nil, err
+	}
+	dependencies := newData.Dependencies
+	if err := s.validateDependencies(0, dependencies); err != nil {
+		return nil, err
+	}
+	if len(dependencies) == 0 {
+		task, err := s.scheduledTaskRepository.Create(newData)
+		if err != nil {
+			return task, err
+		}
+		s.presentTask(task)
+		return task, nil
+	}
+	// 任务和依赖在同一事务中写入，不会留下缺少依赖的任务
+	task, err := s.scheduledTaskRepository.CreateWithDependencies(newData, dependencies)
+	if err != nil {
+		return nil, err
+	}
+	for i := range dependencies {
+		dependencies[i].TaskID = task.ID
+	}
+	s.presentTask(task)
+	task.Dependencies = dependencies
+	return task, nil
 }
 
 func (s *ScheduledTaskUseCase) Delete(ids []int) error {
 	s.Logger.Info("Deleting task", zap.String("ids", fmt.Sprintf("%v", ids)))
-	return s.scheduledTaskRepository.Delete(ids)
+	return s.scheduledTaskRepository.DeleteWithDependencies(ids)
 }
 
 func (s *ScheduledTaskUseCase) Update(id int, userMap map[string]interface{}) (*scheduledTaskDomain.ScheduledTask, error) {
 	s.Logger.Info("Updating task", zap.Int("id", id))
-	return s.scheduledTaskRepository.Update(id, userMap)
+	dependencies, replace, err := dependenciesFromMap(userMap)
+	if err != nil {
+		return nil, domainErrors.NewAppError(err, domainErrors.ValidationError)
+	}
+	if expression, exists := userMap["cron_expression"]; exists {
+		value, _ := expression.(string)
+		if err := validateCronExpression(value); err != nil {
+			return nil, err
+		}
+	}
+	_, cronChanged := userMap["cron_expression"]
+	rawTimezone, timezoneChanged := userMap["timezone"]
+	if timezoneChanged {
+		timezone, ok := rawTimezone.(string)
+		if !ok && rawTimezone != nil {
+			return nil, domainErrors.NewAppError(fmt.Errorf("timezone must be a string"), domainErrors.ValidationError)
+		}
+		if err := s.normalizeTimezone(&timezone); err != nil {
+			return nil, err
+		}
+		userMap["timezone"] = timezone
+	}
+	if err := s.validateTaskParamsUpdate(id, userMap); err != nil {
+		return nil, err
+	}
+	if emails, exists := userMap["alert_emails"]; exists {
+		value, _ := emails.(string)
+		if err := validateAlertEmails(value); err != nil {
+			return nil, err
+		}
+	}
+	if err := blackoutWindowsFromMap(userMap); err != nil {
+		return nil, err
+	}
+	if replace {
+		if err := s.validateDependencies(id, dependencies); err != nil {
+			return nil, err
+		}
+	}
+	var task *scheduledTaskDomain.ScheduledTask
+	switch {
+	case replace:
+		// 任务字段和依赖在同一事务中写入
+		task, err = s.scheduledTaskRepository.UpdateWithDependencies(id, userMap, dependencies,
+			func(existing []scheduledTaskDomain.TaskDependency) error {
+				return s.checkDependencyCycle(id, dependencies, existing)
+			})
+	case len(userMap) > 0:
+		task, err = s.scheduledTaskRepository.Update(id, userMap)
+	default:
+		task, err = s.scheduledTaskRepository.GetByID(id)
+	}
+	if err != nil {
+		return task, err
+	}
+	// 触发规则变化后立即按新的表达式和时区重新调度
+	if cronChanged || timezoneChanged {
+		if err := s.scheduler.UpdateTask(task); err != nil {
+			return nil, err
+		}
+	}
+	s.presentTask(task)
+	if replace {
+		task.Dependencies = dependencies
+	}
+	return task, nil
 }
 
 func (s *ScheduledTaskUseCase) SearchPaginated(filters domain.DataFilters) (*domain.PaginatedResult[scheduledTaskDomain.ScheduledTask], error) {
 	s.Logger.Info("Searching tasks with pagination",
 		zap.Int("page", filters.Page),
 		zap.Int("pageSize", filters.PageSize))
-	return s.scheduledTaskRepository.SearchPaginated(filters)
+	result, err := s.scheduledTaskRepository.SearchPaginated(filters)
+	if err != nil {
+		return nil, err
+	}
+	if result.Data != nil {
+		s.presentTasks(*result.Data)
+	}
+	return result, nil
 }
 
 func (s *ScheduledTaskUseCase) SearchByProperty(property string, searchText string) (*[]string, error) {
@@ -93,7 +251,11 @@ func (s *ScheduledTaskUseCase) DisableTask(taskID int) error {
 		return err
 	}
 
-	return s.scheduler.StopTask(taskID)
+	if err := s.scheduler.StopTask(taskID); err != nil {
+		return err
+	}
+	s.publishStatusChanged(taskID, false)
+	return nil
 }
 
 // EnableTask implements IScheduledTaskService.
@@ -106,10 +268,54 @@ func (s *ScheduledTaskUseCase) EnableTask(taskID int) error {
 		return err
 	}
 
-	return s.scheduler.StartTask(taskID)
+	if err := s.scheduler.StartTask(taskID); err != nil {
+		return err
+	}
+	s.publishStatusChanged(taskID, true)
+	return nil
+}
+
+func (s *ScheduledTaskUseCase) publishStatusChanged(taskID int, enabled bool) {
+	bus.PublishBestEffort(context.Background(), s.eventBus, s.Logger, &model.TaskStatusChangedEvent{
+		DomainEventBase: model.NewDomainEventBase(),
+		TaskID:          taskID,
+		Enabled:         enabled,
+	})
 }
 
 // ReloadTasks implements IScheduledTaskService.
 func (s *ScheduledTaskUseCase) ReloadTasks() error {
 	return s.scheduler.ReloadTasks()
 }
+
+// CancelTask implements IScheduledTaskService.
+func (s *ScheduledTaskUseCase) CancelTask(taskID int) error {
+	s.Logger.Info("Cancelling running task", zap.Int("id", taskID))
+	if err := s.scheduler.CancelExecution(taskID); err != nil {
+		return domainErrors.NewAppError(err, domainErrors.ValidationError)
+	}
+	return nil
+}
+
+// RunTask implements IScheduledTaskService.
+func (s *ScheduledTaskUseCase) RunTask(taskID int, params datatypes.JSON) (string, error) {
+	s.Logger.Info("Running task manually", zap.Int("id", taskID))
+	if len(params) > 0 && !json.Valid(params) {
+		return "", domainErrors.NewAppError(fmt.Errorf("task_params is not valid JSON"), domainErrors.ValidationError)
+	}
+	task, err := s.scheduledTaskRepository.GetByID(taskID)
+	if err != nil {
+		return "", err
+	}
+	if len(params) > 0 {
+		params = s.executorManager.RestoreRedactedParams(task.TaskType, task.TaskParams, params)
+		if err := s.validateTaskParams(task.TaskType, params); err != nil {
+			return "", err
+		}
+	}
+	executionID, err := s.scheduler.RunNow(taskID, params)
+	if err != nil {
+		return "", domainErrors.NewAppError(err, domainErrors.ValidationError)
+	}
+	return executionID, nil
+}
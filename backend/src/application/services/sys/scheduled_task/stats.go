@@ -0,0 +1,283 @@
+package scheduled_task
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	scheduledTaskDomain "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
+	domainTaskExecutionLog "github.com/gbrayhan/microservices-go/src/domain/sys/task_execution_log"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultStatsDays      = 7
+	maxStatsDays          = 90
+	defaultStatsLastRuns  = 20
+	maxStatsLastRuns      = 100
+	defaultDashboardHours = 24
+	maxDashboardHours     = 24 * 30
+	// 单次统计最多读取的执行日志条数
+	statsLogLimit     = 10000
+	dashboardLogLimit = 50000
+	// 概况中每个失败任务展示的最近执行记录数
+	dashboardLastRuns = 5
+)
+
+// GetStats 统计最近 days 天的任务执行情况，lastN 为返回的最近执行记录数
+func (s *ScheduledTaskUseCase) GetStats(id int, days int, lastN int) (*scheduledTaskDomain.TaskStats, error) {
+	if days == 0 {
+		days = defaultStatsDays
+	}
+	if lastN == 0 {
+		lastN = defaultStatsLastRuns
+	}
+	if days < 1 || days > maxStatsDays {
+		return nil, domainErrors.NewAppError(fmt.Errorf("days must be between 1 and %d", maxStatsDays), domainErrors.ValidationError)
+	}
+	if lastN < 1 || lastN > maxStatsLastRuns {
+		return nil, domainErrors.NewAppError(fmt.Errorf("last must be between 1 and %d", maxStatsLastRuns), domainErrors.ValidationError)
+	}
+
+	task, err := s.scheduledTaskRepository.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	since := time.Now().AddDate(0, 0, -days)
+	logs, err := s.executionLogRepository.GetSince(uint(id), since, statsLogLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := computeTaskStats(runOutcomes(*logs), lastN)
+	stats.TaskID = task.ID
+	stats.TaskName = task.TaskName
+	stats.Since, stats.Truncated = coveredSince(*logs, statsLogLimit, since)
+	localizeStats(&stats, s.scheduler.TaskLocation(task))
+	return &stats, nil
+}
+
+// GetDashboard 统计最近 hours 小时所有任务的执行概况
+func (s *ScheduledTaskUseCase) GetDashboard(hours int) (*scheduledTaskDomain.TaskDashboard, error) {
+	if hours == 0 {
+		hours = defaultDashboardHours
+	}
+	if hours < 1 || hours > maxDashboardHours {
+		return nil, domainErrors.NewAppError(fmt.Errorf("hours must be between 1 and %d", maxDashboardHours), domainErrors.ValidationError)
+	}
+
+	tasks, err := s.scheduledTaskRepository.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	since := time.Now().Add(-time.Duration(hours) * time.Hour)
+	logs, err := s.executionLogRepository.GetSince(0, since, dashboardLogLimit)
+	if err != nil {
+		return nil, err
+	}
+	since, truncated := coveredSince(*logs, dashboardLogLimit, since)
+	if truncated {
+		s.Logger.Warn("Dashboard execution logs truncated", zap.Int("limit", dashboardLogLimit), zap.Time("since", since))
+	}
+
+	logsByTask := make(map[int][]domainTaskExecutionLog.TaskExecutionLog)
+	for _, log := range *logs {
+		logsByTask[int(log.TaskID)] = append(logsByTask[int(log.TaskID)], log)
+	}
+
+	dashboard := &scheduledTaskDomain.TaskDashboard{
+		Since:        since,
+		Truncated:    truncated,
+		TotalTasks:   len(*tasks),
+		StatusCounts: make(map[int]int),
+		FailingTasks: []scheduledTaskDomain.TaskStats{},
+	}
+	var durations []int
+	for i := range *tasks {
+		task := &(*tasks)[i]
+		dashboard.StatusCounts[task.Status]++
+
+		outcomes := runOutcomes(logsByTask[task.ID])
+		stats := computeTaskStats(outcomes, dashboardLastRuns)
+		dashboard.TotalRuns += stats.TotalRuns
+		dashboard.SuccessCount += stats.SuccessCount
+		dashboard.FailureCount += stats.FailureCount
+		dashboard.SkippedCount += stats.SkippedCount
+		durations = append(durations, executedDurations(outcomes)...)
+
+		if stats.ConsecutiveFailures > 0 {
+			stats.TaskID = task.ID
+			stats.TaskName = task.TaskName
+			stats.Since = since
+			stats.Truncated = truncated
+			localizeStats(&stats, s.scheduler.TaskLocation(task))
+			dashboard.FailingTasks = append(dashboard.FailingTasks, stats)
+		}
+	}
+	dashboard.SuccessRate = successRate(dashboard.SuccessCount, dashboard.FailureCount)
+	sort.Ints(durations)
+	dashboard.P95DurationMs = percentile(durations, 95)
+	sort.SliceStable(dashboard.FailingTasks, func(i, j int) bool {
+		return dashboard.FailingTasks[i].ConsecutiveFailures > dashboard.FailingTasks[j].ConsecutiveFailures
+	})
+	return dashboard, nil
+}
+
+// coveredSince 日志条数达到 limit 时，统计范围只到读取到的最早一条日志，返回其执行时间并标记为截断
+func coveredSince(logs []domainTaskExecutionLog.TaskExecutionLog, limit int, since time.Time) (time.Time, bool) {
+	if len(logs) < limit || len(logs) == 0 {
+		return since, false
+	}
+	earliest := logs[0].ExecuteTime
+	for _, log := range logs[1:] {
+		if log.ExecuteTime.Before(earliest) {
+			earliest = log.ExecuteTime
+		}
+	}
+	return earliest, true
+}
+
+// runOutcomes 将执行日志按 ExecutionID 合并为每次触发的结果，按执行时间倒序
+// 重试的多次尝试合并为一次，结果取最后一次尝试，耗时为所有尝试之和
+func runOutcomes(logs []domainTaskExecutionLog.TaskExecutionLog) []scheduledTaskDomain.TaskRunOutcome {
+	index := make(map[string]int)
+	lastAttempt := make(map[string]int)
+	outcomes := make([]scheduledTaskDomain.TaskRunOutcome, 0, len(logs))
+	for _, log := range logs {
+		key := log.ExecutionID
+		if key == "" {
+			// 早期日志没有执行ID，每条单独计算
+			key = "log-" + strconv.Itoa(log.ID)
+		}
+		duration := 0
+		if log.ExecuteDuration != nil {
+			duration = *log.ExecuteDuration
+		}
+		i, exists := index[key]
+		if !exists {
+			index[key] = len(outcomes)
+			lastAttempt[key] = log.Attempt
+			outcomes = append(outcomes, scheduledTaskDomain.TaskRunOutcome{
+				ExecutionID:   log.ExecutionID,
+				ExecuteTime:   log.ExecuteTime,
+				ExecuteResult: log.ExecuteResult,
+				DurationMs:    duration,
+				Attempts:      1,
+				ErrorMessage:  log.ErrorMessage,
+			})
+			continue
+		}
+		outcome := &outcomes[i]
+		outcome.Attempts++
+		outcome.DurationMs += duration
+		if log.ExecuteTime.Before(outcome.ExecuteTime) {
+			outcome.ExecuteTime = log.ExecuteTime
+		}
+		if log.Attempt >= lastAttempt[key] {
+			lastAttempt[key] = log.Attempt
+			outcome.ExecuteResult = log.ExecuteResult
+			outcome.ErrorMessage = log.ErrorMessage
+		}
+	}
+	sort.SliceStable(outcomes, func(i, j int) bool {
+		return outcomes[i].ExecuteTime.After(outcomes[j].ExecuteTime)
+	})
+	return outcomes
+}
+
+// computeTaskStats 根据按时间倒序的触发结果计算统计值，跳过的触发不计入成功率和耗时
+func computeTaskStats(outcomes []scheduledTaskDomain.TaskRunOutcome, lastN int) scheduledTaskDomain.TaskStats {
+	stats := scheduledTaskDomain.TaskStats{
+		TotalRuns: len(outcomes),
+		LastRuns:  []scheduledTaskDomain.TaskRunOutcome{},
+	}
+	for i := range outcomes {
+		outcome := outcomes[i]
+		switch outcome.ExecuteResult {
+		case domainTaskExecutionLog.ExecuteResultSuccess:
+			stats.SuccessCount++
+			if stats.LastSuccessTime == nil {
+				stats.LastSuccessTime = &outcome.ExecuteTime
+			}
+		case domainTaskExecutionLog.ExecuteResultFailed:
+			stats.FailureCount++
+			if stats.LastFailureTime == nil {
+				stats.LastFailureTime = &outcome.ExecuteTime
+			}
+		case domainTaskExecutionLog.ExecuteResultSkipped:
+			stats.SkippedCount++
+		}
+		if i < lastN {
+			stats.LastRuns = append(stats.LastRuns, outcome)
+		}
+	}
+	stats.SuccessRate = successRate(stats.SuccessCount, stats.FailureCount)
+	durations := executedDurations(outcomes)
+	sort.Ints(durations)
+	stats.P50DurationMs = percentile(durations, 50)
+	stats.P95DurationMs = percentile(durations, 95)
+	stats.ConsecutiveFailures = consecutiveFailures(outcomes)
+	return stats
+}
+
+// consecutiveFailures 从最近一次触发开始连续失败的次数，跳过的触发不中断计数
+func consecutiveFailures(outcomes []scheduledTaskDomain.TaskRunOutcome) int {
+	count := 0
+	for _, outcome := range outcomes {
+		switch outcome.ExecuteResult {
+		case domainTaskExecutionLog.ExecuteResultFailed:
+			count++
+		case domainTaskExecutionLog.ExecuteResultSuccess:
+			return count
+		}
+	}
+	return count
+}
+
+func executedDurations(outcomes []scheduledTaskDomain.TaskRunOutcome) []int {
+	durations := make([]int, 0, len(outcomes))
+	for _, outcome := range outcomes {
+		if outcome.ExecuteResult != domainTaskExecutionLog.ExecuteResultSkipped {
+			durations = append(durations, outcome.DurationMs)
+		}
+	}
+	return durations
+}
+
+func successRate(success, failure int) float64 {
+	if success+failure == 0 {
+		return 0
+	}
+	return math.Round(float64(success)/float64(success+failure)*10000) / 10000
+}
+
+// percentile 最近秩法计算百分位，sorted 需升序
+func percentile(sorted []int, p float64) int {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// localizeStats 将统计中的时间转换到任务时区，用于展示
+func localizeStats(stats *scheduledTaskDomain.TaskStats, loc *time.Location) {
+	stats.Since = stats.Since.In(loc)
+	if stats.LastSuccessTime != nil {
+		t := stats.LastSuccessTime.In(loc)
+		stats.LastSuccessTime = &t
+	}
+	if stats.LastFailureTime != nil {
+		t := stats.LastFailureTime.In(loc)
+		stats.LastFailureTime = &t
+	}
+	for i := range stats.LastRuns {
+		stats.LastRuns[i].ExecuteTime = stats.LastRuns[i].ExecuteTime.In(loc)
+	}
+}
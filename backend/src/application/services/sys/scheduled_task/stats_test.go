@@ -0,0 +1,81 @@
+package scheduled_task
+
+import (
+	"testing"
+	"time"
+
+	domainTaskExecutionLog "github.com/gbrayhan/microservices-go/src/domain/sys/task_execution_log"
+	"github.com/stretchr/testify/assert"
+)
+
+func executionLog(id int, executionID string, attempt int, result int, durationMs int, at time.Time) domainTaskExecutionLog.TaskExecutionLog {
+	return domainTaskExecutionLog.TaskExecutionLog{
+		ID:              id,
+		ExecutionID:     executionID,
+		Attempt:         attempt,
+		ExecuteResult:   result,
+		ExecuteDuration: &durationMs,
+		ExecuteTime:     at,
+	}
+}
+
+func TestComputeTaskStats(t *testing.T) {
+	base := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	logs := []domainTaskExecutionLog.TaskExecutionLog{
+		// 最近：跳过 -> 失败 -> 失败（重试一次）-> 成功（第二次尝试成功）-> 成功
+		executionLog(7, "e5", 1, domainTaskExecutionLog.ExecuteResultSkipped, 0, base.Add(5*time.Minute)),
+		executionLog(6, "e4", 1, domainTaskExecutionLog.ExecuteResultFailed, 400, base.Add(4*time.Minute)),
+		executionLog(5, "e3", 2, domainTaskExecutionLog.ExecuteResultFailed, 300, base.Add(3*time.Minute+10*time.Second)),
+		executionLog(4, "e3", 1, domainTaskExecutionLog.ExecuteResultFailed, 300, base.Add(3*time.Minute)),
+		executionLog(3, "e2", 2, domainTaskExecutionLog.ExecuteResultSuccess, 200, base.Add(2*time.Minute+10*time.Second)),
+		executionLog(2, "e2", 1, domainTaskExecutionLog.ExecuteResultFailed, 100, base.Add(2*time.Minute)),
+		executionLog(1, "e1", 1, domainTaskExecutionLog.ExecuteResultSuccess, 100, base.Add(time.Minute)),
+	}
+
+	outcomes := runOutcomes(logs)
+	stats := computeTaskStats(outcomes, 3)
+
+	assert.Equal(t, 5, stats.TotalRuns)
+	assert.Equal(t, 2, stats.SuccessCount)
+	assert.Equal(t, 2, stats.FailureCount)
+	assert.Equal(t, 1, stats.SkippedCount)
+	assert.Equal(t, 0.5, stats.SuccessRate)
+	assert.Equal(t, 2, stats.ConsecutiveFailures)
+	// 耗时为所有尝试之和：100, 300, 400, 600
+	assert.Equal(t, 300, stats.P50DurationMs)
+	assert.Equal(t, 600, stats.P95DurationMs)
+	assert.Equal(t, base.Add(2*time.Minute), *stats.LastSuccessTime)
+	assert.Equal(t, base.Add(4*time.Minute), *stats.LastFailureTime)
+
+	assert.Len(t, stats.LastRuns, 3)
+	assert.Equal(t, "e5", stats.LastRuns[0].ExecutionID)
+	assert.Equal(t, 2, stats.LastRuns[2].Attempts)
+}
+
+func TestComputeTaskStatsEmpty(t *testing.T) {
+	stats := computeTaskStats(runOutcomes(nil), 10)
+
+	assert.Equal(t, 0, stats.TotalRuns)
+	assert.Equal(t, float64(0), stats.SuccessRate)
+	assert.Equal(t, 0, stats.P95DurationMs)
+	assert.Nil(t, stats.LastSuccessTime)
+	assert.Empty(t, stats.LastRuns)
+}
+
+func TestCoveredSince(t *testing.T) {
+	since := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	logs := []domainTaskExecutionLog.TaskExecutionLog{
+		executionLog(3, "c", 1, domainTaskExecutionLog.ExecuteResultSuccess, 10, since.Add(3*time.Hour)),
+		executionLog(2, "b", 1, domainTaskExecutionLog.ExecuteResultSuccess, 10, since.Add(time.Hour)),
+		executionLog(1, "a", 1, domainTaskExecutionLog.ExecuteResultSuccess, 10, since.Add(2*time.Hour)),
+	}
+
+	covered, truncated := coveredSince(logs, 10, since)
+	assert.False(t, truncated)
+	assert.Equal(t, since, covered)
+
+	// 达到读取上限时只统计到最早一条日志
+	covered, truncated = coveredSince(logs, 3, since)
+	assert.True(t, truncated)
+	assert.Equal(t, since.Add(time.Hour), covered)
+}
@@ -0,0 +1,37 @@
+package scheduled_task
+
+import (
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	scheduledTaskDomain "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/lib/scheduler"
+)
+
+// normalizeTimezone 校验 IANA 时区，为空时使用调度器默认时区
+func (s *ScheduledTaskUseCase) normalizeTimezone(timezone *string) error {
+	if *timezone == "" {
+		*timezone = s.scheduler.Location().String()
+		return nil
+	}
+	if _, err := scheduler.LoadTimezone(*timezone); err != nil {
+		return domainErrors.NewAppError(err, domainErrors.ValidationError)
+	}
+	return nil
+}
+
+// localizeTask 将上次/下次执行时间转换到任务时区，用于展示
+func (s *ScheduledTaskUseCase) localizeTask(task *scheduledTaskDomain.ScheduledTask) {
+	loc := s.scheduler.TaskLocation(task)
+	if task.Timezone == "" {
+		task.Timezone = loc.String()
+	}
+	if !task.LastExecuteTime.IsZero() {
+		task.LastExecuteTime = task.LastExecuteTime.In(loc)
+	}
+	if !task.NextExecuteTime.IsZero() {
+		task.NextExecuteTime = task.NextExecuteTime.In(loc)
+	}
+	if task.PausedUntil != nil {
+		pausedUntil := task.PausedUntil.In(loc)
+		task.PausedUntil = &pausedUntil
+	}
+}
@@ -0,0 +1,294 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gbrayhan/microservices-go/src/application/event/bus"
+	"github.com/gbrayhan/microservices-go/src/application/event/model"
+	domainWebhook "github.com/gbrayhan/microservices-go/src/domain/sys/webhook"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/lib/executor"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	webhookRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/webhook"
+	"github.com/gbrayhan/microservices-go/src/shared/utils"
+	"go.uber.org/zap"
+)
+
+// 投递请求头，签名和时间戳沿用 signed_webhook 任务的格式
+const (
+	HeaderWebhookID = "X-Webhook-ID"
+	HeaderEventType = "X-Event-Type"
+	HeaderEventID   = "X-Event-ID"
+)
+
+// 投递记录中保存的响应体上限
+const maxResponseBodyBytes = 2048
+
+// 载荷包含敏感信息（如重置密码链接）的事件不会投递给 webhook
+var undeliverableEventTypes = map[string]bool{
+	model.ForgetPasswordEventType: true,
+}
+
+// DispatcherConfig webhook 投递配置
+type DispatcherConfig struct {
+	MaxAttempts   int           // 每个 webhook 的最大投递次数，含首次
+	RetryInterval time.Duration // 首次重试间隔，之后翻倍
+	Timeout       time.Duration // 单次请求超时
+	// 允许投递到回环、链路本地和私有地址，只用于本地开发
+	AllowPrivateNetworks bool
+}
+
+// DefaultDispatcherConfig 从环境变量读取投递配置
+func DefaultDispatcherConfig() DispatcherConfig {
+	return DispatcherConfig{
+		MaxAttempts:   utils.GetEnvAsInt("WEBHOOK_MAX_ATTEMPTS", 3),
+		RetryInterval: time.Duration(utils.GetEnvAsInt("WEBHOOK_RETRY_INTERVAL_SECOND", 5)) * time.Second,
+		Timeout:       time.Duration(utils.GetEnvAsInt("WEBHOOK_TIMEOUT_SECOND", 10)) * time.Second,
+
+		AllowPrivateNetworks: utils.GetEnv("WEBHOOK_ALLOW_PRIVATE_NETWORKS", "false") == "true",
+	}
+}
+
+// Dispatcher 订阅事件总线，把事件签名后投递给匹配的 webhook。
+// 投递在独立的 goroutine 中进行，不占用事件总线的处理器，也不会因为对方失败触发总线重试
+type Dispatcher struct {
+	repo     webhookRepo.IWebhookRepository
+	eventBus bus.EventBus
+	client   *http.Client
+	logger   *logger.Logger
+	config   DispatcherConfig
+
+	mutex      sync.Mutex
+	subscribed map[string]bool
+
+	ctx      context.Context
+	cancel   context.CancelFunc
+	inFlight sync.WaitGroup
+}
+
+// NewDispatcher 创建 webhook 投递器
+func NewDispatcher(repo webhookRepo.IWebhookRepository, eventBus bus.EventBus, loggerInstance *logger.Logger, config DispatcherConfig) *Dispatcher {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 1
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Dispatcher{
+		repo:       repo,
+		eventBus:   eventBus,
+		client:     newWebhookClient(config.Timeout, config.AllowPrivateNetworks),
+		logger:     loggerInstance,
+		config:     config,
+		subscribed: make(map[string]bool),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// Start 订阅已登记的事件类型，以及现有 webhook 中配置的其他事件类型
+func (d *Dispatcher) Start() error {
+	d.EnsureSubscribed(model.DefaultEventRegistry.EventTypes())
+	webhooks, err := d.repo.GetAll()
+	if err != nil {
+		return err
+	}
+	for _, webhook := range *webhooks {
+		d.EnsureSubscribed(webhook.EventTypes)
+	}
+	return nil
+}
+
+// EnsureSubscribed 订阅尚未订阅的事件类型，新建或修改 webhook 后调用
+func (d *Dispatcher) EnsureSubscribed(eventTypes []string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for _, eventType := range eventTypes {
+		eventType = resolveEventType(eventType)
+		if eventType == domainWebhook.EventTypeWildcard || undeliverableEventTypes[eventType] || d.subscribed[eventType] {
+			continue
+		}
+		if err := d.eventBus.Subscribe(eventType, d); err != nil {
+			d.logger.Error("Failed to subscribe webhook dispatcher", zap.String("eventType", eventType), zap.Error(err))
+			continue
+		}
+		d.subscribed[eventType] = true
+	}
+}
+
+// Handle implements model.EventHandler.
+func (d *Dispatcher) Handle(event model.ApplicationEvent) error {
+	if d.ctx.Err() != nil || undeliverableEventTypes[event.EventType()] {
+		return nil
+	}
+	webhooks, err := d.repo.GetActive()
+	if err != nil {
+		return err
+	}
+	for _, webhook := range *webhooks {
+		if !MatchEventType(webhook.EventTypes, event.EventType()) {
+			continue
+		}
+		if !d.track() {
+			return nil
+		}
+		go func(webhook domainWebhook.Webhook) {
+			defer d.inFlight.Done()
+			d.deliverWithRetry(&webhook, event)
+		}(webhook)
+	}
+	return nil
+}
+
+// Stop 放弃等待中的重试，等待正在进行的请求结束
+func (d *Dispatcher) Stop() {
+	d.mutex.Lock()
+	d.cancel()
+	d.mutex.Unlock()
+	d.inFlight.Wait()
+}
+
+// track 登记一次投递，Stop 之后不再接受
+func (d *Dispatcher) track() bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.ctx.Err() != nil {
+		return false
+	}
+	d.inFlight.Add(1)
+	return true
+}
+
+func (d *Dispatcher) deliverWithRetry(webhook *domainWebhook.Webhook, event model.ApplicationEvent) {
+	delay := d.config.RetryInterval
+	for attempt := 1; attempt <= d.config.MaxAttempts; attempt++ {
+		// 请求本身不随 Stop 取消，由客户端超时限制
+		if d.Deliver(context.Background(), webhook, event, attempt).Success {
+			return
+		}
+		if attempt == d.config.MaxAttempts {
+			break
+		}
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+	d.logger.Warn("Webhook delivery failed after max attempts",
+		zap.Int("webhook_id", webhook.ID),
+		zap.String("eventType", event.EventType()),
+		zap.String("eventID", event.EventID()))
+}
+
+// Deliver 投递一次并写入投递记录，2xx 视为成功
+func (d *Dispatcher) Deliver(ctx context.Context, webhook *domainWebhook.Webhook, event model.ApplicationEvent, attempt int) *domainWebhook.WebhookDelivery {
+	delivery := &domainWebhook.WebhookDelivery{
+		WebhookID: webhook.ID,
+		EventID:   event.EventID(),
+		EventType: event.EventType(),
+		Attempt:   attempt,
+	}
+	start := time.Now()
+	statusCode, responseBody, err := d.send(ctx, webhook, event)
+	delivery.DurationMs = time.Since(start).Milliseconds()
+	delivery.StatusCode = statusCode
+	delivery.ResponseBody = responseBody
+	if err == nil && (statusCode < 200 || statusCode > 299) {
+		err = fmt.Errorf("unexpected status code %d", statusCode)
+	}
+	if err != nil {
+		delivery.Error = err.Error()
+		d.logger.Warn("Webhook delivery attempt failed",
+			zap.Int("webhook_id", webhook.ID),
+			zap.String("eventID", event.EventID()),
+			zap.Int("attempt", attempt),
+			zap.Error(err))
+	} else {
+		delivery.Success = true
+	}
+	if err := d.repo.CreateDelivery(delivery); err != nil {
+		d.logger.Error("Failed to save webhook delivery", zap.Int("webhook_id", webhook.ID), zap.Error(err))
+	}
+	return delivery
+}
+
+// send 请求体为事件信封，签名为 sha256=hex(HMAC-SHA256(secret, timestamp + "." + body))
+func (d *Dispatcher) send(ctx context.Context, webhook *domainWebhook.Webhook, event model.ApplicationEvent) (int, string, error) {
+	envelope, err := model.NewEnvelope(event, "")
+	if err != nil {
+		return 0, "", err
+	}
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		return 0, "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderWebhookID, strconv.Itoa(webhook.ID))
+	req.Header.Set(HeaderEventType, event.EventType())
+	req.Header.Set(HeaderEventID, event.EventID())
+	req.Header.Set(executor.DefaultTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(executor.DefaultSignatureHeader, executor.SignWebhookPayload(webhook.Secret, timestamp, body))
+	// 重试时保持不变，接收方据此去重
+	req.Header.Set(executor.DefaultIdempotencyHeader, fmt.Sprintf("webhook-%d-%s", webhook.ID, event.EventID()))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	responseBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBodyBytes))
+	return resp.StatusCode, string(responseBody), nil
+}
+
+// MatchEventType 判断 webhook 是否订阅了该事件，* 匹配全部；
+// 比较时忽略大小写和 . _ -，user.registered 与 UserRegistered 等价
+func MatchEventType(filters []string, eventType string) bool {
+	normalized := normalizeEventType(eventType)
+	for _, filter := range filters {
+		if filter == domainWebhook.EventTypeWildcard || normalizeEventType(filter) == normalized {
+			return true
+		}
+	}
+	return false
+}
+
+// DeliverableEventTypes 可以订阅的事件类型：已登记且载荷不含敏感信息的事件
+func DeliverableEventTypes() []string {
+	eventTypes := make([]string, 0)
+	for _, eventType := range model.DefaultEventRegistry.EventTypes() {
+		if !undeliverableEventTypes[eventType] {
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+	return eventTypes
+}
+
+// resolveEventType 把 user.registered 这类写法换成已登记的事件类型，未登记的原样返回
+func resolveEventType(filter string) string {
+	filter = strings.TrimSpace(filter)
+	normalized := normalizeEventType(filter)
+	for _, eventType := range model.DefaultEventRegistry.EventTypes() {
+		if normalizeEventType(eventType) == normalized {
+			return eventType
+		}
+	}
+	return filter
+}
+
+func normalizeEventType(eventType string) string {
+	return strings.ToLower(strings.NewReplacer(".", "", "_", "", "-", "").Replace(strings.TrimSpace(eventType)))
+}
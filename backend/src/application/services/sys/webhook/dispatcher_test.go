@@ -0,0 +1,227 @@
+package webhook
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gbrayhan/microservices-go/src/application/event/model"
+	domainWebhook "github.com/gbrayhan/microservices-go/src/domain/sys/webhook"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/lib/executor"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeWebhookRepository struct {
+	mutex      sync.Mutex
+	webhooks   []domainWebhook.Webhook
+	deliveries []domainWebhook.WebhookDelivery
+}
+
+func (r *fakeWebhookRepository) GetAll() (*[]domainWebhook.Webhook, error) {
+	return &r.webhooks, nil
+}
+
+func (r *fakeWebhookRepository) GetActive() (*[]domainWebhook.Webhook, error) {
+	active := make([]domainWebhook.Webhook, 0)
+	for _, webhook := range r.webhooks {
+		if webhook.Active {
+			active = append(active, webhook)
+		}
+	}
+	return &active, nil
+}
+
+func (r *fakeWebhookRepository) GetByID(id int) (*domainWebhook.Webhook, error) {
+	for _, webhook := range r.webhooks {
+		if webhook.ID == id {
+			return &webhook, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeWebhookRepository) Create(webhook *domainWebhook.Webhook) (*domainWebhook.Webhook, error) {
+	return webhook, nil
+}
+
+func (r *fakeWebhookRepository) Update(id int, webhookMap map[string]interface{}) (*domainWebhook.Webhook, error) {
+	return r.GetByID(id)
+}
+
+func (r *fakeWebhookRepository) Delete(id int) error {
+	return nil
+}
+
+func (r *fakeWebhookRepository) CreateDelivery(delivery *domainWebhook.WebhookDelivery) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.deliveries = append(r.deliveries, *delivery)
+	return nil
+}
+
+func (r *fakeWebhookRepository) GetDeliveries(webhookID int, limit int) (*[]domainWebhook.WebhookDelivery, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	deliveries := append([]domainWebhook.WebhookDelivery(nil), r.deliveries...)
+	return &deliveries, nil
+}
+
+func newTestDispatcher(t *testing.T, repo *fakeWebhookRepository) *Dispatcher {
+	return newTestDispatcherWithConfig(t, repo, DispatcherConfig{
+		MaxAttempts:   3,
+		RetryInterval: time.Millisecond,
+		Timeout:       time.Second,
+		// httptest 监听 127.0.0.1
+		AllowPrivateNetworks: true,
+	})
+}
+
+func newTestDispatcherWithConfig(t *testing.T, repo *fakeWebhookRepository, config DispatcherConfig) *Dispatcher {
+	loggerInstance, err := logger.NewLogger()
+	require.NoError(t, err)
+	return NewDispatcher(repo, nil, loggerInstance, config)
+}
+
+func TestDeliverSignsEnvelope(t *testing.T) {
+	var header http.Header
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	repo := &fakeWebhookRepository{}
+	dispatcher := newTestDispatcher(t, repo)
+	webhook := &domainWebhook.Webhook{ID: 7, URL: server.URL, Secret: "s3cret", EventTypes: []string{"*"}, Active: true}
+	event := &model.GenericEvent{ID: "evt-1", Type: "UserRegistered", Data: map[string]interface{}{"user_id": 1}, OccurredAt: time.Now()}
+
+	delivery := dispatcher.Deliver(t.Context(), webhook, event, 1)
+	require.True(t, delivery.Success, delivery.Error)
+	assert.Equal(t, http.StatusNoContent, delivery.StatusCode)
+	require.Len(t, repo.deliveries, 1)
+
+	timestamp, err := strconv.ParseInt(header.Get(executor.DefaultTimestampHeader), 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, executor.SignWebhookPayload("s3cret", timestamp, body), header.Get(executor.DefaultSignatureHeader))
+	assert.Equal(t, "7", header.Get(HeaderWebhookID))
+	assert.Equal(t, "UserRegistered", header.Get(HeaderEventType))
+	assert.Equal(t, "webhook-7-evt-1", header.Get(executor.DefaultIdempotencyHeader))
+
+	decoded, _, err := model.DefaultEventRegistry.DecodeJSON(body)
+	require.NoError(t, err)
+	assert.Equal(t, "evt-1", decoded.EventID())
+}
+
+func TestHandleRetriesUntilSuccess(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	repo := &fakeWebhookRepository{webhooks: []domainWebhook.Webhook{
+		{ID: 1, URL: server.URL, Secret: "a", EventTypes: []string{"user.registered"}, Active: true},
+		{ID: 2, URL: server.URL, Secret: "b", EventTypes: []string{"task.enabled"}, Active: true},
+		{ID: 3, URL: server.URL, Secret: "c", EventTypes: []string{"*"}, Active: false},
+	}}
+	dispatcher := newTestDispatcher(t, repo)
+
+	require.NoError(t, dispatcher.Handle(&model.GenericEvent{ID: "evt-2", Type: "UserRegistered", OccurredAt: time.Now()}))
+	require.Eventually(t, func() bool {
+		deliveries, _ := repo.GetDeliveries(1, 10)
+		return len(*deliveries) == 2
+	}, time.Second, 5*time.Millisecond)
+	dispatcher.Stop()
+
+	require.Len(t, repo.deliveries, 2)
+	assert.Equal(t, 1, repo.deliveries[0].WebhookID)
+	assert.False(t, repo.deliveries[0].Success)
+	assert.Equal(t, http.StatusBadGateway, repo.deliveries[0].StatusCode)
+	assert.True(t, repo.deliveries[1].Success)
+	assert.Equal(t, 2, repo.deliveries[1].Attempt)
+}
+
+func TestMatchEventType(t *testing.T) {
+	assert.True(t, MatchEventType([]string{"user.registered"}, "UserRegistered"))
+	assert.True(t, MatchEventType([]string{"task_enabled", "user.registered"}, "TaskEnabled"))
+	assert.True(t, MatchEventType([]string{"*"}, "ConfigUpdated"))
+	assert.False(t, MatchEventType([]string{"user.registered"}, "UserCreated"))
+	assert.False(t, MatchEventType(nil, "UserRegistered"))
+}
+
+func TestDeliverBlocksPrivateNetworks(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer server.Close()
+
+	repo := &fakeWebhookRepository{}
+	dispatcher := newTestDispatcherWithConfig(t, repo, DispatcherConfig{MaxAttempts: 1, Timeout: time.Second})
+	webhook := &domainWebhook.Webhook{ID: 1, URL: server.URL, Secret: "s", EventTypes: []string{"*"}, Active: true}
+	event := &model.GenericEvent{ID: "evt-3", Type: "UserRegistered", OccurredAt: time.Now()}
+
+	delivery := dispatcher.Deliver(t.Context(), webhook, event, 1)
+	assert.False(t, delivery.Success)
+	assert.Contains(t, delivery.Error, "not allowed")
+	assert.Zero(t, calls.Load(), "loopback target is never reached")
+}
+
+func TestDeliverDoesNotFollowRedirects(t *testing.T) {
+	var redirected atomic.Int32
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirected.Add(1)
+	}))
+	defer target.Close()
+	server := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusFound))
+	defer server.Close()
+
+	repo := &fakeWebhookRepository{}
+	dispatcher := newTestDispatcher(t, repo)
+	webhook := &domainWebhook.Webhook{ID: 1, URL: server.URL, Secret: "s", EventTypes: []string{"*"}, Active: true}
+	event := &model.GenericEvent{ID: "evt-4", Type: "UserRegistered", OccurredAt: time.Now()}
+
+	delivery := dispatcher.Deliver(t.Context(), webhook, event, 1)
+	assert.False(t, delivery.Success)
+	assert.Equal(t, http.StatusFound, delivery.StatusCode)
+	assert.Zero(t, redirected.Load())
+}
+
+func TestValidateWebhook(t *testing.T) {
+	s := &WebhookUseCase{dispatcher: newTestDispatcherWithConfig(t, &fakeWebhookRepository{}, DispatcherConfig{})}
+	newWebhook := func(url string, eventTypes ...string) *domainWebhook.Webhook {
+		return &domainWebhook.Webhook{Name: "hook", URL: url, EventTypes: cleanEventTypes(eventTypes)}
+	}
+
+	valid := newWebhook("https://example.com/hook", " user.registered ", "UserRegistered", "task.failed", "*")
+	require.NoError(t, s.validateWebhook(valid))
+	assert.Equal(t, []string{"UserRegistered", "TaskFailed", "*"}, valid.EventTypes)
+
+	for _, url := range []string{
+		"http://localhost:8080/hook",
+		"http://127.0.0.1/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://10.0.0.8/hook",
+		"http://[::1]/hook",
+	} {
+		assert.Error(t, s.validateWebhook(newWebhook(url, "*")), url)
+	}
+
+	err := s.validateWebhook(newWebhook("https://example.com/hook", "task.finished"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "UserRegistered")
+	assert.Error(t, s.validateWebhook(newWebhook("https://example.com/hook", "ForgetPassword")))
+}
@@ -0,0 +1,276 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gbrayhan/microservices-go/src/application/event/model"
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainWebhook "github.com/gbrayhan/microservices-go/src/domain/sys/webhook"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	webhookRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/webhook"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultDeliveryLimit = 50
+	maxDeliveryLimit     = 500
+)
+
+// 允许通过 Update 修改的字段
+var updatableFields = map[string]bool{
+	"name":        true,
+	"url":         true,
+	"secret":      true,
+	"event_types": true,
+	"active":      true,
+	"description": true,
+}
+
+type IWebhookService interface {
+	GetAll() (*[]domainWebhook.Webhook, error)
+	GetByID(id int) (*domainWebhook.Webhook, error)
+	Create(newWebhook *domainWebhook.Webhook) (*domainWebhook.Webhook, error)
+	Update(id int, webhookMap map[string]interface{}) (*domainWebhook.Webhook, error)
+	Delete(id int) error
+	GetDeliveries(webhookID int, limit int) (*[]domainWebhook.WebhookDelivery, error)
+	SendTestEvent(id int) (*domainWebhook.WebhookDelivery, error)
+}
+
+type WebhookUseCase struct {
+	webhookRepository webhookRepo.IWebhookRepository
+	dispatcher        *Dispatcher
+	Logger            *logger.Logger
+}
+
+func NewWebhookUseCase(
+	webhookRepository webhookRepo.IWebhookRepository,
+	dispatcher *Dispatcher,
+	loggerInstance *logger.Logger,
+) IWebhookService {
+	return &WebhookUseCase{
+		webhookRepository: webhookRepository,
+		dispatcher:        dispatcher,
+		Logger:            loggerInstance,
+	}
+}
+
+func (s *WebhookUseCase) GetAll() (*[]domainWebhook.Webhook, error) {
+	s.Logger.Info("Getting all webhooks")
+	return s.webhookRepository.GetAll()
+}
+
+func (s *WebhookUseCase) GetByID(id int) (*domainWebhook.Webhook, error) {
+	s.Logger.Info("Getting webhook by ID", zap.Int("id", id))
+	return s.webhookRepository.GetByID(id)
+}
+
+// Create 未指定 secret 时自动生成，只在创建响应中返回一次
+func (s *WebhookUseCase) Create(newWebhook *domainWebhook.Webhook) (*domainWebhook.Webhook, error) {
+	s.Logger.Info("Creating new webhook", zap.String("name", newWebhook.Name), zap.String("url", newWebhook.URL))
+	newWebhook.EventTypes = cleanEventTypes(newWebhook.EventTypes)
+	if err := s.validateWebhook(newWebhook); err != nil {
+		return nil, err
+	}
+	if newWebhook.Secret == "" {
+		secret, err := generateSecret()
+		if err != nil {
+			s.Logger.Error("Error generating webhook secret", zap.Error(err))
+			return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+		}
+		newWebhook.Secret = secret
+	}
+	created, err := s.webhookRepository.Create(newWebhook)
+	if err != nil {
+		return nil, err
+	}
+	s.dispatcher.EnsureSubscribed(created.EventTypes)
+	created.Secret = newWebhook.Secret
+	return created, nil
+}
+
+// Update 只接受 updatableFields 中的字段，合并后整体校验
+func (s *WebhookUseCase) Update(id int, webhookMap map[string]interface{}) (*domainWebhook.Webhook, error) {
+	s.Logger.Info("Updating webhook", zap.Int("id", id))
+	current, err := s.webhookRepository.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+	for key, value := range webhookMap {
+		if !updatableFields[key] {
+			continue
+		}
+		switch key {
+		case "event_types":
+			eventTypes, err := toStringSlice(value)
+			if err != nil {
+				return nil, domainErrors.NewAppError(err, domainErrors.ValidationError)
+			}
+			current.EventTypes = cleanEventTypes(eventTypes)
+			updates[key] = current.EventTypes
+		case "active":
+			active, ok := value.(bool)
+			if !ok {
+				return nil, domainErrors.NewAppError(fmt.Errorf("active must be a boolean"), domainErrors.ValidationError)
+			}
+			current.Active = active
+			updates[key] = active
+		default:
+			text, ok := value.(string)
+			if !ok {
+				return nil, domainErrors.NewAppError(fmt.Errorf("%s must be a string", key), domainErrors.ValidationError)
+			}
+			text = strings.TrimSpace(text)
+			switch key {
+			case "name":
+				current.Name = text
+			case "url":
+				current.URL = text
+			case "secret":
+				if text == "" {
+					return nil, domainErrors.NewAppError(fmt.Errorf("secret must not be empty"), domainErrors.ValidationError)
+				}
+			}
+			updates[key] = text
+		}
+	}
+	if len(updates) == 0 {
+		return current, nil
+	}
+	if err := s.validateWebhook(current); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.webhookRepository.Update(id, updates)
+	if err != nil {
+		return nil, err
+	}
+	s.dispatcher.EnsureSubscribed(updated.EventTypes)
+	return updated, nil
+}
+
+func (s *WebhookUseCase) Delete(id int) error {
+	s.Logger.Info("Deleting webhook", zap.Int("id", id))
+	return s.webhookRepository.Delete(id)
+}
+
+// GetDeliveries 默认返回最近 50 条
+func (s *WebhookUseCase) GetDeliveries(webhookID int, limit int) (*[]domainWebhook.WebhookDelivery, error) {
+	if limit <= 0 {
+		limit = defaultDeliveryLimit
+	}
+	if limit > maxDeliveryLimit {
+		return nil, domainErrors.NewAppError(fmt.Errorf("limit must be between 1 and %d", maxDeliveryLimit), domainErrors.ValidationError)
+	}
+	if _, err := s.webhookRepository.GetByID(webhookID); err != nil {
+		return nil, err
+	}
+	return s.webhookRepository.GetDeliveries(webhookID, limit)
+}
+
+// SendTestEvent 同步投递一次测试事件，不重试，也不要求 webhook 已启用或订阅了该类型
+func (s *WebhookUseCase) SendTestEvent(id int) (*domainWebhook.WebhookDelivery, error) {
+	s.Logger.Info("Sending webhook test event", zap.Int("id", id))
+	webhook, err := s.webhookRepository.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	event := &model.GenericEvent{
+		ID:   uuid.New().String(),
+		Type: domainWebhook.TestEventType,
+		Data: map[string]interface{}{
+			"webhook_id": webhook.ID,
+			"message":    "This is a test event",
+		},
+		OccurredAt: time.Now(),
+	}
+	return s.dispatcher.Deliver(context.Background(), webhook, event, 1), nil
+}
+
+func (s *WebhookUseCase) validateWebhook(webhook *domainWebhook.Webhook) error {
+	if strings.TrimSpace(webhook.Name) == "" {
+		return domainErrors.NewAppError(fmt.Errorf("name is required"), domainErrors.ValidationError)
+	}
+	parsed, err := url.Parse(webhook.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return domainErrors.NewAppError(fmt.Errorf("invalid webhook url %q", webhook.URL), domainErrors.ValidationError)
+	}
+	if !s.dispatcher.config.AllowPrivateNetworks {
+		if err := checkWebhookHost(parsed.Hostname()); err != nil {
+			return domainErrors.NewAppError(err, domainErrors.ValidationError)
+		}
+	}
+	if len(webhook.EventTypes) == 0 {
+		return domainErrors.NewAppError(fmt.Errorf("event_types must not be empty"), domainErrors.ValidationError)
+	}
+	deliverable := DeliverableEventTypes()
+	for _, eventType := range webhook.EventTypes {
+		if eventType == domainWebhook.EventTypeWildcard {
+			continue
+		}
+		if !containsString(deliverable, eventType) {
+			return domainErrors.NewAppError(fmt.Errorf("unknown event type %q, supported event types: %s, or %s for all events",
+				eventType, strings.Join(deliverable, ", "), domainWebhook.EventTypeWildcard), domainErrors.ValidationError)
+		}
+	}
+	return nil
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanEventTypes 去掉空白和重复项，并把 user.registered 这类写法换成已登记的事件类型
+func cleanEventTypes(eventTypes []string) []string {
+	cleaned := make([]string, 0, len(eventTypes))
+	seen := make(map[string]bool)
+	for _, eventType := range eventTypes {
+		eventType = resolveEventType(eventType)
+		if eventType == "" || seen[eventType] {
+			continue
+		}
+		seen[eventType] = true
+		cleaned = append(cleaned, eventType)
+	}
+	return cleaned
+}
+
+func toStringSlice(value interface{}) ([]string, error) {
+	switch values := value.(type) {
+	case []string:
+		return values, nil
+	case []interface{}:
+		result := make([]string, 0, len(values))
+		for _, item := range values {
+			text, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("event_types must be an array of strings")
+			}
+			result = append(result, text)
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("event_types must be an array of strings")
+	}
+}
+
+func generateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
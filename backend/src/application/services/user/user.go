@@ -1,10 +1,14 @@
 package user
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"os"
+	"sort"
 
 	"github.com/gbrayhan/microservices-go/src/application/event/bus"
+	"github.com/gbrayhan/microservices-go/src/application/event/model"
 	"github.com/gbrayhan/microservices-go/src/domain"
 	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
 	jwtBlacklistDomain "github.com/gbrayhan/microservices-go/src/domain/jwt_blacklist"
@@ -82,18 +86,32 @@ func (s *UserUseCase) Create(newUser *userDomain.User) (*userDomain.User, error)
 	newUser.HashPassword = string(hash)
 	newUser.UUID = uuid.New().String()
 	newUser.Status = 1
-	return s.userRepository.Create(newUser)
+	created, err := s.userRepository.Create(newUser)
+	if err != nil {
+		return created, err
+	}
+	s.publish(&model.UserCreatedEvent{
+		DomainEventBase: model.NewDomainEventBase(),
+		UserID:          created.ID,
+		Username:        created.UserName,
+		Email:           created.Email,
+	})
+	return created, nil
 }
 
 func (s *UserUseCase) Delete(id int) error {
 	s.Logger.Info("Deleting user", zap.Int("id", id))
-	return s.userRepository.Delete(id)
+	if err := s.userRepository.Delete(id); err != nil {
+		return err
+	}
+	s.publish(&model.UserDeletedEvent{DomainEventBase: model.NewDomainEventBase(), UserID: int64(id)})
+	return nil
 }
 
 func (s *UserUseCase) Update(id int64, userMap map[string]interface{}) (*userDomain.User, error) {
 	s.Logger.Info("Updating user", zap.Int64("id", id))
 
-	return s.userRepository.Update(id, userMap)
+	return s.update(id, userMap)
 }
 
 func (s *UserUseCase) SearchPaginated(filters domain.DataFilters) (*userDomain.SearchResultUser, error) {
@@ -114,7 +132,17 @@ func (s *UserUseCase) GetOneByMap(userMap map[string]interface{}) (*userDomain.U
 	return s.userRepository.GetOneByMap(userMap)
 }
 func (s *UserUseCase) UserBindRoles(userId int64, updateMap map[string]interface{}) error {
-	return s.userRoleRepository.Insert(userId, updateMap)
+	if err := s.userRoleRepository.Insert(userId, updateMap); err != nil {
+		return err
+	}
+	roleIDs := make([]string, 0)
+	if items, ok := updateMap["roleIds"].([]interface{}); ok {
+		for _, item := range items {
+			roleIDs = append(roleIDs, fmt.Sprint(item))
+		}
+	}
+	s.publish(&model.UserRolesBoundEvent{DomainEventBase: model.NewDomainEventBase(), UserID: userId, RoleIDs: roleIDs})
+	return nil
 }
 
 func (s *UserUseCase) ResetPassword(userId int64) (*userDomain.User, error) {
@@ -126,7 +154,7 @@ func (s *UserUseCase) ResetPassword(userId int64) (*userDomain.User, error) {
 		return nil, err
 	}
 	updateMap["hash_password"] = hash
-	return s.userRepository.Update(userId, updateMap)
+	return s.update(userId, updateMap)
 }
 
 func (s *UserUseCase) EditPassword(userId int64, data userDomain.PasswordEditRequest) (*userDomain.User, error) {
@@ -148,7 +176,7 @@ func (s *UserUseCase) EditPassword(userId int64, data userDomain.PasswordEditReq
 	}
 	updateMap := make(map[string]interface{})
 	updateMap["hash_password"] = hash
-	return s.userRepository.Update(userId, updateMap)
+	return s.update(userId, updateMap)
 }
 
 // ChangePasswordById implements IUserUseCase.
@@ -166,7 +194,7 @@ func (s *UserUseCase) ChangePasswordById(userId int64, password string, jwtToken
 		return nil, err
 	}
 	updateMap["hash_password"] = hash
-	res, err := s.userRepository.Update(userInfo.ID, updateMap)
+	res, err := s.update(userInfo.ID, updateMap)
 	if err != nil {
 		s.Logger.Error("Error updating user info", zap.Error(err))
 		return nil, err
@@ -175,3 +203,22 @@ func (s *UserUseCase) ChangePasswordById(userId int64, password string, jwtToken
 	s.jwtBlacklistRepository.AddToBlacklist(jwtToken)
 	return res, nil
 }
+
+// update 更新用户并发布 UserUpdated 事件，事件中只包含字段名
+func (s *UserUseCase) update(id int64, userMap map[string]interface{}) (*userDomain.User, error) {
+	res, err := s.userRepository.Update(id, userMap)
+	if err != nil {
+		return res, err
+	}
+	fields := make([]string, 0, len(userMap))
+	for field := range userMap {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	s.publish(&model.UserUpdatedEvent{DomainEventBase: model.NewDomainEventBase(), UserID: id, Fields: fields})
+	return res, nil
+}
+
+func (s *UserUseCase) publish(event model.ApplicationEvent) {
+	bus.PublishBestEffort(context.Background(), s.eventBus, s.Logger, event)
+}
@@ -0,0 +1,34 @@
+package event_bus
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 进入死信队列的原因
+const (
+	DeadLetterReasonMaxAttempts = "max_attempts_exceeded" // 处理失败次数达到上限
+	DeadLetterReasonMalformed   = "malformed_message"     // 消息无法解析
+)
+
+// DeadLetter 死信队列中的一条消息
+type DeadLetter struct {
+	MessageID      string          `json:"message_id"`
+	EventType      string          `json:"event_type"`
+	RetryCount     int             `json:"retry_count"`
+	Reason         string          `json:"reason"`
+	LastError      string          `json:"last_error"`
+	DeadLetteredAt *time.Time      `json:"dead_lettered_at"`
+	Payload        json.RawMessage `json:"payload"`
+}
+
+// ReplayResult 重放结果
+type ReplayResult struct {
+	Replayed int `json:"replayed"`
+}
+
+type IEventBusService interface {
+	ListDeadLetters(limit int) (*[]DeadLetter, error)
+	// ReplayDeadLetters 按消息ID重放，messageIDs 为空时重放最早的 limit 条
+	ReplayDeadLetters(messageIDs []string, limit int) (*ReplayResult, error)
+}
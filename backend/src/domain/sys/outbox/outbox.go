@@ -0,0 +1,31 @@
+package outbox
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// 发件箱状态
+const (
+	StatusPending = "pending" // 等待投递
+	StatusDone    = "done"    // 已投递到事件总线
+	StatusFailed  = "failed"  // 超过最大重试次数，不再投递
+)
+
+// OutboxEvent 与业务数据在同一事务中写入的待发布事件，由 relay 投递到事件总线
+type OutboxEvent struct {
+	ID            int64          `json:"id"`
+	EventID       string         `json:"event_id"`
+	EventType     string         `json:"event_type"`
+	EventVersion  int            `json:"event_version"`
+	CorrelationID string         `json:"correlation_id"`
+	Payload       datatypes.JSON `json:"payload"`
+	OccurredAt    time.Time      `json:"occurred_at"`
+	Status        string         `json:"status"`
+	Attempts      int            `json:"attempts"`
+	NextAttemptAt time.Time      `json:"next_attempt_at"`
+	LastError     string         `json:"last_error"`
+	PublishedAt   *time.Time     `json:"published_at"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
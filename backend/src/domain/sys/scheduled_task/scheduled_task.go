@@ -1,12 +1,38 @@
 package scheduled_task
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/gbrayhan/microservices-go/src/domain"
 	"gorm.io/datatypes"
 )
 
+const (
+	RetryBackoffFixed       = "fixed"
+	RetryBackoffExponential = "exponential"
+)
+
+// 错过触发的处理策略：跳过，或启动时补执行一次
+const (
+	MisfirePolicySkip     = "skip"
+	MisfirePolicyFireOnce = "fire_once"
+)
+
+// 并发策略：上一次执行未结束时，允许并行、跳过本次或取消上一次
+const (
+	ConcurrencyAllow   = "allow"
+	ConcurrencyForbid  = "forbid"
+	ConcurrencyReplace = "replace"
+)
+
+// 依赖触发条件：上游执行成功、失败或结束后触发下游
+const (
+	TriggerOnSuccess = "success"
+	TriggerOnFailure = "failure"
+	TriggerAlways    = "always"
+)
+
 type ScheduledTask struct {
 	ID              int            `json:"id"`
 	TaskName        string         `json:"task_name"`
@@ -16,10 +42,86 @@ type ScheduledTask struct {
 	TaskParams      datatypes.JSON `json:"task_params"`
 	Status          int            `json:"status"`
 	ExecType        string         `json:"exec_type"`
+	// 重试策略
+	RetryMaxAttempts     int    `json:"retry_max_attempts"`
+	RetryBackoff         string `json:"retry_backoff"`
+	RetryIntervalSeconds int    `json:"retry_interval_seconds"`
+	RetryOnErrors        string `json:"retry_on_errors"`
+	TimeoutSeconds       int    `json:"timeout_seconds"`
+	MisfirePolicy        string `json:"misfire_policy"`
+	ConcurrencyPolicy    string `json:"concurrency_policy"`
+	Timezone             string `json:"timezone"` // IANA 时区，cron 表达式按该时区解析
+	// 告警规则，0 表示不启用
+	AlertConsecutiveFailures int    `json:"alert_consecutive_failures"`
+	AlertMaxDurationSeconds  int    `json:"alert_max_duration_seconds"`
+	AlertEmails              string `json:"alert_emails"` // 告警通知邮箱，逗号分隔
+	// 是否已暂停，与 status 分开保存：暂停的任务被手动执行时 status 为运行中，paused 仍为 true
+	Paused bool `json:"paused"`
+	// 暂停到期时间，为空表示暂停到手动恢复
+	PausedUntil *time.Time `json:"paused_until"`
+	// 周期性停止窗口（[]BlackoutWindow），窗口内的计划触发记为跳过
+	BlackoutWindows datatypes.JSON `json:"blackout_windows"`
 	LastExecuteTime time.Time      `json:"last_execute_time"`
 	NextExecuteTime time.Time      `json:"next_execute_time"`
 	CreatedAt       time.Time      `json:"created_at"`
 	UpdatedAt       time.Time      `json:"updated_at"`
+	// 上游依赖，不为 nil 时保存任务会整体替换
+	Dependencies []TaskDependency `json:"dependencies"`
+}
+
+// TaskDependency 任务依赖，上游任务执行结束且满足触发条件时启动下游任务
+type TaskDependency struct {
+	ID               int       `json:"id"`
+	TaskID           int       `json:"task_id"`
+	UpstreamTaskID   int       `json:"upstream_task_id"`
+	TriggerCondition string    `json:"trigger_condition"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// TaskDAGNode DAG 视图中的任务节点
+type TaskDAGNode struct {
+	ID             int    `json:"id"`
+	TaskName       string `json:"task_name"`
+	TaskType       string `json:"task_type"`
+	CronExpression string `json:"cron_expression"`
+	Status         int    `json:"status"`
+}
+
+// TaskDAG 任务依赖图
+type TaskDAG struct {
+	Nodes []TaskDAGNode    `json:"nodes"`
+	Edges []TaskDependency `json:"edges"`
+}
+
+// TaskTypeInfo 已注册的任务类型，ParamsSchema 为 task_params 的 JSON Schema
+type TaskTypeInfo struct {
+	Type         string          `json:"type"`
+	Description  string          `json:"description"`
+	ParamsSchema json.RawMessage `json:"params_schema,omitempty"`
+}
+
+// TaskFunctionInfo 已注册的函数任务，ParamsSchema 为 task_params.params 的 JSON Schema
+type TaskFunctionInfo struct {
+	Name                  string          `json:"name"`
+	Description           string          `json:"description"`
+	ParamsSchema          json.RawMessage `json:"params_schema,omitempty"`
+	DefaultTimeoutSeconds int             `json:"default_timeout_seconds"`
+}
+
+// CronPreview cron 表达式的描述及接下来的触发时间
+type CronPreview struct {
+	CronExpression string      `json:"cron_expression"`
+	Description    string      `json:"description"`
+	Timezone       string      `json:"timezone"`
+	NextRuns       []time.Time `json:"next_runs"`
+}
+
+// BlackoutWindow 周期性停止窗口，按任务时区计算
+// Weekdays 为空表示每天（0 为周日）；Start/End 格式 HH:MM，都为空表示全天，Start 晚于 End 表示跨过午夜
+type BlackoutWindow struct {
+	Weekdays []int  `json:"weekdays"`
+	Start    string `json:"start"`
+	End      string `json:"end"`
 }
 
 type IScheduledTaskService interface {
@@ -33,4 +135,14 @@ type IScheduledTaskService interface {
 	EnableTask(id int) error
 	DisableTask(id int) error
 	ReloadTasks() error
+	CancelTask(id int) error
+	RunTask(id int, params datatypes.JSON) (string, error)
+	GetDAG() (*TaskDAG, error)
+	GetFunctions() []TaskFunctionInfo
+	GetTaskTypes() []TaskTypeInfo
+	PreviewCron(expression string, timezone string, count int) (*CronPreview, error)
+	GetStats(id int, days int, lastN int) (*TaskStats, error)
+	PauseTask(id int, until *time.Time) error
+	ResumeTask(id int) error
+	GetDashboard(hours int) (*TaskDashboard, error)
 }
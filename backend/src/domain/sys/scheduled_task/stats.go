@@ -0,0 +1,53 @@
+package scheduled_task
+
+import "time"
+
+// 告警规则
+const (
+	AlertRuleConsecutiveFailures = "consecutive_failures"
+	AlertRuleDurationExceeded    = "duration_exceeded"
+)
+
+// TaskRunOutcome 一次触发的最终结果，重试只计最后一次尝试
+type TaskRunOutcome struct {
+	ExecutionID   string    `json:"execution_id"`
+	ExecuteTime   time.Time `json:"execute_time"`
+	ExecuteResult int       `json:"execute_result"`
+	DurationMs    int       `json:"duration_ms"`
+	Attempts      int       `json:"attempts"`
+	ErrorMessage  string    `json:"error_message,omitempty"`
+}
+
+// TaskStats 按执行日志统计的任务执行情况
+type TaskStats struct {
+	TaskID              int              `json:"task_id"`
+	TaskName            string           `json:"task_name"`
+	Since               time.Time        `json:"since"`
+	Truncated           bool             `json:"truncated"` // 执行日志超过读取上限，只统计了 Since 之后的部分
+	TotalRuns           int              `json:"total_runs"`
+	SuccessCount        int              `json:"success_count"`
+	FailureCount        int              `json:"failure_count"`
+	SkippedCount        int              `json:"skipped_count"`
+	SuccessRate         float64          `json:"success_rate"` // 成功次数 / (成功 + 失败)，跳过的不计入
+	P50DurationMs       int              `json:"p50_duration_ms"`
+	P95DurationMs       int              `json:"p95_duration_ms"`
+	ConsecutiveFailures int              `json:"consecutive_failures"`
+	LastSuccessTime     *time.Time       `json:"last_success_time"`
+	LastFailureTime     *time.Time       `json:"last_failure_time"`
+	LastRuns            []TaskRunOutcome `json:"last_runs"`
+}
+
+// TaskDashboard 所有任务的执行概况
+type TaskDashboard struct {
+	Since         time.Time   `json:"since"`
+	Truncated     bool        `json:"truncated"` // 执行日志超过读取上限，只统计了 Since 之后的部分
+	TotalTasks    int         `json:"total_tasks"`
+	StatusCounts  map[int]int `json:"status_counts"` // 按任务状态统计任务数
+	TotalRuns     int         `json:"total_runs"`
+	SuccessCount  int         `json:"success_count"`
+	FailureCount  int         `json:"failure_count"`
+	SkippedCount  int         `json:"skipped_count"`
+	SuccessRate   float64     `json:"success_rate"`
+	P95DurationMs int         `json:"p95_duration_ms"`
+	FailingTasks  []TaskStats `json:"failing_tasks"` // 最近一次执行失败的任务，按连续失败次数倒序
+}
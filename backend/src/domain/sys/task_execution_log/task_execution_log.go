@@ -6,6 +6,13 @@ import (
 	"github.com/gbrayhan/microservices-go/src/domain"
 )
 
+// 执行结果
+const (
+	ExecuteResultFailed  = 0
+	ExecuteResultSuccess = 1
+	ExecuteResultSkipped = 2 // 因并发策略等原因未执行
+)
+
 type TaskExecutionLog struct {
 	ID              int       `json:"id"`
 	TaskID          uint      `json:"task_id"`
@@ -13,6 +20,11 @@ type TaskExecutionLog struct {
 	ExecuteResult   int       `json:"execute_result"`
 	ExecuteDuration *int      `json:"execute_duration"`
 	ErrorMessage    string    `json:"error_message"`
+	ExecuteOutput   string    `json:"execute_output"`
+	ExecuteNode     string    `json:"execute_node"`
+	ExecutionID     string    `json:"execution_id"`
+	Attempt         int       `json:"attempt"`
+	ParentLogID     *int      `json:"parent_log_id"`
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
 }
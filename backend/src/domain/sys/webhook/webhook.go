@@ -0,0 +1,49 @@
+package webhook
+
+import (
+	"time"
+)
+
+// TestEventType 管理端“发送测试事件”使用的事件类型，不经过事件总线
+const TestEventType = "WebhookTest"
+
+// EventTypeWildcard 订阅全部事件
+const EventTypeWildcard = "*"
+
+// Webhook 外部系统注册的 webhook，EventTypes 为订阅的事件类型
+type Webhook struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	URL         string    `json:"url"`
+	Secret      string    `json:"-"`
+	EventTypes  []string  `json:"event_types"`
+	Active      bool      `json:"active"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// WebhookDelivery 一次投递尝试的记录
+type WebhookDelivery struct {
+	ID           int64     `json:"id"`
+	WebhookID    int       `json:"webhook_id"`
+	EventID      string    `json:"event_id"`
+	EventType    string    `json:"event_type"`
+	Attempt      int       `json:"attempt"`
+	Success      bool      `json:"success"`
+	StatusCode   int       `json:"status_code"`
+	DurationMs   int64     `json:"duration_ms"`
+	Error        string    `json:"error"`
+	ResponseBody string    `json:"response_body"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type IWebhookService interface {
+	GetAll() (*[]Webhook, error)
+	GetByID(id int) (*Webhook, error)
+	Create(newWebhook *Webhook) (*Webhook, error)
+	Update(id int, webhookMap map[string]interface{}) (*Webhook, error)
+	Delete(id int) error
+	GetDeliveries(webhookID int, limit int) (*[]WebhookDelivery, error)
+	SendTestEvent(id int) (*WebhookDelivery, error)
+}
@@ -12,7 +12,7 @@ type ConfigModule struct {
 
 func setupConfigModule(appContext *ApplicationContext) error {
 	// Initialize use cases
-	configUC := configUseCase.NewSysConfigUseCase(appContext.Logger)
+	configUC := configUseCase.NewSysConfigUseCase(appContext.EventBus, appContext.Logger)
 	// Initialize controllers
 	configController := configController.NewConfigController(configUC, appContext.Logger)
 
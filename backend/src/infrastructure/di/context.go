@@ -3,11 +3,13 @@ package di
 
 import (
 	"fmt"
+	"io"
 	"sync"
 
 	"github.com/casbin/casbin/v2"
 	"github.com/gbrayhan/microservices-go/src/application/event/bus"
 	"github.com/gbrayhan/microservices-go/src/application/event/factory"
+	"github.com/gbrayhan/microservices-go/src/application/event/outbox"
 	taskConstants "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task/constants"
 	captchaLib "github.com/gbrayhan/microservices-go/src/infrastructure/lib/captcha"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/lib/executor"
@@ -26,12 +28,16 @@ import (
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/dictionary"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/dictionary_detail"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/files"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/operation_records"
+	outboxRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/outbox"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role_btn"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role_menu"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/scheduled_task"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/task_dependency"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/task_execution_log"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/user_role"
+	webhookRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/webhook"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/user"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/rest/middlewares"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/security"
@@ -66,6 +72,7 @@ type ApplicationContext struct {
 	Repositories       RepositoryContainer
 	TaskExecutor       *executor.TaskExecutorManager
 	TaskScheduler      *scheduler.TaskScheduler
+	OutboxRelay        *outbox.Relay
 	HttpExecutor       *executor.HTTPExecutor
 	FunctionExecutor   *executor.FunctionExecutor
 	MiddlewareProvider *middlewares.MiddlewareProvider
@@ -90,6 +97,8 @@ type ApplicationContext struct {
 	ConfigModule           ConfigModule
 	EmailModule            EmailModule
 	CaptchaModule          CaptchaModule
+	EventBusModule         EventBusModule
+	WebhookModule          WebhookModule
 }
 type RepositoryContainer struct {
 	RoleMenuRepository         role_menu.ISysRoleMenuRepository
@@ -109,6 +118,10 @@ type RepositoryContainer struct {
 	FileRepository             files.ISysFilesRepository
 	ScheduledTaskRepository    scheduled_task.IScheduledTaskRepository
 	TaskExecutionLogRepository task_execution_log.ITaskExecutionLogRepository
+	TaskDependencyRepository   task_dependency.ITaskDependencyRepository
+	OperationRepository        operation_records.OperationRepositoryInterface
+	OutboxRepository           outboxRepo.IOutboxRepository
+	WebhookRepository          webhookRepo.IWebhookRepository
 }
 
 // SetupDependencies creates a new application context with all dependencies
@@ -136,10 +149,19 @@ func SetupDependencies(loggerInstance *logger.Logger) (*ApplicationContext, erro
 		FileRepository:             files.NewSysFilesRepository(db, loggerInstance),
 		ScheduledTaskRepository:    scheduled_task.NewScheduledTaskRepository(db, loggerInstance),
 		TaskExecutionLogRepository: task_execution_log.NewTaskExecutionLogRepository(db, loggerInstance),
+		TaskDependencyRepository:   task_dependency.NewTaskDependencyRepository(db, loggerInstance),
+		OperationRepository:        operation_records.NewOperationRepository(db, loggerInstance),
+		OutboxRepository:           outboxRepo.NewOutboxRepository(db, loggerInstance),
+		WebhookRepository:          webhookRepo.NewWebhookRepository(db, loggerInstance),
 	}
 
 	// create event bus
-	eventBus := factory.CreateEventBus(loggerInstance)
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database connection: %w", err)
+	}
+	eventBus := factory.CreateEventBus(sqlDB, loggerInstance)
+	outboxRelay := outbox.NewRelay(repositories.OutboxRepository, eventBus, loggerInstance, outbox.DefaultRelayConfig())
 
 	// Initialize Redis client
 	redisClientInstance, err := redisLib.InitRedisClient(loggerInstance)
@@ -166,8 +188,12 @@ func SetupDependencies(loggerInstance *logger.Logger) (*ApplicationContext, erro
 	taskExecutor := executor.NewTaskExecutorManager(loggerInstance)
 	functionExecutor := executor.NewFunctionExecutor(loggerInstance)
 	httpCallExecutor := executor.NewHTTPExecutor(loggerInstance)
+	shellExecutor := executor.NewShellExecutor(loggerInstance, executor.DefaultShellExecutorConfig())
 	taskExecutor.RegisterExecutor(taskConstants.TaskTypeFunction, functionExecutor)
 	taskExecutor.RegisterExecutor(taskConstants.TaskTypeHttpCall, httpCallExecutor)
+	taskExecutor.RegisterExecutor(taskConstants.TaskTypeScriptExec, shellExecutor)
+	taskExecutor.RegisterExecutor(executor.TaskTypePublishEvent, executor.NewEventExecutor(eventBus, loggerInstance))
+	taskExecutor.RegisterExecutor(executor.TaskTypeSignedWebhook, executor.NewWebhookExecutor(loggerInstance))
 
 	// Initialize JWT service
 	jwtService := security.NewJWTService()
@@ -178,8 +204,10 @@ func SetupDependencies(loggerInstance *logger.Logger) (*ApplicationContext, erro
 	// Initialize CaptchaHandler
 	captchaHandler := captchaLib.New(captchaLib.DefaultConfig(loggerInstance))
 	// initialize task scheduler
+	taskLocker := scheduler.NewRedisTaskLocker(redisClientInstance)
 	taskScheduler := scheduler.NewTaskScheduler(
-		repositories.ScheduledTaskRepository, loggerInstance, taskExecutor, repositories.TaskExecutionLogRepository)
+		repositories.ScheduledTaskRepository, loggerInstance, taskExecutor, repositories.TaskExecutionLogRepository,
+		repositories.TaskDependencyRepository, taskLocker, taskLocker)
 
 	// create context
 	appContext := &ApplicationContext{
@@ -194,6 +222,7 @@ func SetupDependencies(loggerInstance *logger.Logger) (*ApplicationContext, erro
 		Repositories:  repositories,
 		TaskExecutor:  taskExecutor,
 		TaskScheduler: taskScheduler,
+		OutboxRelay:   outboxRelay,
 
 		FunctionExecutor:   functionExecutor,
 		HttpExecutor:       httpCallExecutor,
@@ -222,6 +251,8 @@ func SetupDependencies(loggerInstance *logger.Logger) (*ApplicationContext, erro
 		setupTaskExecutionLogModule,
 		setupEmailModule,
 		setupCaptchaModule,
+		setupEventBusModule,
+		setupWebhookModule,
 	}
 
 	for _, setupFunc := range moduleSetupFuncs {
@@ -237,6 +268,28 @@ func SetupDependencies(loggerInstance *logger.Logger) (*ApplicationContext, erro
 
 // close response
 func (appContext *ApplicationContext) Close() error {
+	// down scheduler first, running tasks still need db to write execution logs
+	if appContext.TaskScheduler != nil {
+		appContext.TaskScheduler.Stop()
+	}
+
+	// stop outbox relay before db closed, undelivered events stay pending
+	if appContext.OutboxRelay != nil {
+		appContext.OutboxRelay.Stop()
+	}
+
+	// close event bus, drains in-flight handlers which may still write db; watermill sql transport also needs db
+	if closer, ok := appContext.EventBus.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			appContext.Logger.Error("Error closing event bus", zap.Error(err))
+		}
+	}
+
+	// webhook deliveries run outside the bus handlers and write delivery logs
+	if appContext.WebhookModule.Dispatcher != nil {
+		appContext.WebhookModule.Dispatcher.Stop()
+	}
+
 	// close database connection
 	if appContext.DB != nil {
 		db, _ := appContext.DB.DB()
@@ -253,10 +306,5 @@ func (appContext *ApplicationContext) Close() error {
 			appContext.Logger.Info("Redis connection closed successfully")
 		}
 	}
-	// down scheduler
-	if appContext.TaskScheduler != nil {
-		appContext.TaskScheduler.Stop()
-	}
-
 	return nil
 }
@@ -3,6 +3,7 @@ package di
 import (
 	eventHandler "github.com/gbrayhan/microservices-go/src/application/event/handler"
 	eventModel "github.com/gbrayhan/microservices-go/src/application/event/model"
+	"github.com/gbrayhan/microservices-go/src/application/event/outbox"
 	emailUseCase "github.com/gbrayhan/microservices-go/src/application/services/email"
 	emailController "github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers/email"
 )
@@ -13,17 +14,19 @@ type EmailModule struct {
 }
 
 func setupEmailModule(appContext *ApplicationContext) error {
-	// Initialize event
-	appContext.EventBus.Subscribe(eventModel.ForgetPasswordEventType, eventHandler.NewEmailEventHandler())
-
-	// Initialize use cases
+	// Initialize use cases, 忘记密码事件写入发件箱，由 Relay 至少投递一次；事件不含重置令牌，发送邮件时再生成
 	service := emailUseCase.NewEmailUseCase(
 		appContext.Repositories.UserRepository,
 		appContext.JWTService,
-		appContext.EventBus,
+		outbox.NewEventBus(appContext.Repositories.OutboxRepository, appContext.EventBus),
 		appContext.RedisClient,
 		appContext.Logger)
 
+	// Initialize event
+	emailHandler := eventHandler.NewEmailEventHandler(service)
+	appContext.EventBus.Subscribe(eventModel.ForgetPasswordEventType, emailHandler)
+	appContext.EventBus.Subscribe(eventModel.TaskAlertEventType, emailHandler)
+
 	// Initialize controllers
 	controller := emailController.NewEmailController(service, appContext.Logger)
 
@@ -0,0 +1,24 @@
+package di
+
+import (
+	eventBusUseCase "github.com/gbrayhan/microservices-go/src/application/services/sys/event_bus"
+	eventBusController "github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers/event_bus"
+)
+
+type EventBusModule struct {
+	Controller eventBusController.IEventBusController
+	UseCase    eventBusUseCase.IEventBusService
+}
+
+func setupEventBusModule(appContext *ApplicationContext) error {
+	// Initialize use cases
+	eventBusUC := eventBusUseCase.NewEventBusUseCase(appContext.EventBus, appContext.Logger)
+	// Initialize controllers
+	controller := eventBusController.NewEventBusController(eventBusUC, appContext.Logger)
+
+	appContext.EventBusModule = EventBusModule{
+		Controller: controller,
+		UseCase:    eventBusUC,
+	}
+	return nil
+}
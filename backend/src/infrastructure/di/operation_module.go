@@ -3,7 +3,6 @@ package di
 import (
 	"github.com/gbrayhan/microservices-go/src/application/services/sys/operation_record"
 	operationUseCase "github.com/gbrayhan/microservices-go/src/application/services/sys/operation_record"
-	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/operation_records"
 	operationController "github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers/operation"
 )
 
@@ -14,8 +13,7 @@ type OperationModule struct {
 }
 
 func setupOperationModule(appContext *ApplicationContext) error {
-	// Initialize repositories
-	operationRepo := operation_records.NewOperationRepository(appContext.DB, appContext.Logger)
+	operationRepo := appContext.Repositories.OperationRepository
 
 	// Initialize use cases
 	operationUC := operationUseCase.NewSysOperationUseCase(operationRepo, appContext.Logger)
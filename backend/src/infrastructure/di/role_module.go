@@ -29,6 +29,7 @@ func setupRoleModule(appContext *ApplicationContext) error {
 		appContext.Repositories.CasBinRepository,
 		appContext.Repositories.MenuRepository,
 		appContext.Repositories.RoleBtnRepository,
+		appContext.EventBus,
 		appContext.Logger)
 
 	// Initialize controllers
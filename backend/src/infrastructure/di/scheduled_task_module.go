@@ -2,6 +2,7 @@ package di
 
 import (
 	scheduledTaskUseCase "github.com/gbrayhan/microservices-go/src/application/services/sys/scheduled_task"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/job"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/scheduled_task"
 	scheduledTaskController "github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers/scheduled_task"
 )
@@ -14,10 +15,30 @@ type ScheduledTaskModule struct {
 
 func setupScheduledTaskModule(appContext *ApplicationContext) error {
 
+	// register built-in maintenance jobs
+	job.NewMaintenanceJobs(
+		appContext.Repositories.OperationRepository,
+		appContext.Repositories.TaskExecutionLogRepository,
+		appContext.Repositories.JwtBlacklistRepository,
+		appContext.Repositories.FileRepository,
+		appContext.Repositories.OutboxRepository,
+		appContext.RedisClient,
+		appContext.Logger).Register(appContext.FunctionExecutor)
+
 	// Initialize use cases
 	service := scheduledTaskUseCase.NewScheduledTaskUseCase(
 		appContext.Repositories.ScheduledTaskRepository,
-		appContext.Logger, appContext.TaskScheduler)
+		appContext.Repositories.TaskDependencyRepository,
+		appContext.Repositories.TaskExecutionLogRepository,
+		appContext.Logger, appContext.TaskScheduler,
+		appContext.TaskExecutor, appContext.FunctionExecutor,
+		appContext.EventBus)
+
+	// 任务执行结束后检查告警规则
+	appContext.TaskScheduler.SetExecutionObserver(scheduledTaskUseCase.NewTaskAlertObserver(
+		appContext.Repositories.TaskExecutionLogRepository,
+		appContext.EventBus,
+		appContext.Logger))
 
 	// Initialize controllers
 	controller := scheduledTaskController.NewScheduledTaskController(
@@ -2,7 +2,6 @@ package di
 
 import (
 	userUseCase "github.com/gbrayhan/microservices-go/src/application/services/user"
-	"github.com/gbrayhan/microservices-go/src/infrastructure/job"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/user"
 	userController "github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers/user"
 )
@@ -15,9 +14,6 @@ type UserModule struct {
 
 func setupUserModule(appContext *ApplicationContext) error {
 
-	// register executor
-	appContext.FunctionExecutor.RegisterFunction(job.FUNCTION_TYPE_CLEAN_UP_OLD_DATA, job.CleanOldData)
-
 	// Initialize use cases
 	userUC := userUseCase.NewUserUseCase(
 		appContext.Repositories.UserRepository,
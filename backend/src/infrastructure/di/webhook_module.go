@@ -0,0 +1,33 @@
+package di
+
+import (
+	webhookUseCase "github.com/gbrayhan/microservices-go/src/application/services/sys/webhook"
+	webhookController "github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers/webhook"
+	"go.uber.org/zap"
+)
+
+type WebhookModule struct {
+	Controller webhookController.IWebhookController
+	UseCase    webhookUseCase.IWebhookService
+	Dispatcher *webhookUseCase.Dispatcher
+}
+
+func setupWebhookModule(appContext *ApplicationContext) error {
+	// subscribe dispatcher to event bus, a failed lookup of existing webhooks should not block startup
+	dispatcher := webhookUseCase.NewDispatcher(appContext.Repositories.WebhookRepository, appContext.EventBus,
+		appContext.Logger, webhookUseCase.DefaultDispatcherConfig())
+	if err := dispatcher.Start(); err != nil {
+		appContext.Logger.Error("Failed to subscribe event types of existing webhooks", zap.Error(err))
+	}
+	// Initialize use cases
+	webhookUC := webhookUseCase.NewWebhookUseCase(appContext.Repositories.WebhookRepository, dispatcher, appContext.Logger)
+	// Initialize controllers
+	controller := webhookController.NewWebhookController(webhookUC, appContext.Logger)
+
+	appContext.WebhookModule = WebhookModule{
+		Controller: controller,
+		UseCase:    webhookUC,
+		Dispatcher: dispatcher,
+	}
+	return nil
+}
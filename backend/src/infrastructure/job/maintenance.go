@@ -0,0 +1,371 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	domainScheduledTask "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/lib/executor"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/jwt_blacklist"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/files"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/operation_records"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/outbox"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/task_execution_log"
+	"github.com/gbrayhan/microservices-go/src/shared/utils"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// 内置维护任务
+const (
+	FUNCTION_TYPE_PURGE_OPERATION_RECORDS  = "purge_operation_records"
+	FUNCTION_TYPE_PURGE_TASK_EXECUTION_LOG = "purge_task_execution_logs"
+	FUNCTION_TYPE_PRUNE_JWT_BLACKLIST      = "prune_jwt_blacklist"
+	FUNCTION_TYPE_CLEAN_ORPHAN_FILES       = "clean_orphan_files"
+	FUNCTION_TYPE_VACUUM_REDIS_KEYS        = "vacuum_redis_keys"
+	FUNCTION_TYPE_PURGE_OUTBOX_EVENTS      = "purge_outbox_events"
+)
+
+// orphanFilesBatchSize 清理孤儿文件时每批读取的目录项数
+const orphanFilesBatchSize = 500
+
+// RetentionParams 按天数保留数据
+type RetentionParams struct {
+	Days int `json:"days"` // 保留最近 N 天，默认 30
+}
+
+// OrphanFilesParams 清理上传目录中没有 sys_files 记录的文件
+type OrphanFilesParams struct {
+	MinAgeHours int  `json:"min_age_hours"` // 只清理修改时间早于 N 小时的文件，避免误删正在上传的文件，默认 24
+	DryRun      bool `json:"dry_run"`       // 只统计不删除
+}
+
+// RedisVacuumParams 扫描 Redis key 触发过期 key 的惰性删除
+type RedisVacuumParams struct {
+	Match     string `json:"match"`      // key 匹配模式，默认 *
+	ScanCount int64  `json:"scan_count"` // 每次 SCAN 的 COUNT，默认 1000
+	MaxKeys   int    `json:"max_keys"`   // 单次最多扫描的 key 数，默认 100000
+}
+
+// MaintenanceResult 维护任务的执行结果
+type MaintenanceResult struct {
+	Deleted int64  `json:"deleted"`
+	Scanned int64  `json:"scanned,omitempty"`
+	Before  string `json:"before,omitempty"`
+	DryRun  bool   `json:"dry_run,omitempty"`
+}
+
+type MaintenanceJobs struct {
+	operationRepo    operation_records.OperationRepositoryInterface
+	executionLogRepo task_execution_log.ITaskExecutionLogRepository
+	jwtBlacklistRepo jwt_blacklist.JwtBlacklistRepository
+	filesRepo        files.ISysFilesRepository
+	outboxRepo       outbox.IOutboxRepository
+	redisClient      *redis.Client
+	logger           *logger.Logger
+}
+
+func NewMaintenanceJobs(
+	operationRepo operation_records.OperationRepositoryInterface,
+	executionLogRepo task_execution_log.ITaskExecutionLogRepository,
+	jwtBlacklistRepo jwt_blacklist.JwtBlacklistRepository,
+	filesRepo files.ISysFilesRepository,
+	outboxRepo outbox.IOutboxRepository,
+	redisClient *redis.Client,
+	loggerInstance *logger.Logger,
+) *MaintenanceJobs {
+	return &MaintenanceJobs{
+		operationRepo:    operationRepo,
+		executionLogRepo: executionLogRepo,
+		jwtBlacklistRepo: jwtBlacklistRepo,
+		filesRepo:        filesRepo,
+		outboxRepo:       outboxRepo,
+		redisClient:      redisClient,
+		logger:           loggerInstance,
+	}
+}
+
+// 各维护任务 params 的 JSON Schema
+const (
+	retentionParamsSchema = `{
+	"type": "object",
+	"additionalProperties": false,
+	"properties": {
+		"days": {"type": "integer", "minimum": 1, "description": "保留最近 N 天，默认 30"}
+	}
+}`
+	orphanFilesParamsSchema = `{
+	"type": "object",
+	"additionalProperties": false,
+	"properties": {
+		"min_age_hours": {"type": "integer", "minimum": 0, "description": "只清理修改时间早于 N 小时的文件，默认 24"},
+		"dry_run": {"type": "boolean", "description": "只统计不删除"}
+	}
+}`
+	redisVacuumParamsSchema = `{
+	"type": "object",
+	"additionalProperties": false,
+	"properties": {
+		"match": {"type": "string", "minLength": 1, "description": "key 匹配模式，默认 *"},
+		"scan_count": {"type": "integer", "minimum": 1, "description": "每次 SCAN 的 COUNT，默认 1000"},
+		"max_keys": {"type": "integer", "minimum": 1, "description": "单次最多扫描的 key 数，默认 100000"}
+	}
+}`
+)
+
+// Register 注册所有内置维护任务
+func (j *MaintenanceJobs) Register(functionExecutor *executor.FunctionExecutor) {
+	functionExecutor.RegisterFunctionWithMeta(FUNCTION_TYPE_PURGE_OPERATION_RECORDS, j.PurgeOperationRecords, executor.FunctionMeta{
+		Description:    "删除 N 天前的操作记录",
+		ParamsSchema:   json.RawMessage(retentionParamsSchema),
+		DefaultTimeout: 10 * time.Minute,
+	})
+	functionExecutor.RegisterFunctionWithMeta(FUNCTION_TYPE_PURGE_TASK_EXECUTION_LOG, j.PurgeTaskExecutionLogs, executor.FunctionMeta{
+		Description:    "删除 N 天前的任务执行日志",
+		ParamsSchema:   json.RawMessage(retentionParamsSchema),
+		DefaultTimeout: 10 * time.Minute,
+	})
+	functionExecutor.RegisterFunctionWithMeta(FUNCTION_TYPE_PRUNE_JWT_BLACKLIST, j.PruneJwtBlacklist, executor.FunctionMeta{
+		Description:    "删除已过期 token 的黑名单记录",
+		ParamsSchema:   json.RawMessage(`{"type": "object", "additionalProperties": false}`),
+		DefaultTimeout: 5 * time.Minute,
+	})
+	functionExecutor.RegisterFunctionWithMeta(FUNCTION_TYPE_CLEAN_ORPHAN_FILES, j.CleanOrphanFiles, executor.FunctionMeta{
+		Description:    "删除上传目录中没有 sys_files 记录的文件",
+		ParamsSchema:   json.RawMessage(orphanFilesParamsSchema),
+		DefaultTimeout: 10 * time.Minute,
+	})
+	functionExecutor.RegisterFunctionWithMeta(FUNCTION_TYPE_VACUUM_REDIS_KEYS, j.VacuumRedisKeys, executor.FunctionMeta{
+		Description:    "扫描 Redis key，回收已过期的 key",
+		ParamsSchema:   json.RawMessage(redisVacuumParamsSchema),
+		DefaultTimeout: 5 * time.Minute,
+	})
+	functionExecutor.RegisterFunctionWithMeta(FUNCTION_TYPE_PURGE_OUTBOX_EVENTS, j.PurgeOutboxEvents, executor.FunctionMeta{
+		Description:    "删除 N 天前已投递的发件箱事件",
+		ParamsSchema:   json.RawMessage(retentionParamsSchema),
+		DefaultTimeout: 10 * time.Minute,
+	})
+}
+
+func (j *MaintenanceJobs) PurgeOperationRecords(ctx context.Context, task *domainScheduledTask.ScheduledTask) (interface{}, error) {
+	params := RetentionParams{Days: 30}
+	if err := decodeFunctionParams(task, &params); err != nil {
+		return nil, err
+	}
+	before, err := params.before()
+	if err != nil {
+		return nil, err
+	}
+	deleted, err := j.operationRepo.PurgeBefore(before)
+	if err != nil {
+		return nil, err
+	}
+	return MaintenanceResult{Deleted: deleted, Before: before.Format(time.RFC3339)}, nil
+}
+
+func (j *MaintenanceJobs) PurgeTaskExecutionLogs(ctx context.Context, task *domainScheduledTask.ScheduledTask) (interface{}, error) {
+	params := RetentionParams{Days: 30}
+	if err := decodeFunctionParams(task, &params); err != nil {
+		return nil, err
+	}
+	before, err := params.before()
+	if err != nil {
+		return nil, err
+	}
+	deleted, err := j.executionLogRepo.PurgeBefore(before)
+	if err != nil {
+		return nil, err
+	}
+	return MaintenanceResult{Deleted: deleted, Before: before.Format(time.RFC3339)}, nil
+}
+
+// PurgeOutboxEvents 删除已投递的发件箱事件，事件载荷中的用户信息不长期留存
+func (j *MaintenanceJobs) PurgeOutboxEvents(ctx context.Context, task *domainScheduledTask.ScheduledTask) (interface{}, error) {
+	params := RetentionParams{Days: 30}
+	if err := decodeFunctionParams(task, &params); err != nil {
+		return nil, err
+	}
+	before, err := params.before()
+	if err != nil {
+		return nil, err
+	}
+	deleted, err := j.outboxRepo.PurgeDone(before)
+	if err != nil {
+		return nil, err
+	}
+	return MaintenanceResult{Deleted: deleted, Before: before.Format(time.RFC3339)}, nil
+}
+
+// PruneJwtBlacklist 删除已过期 token 的黑名单记录，token 过期后本身就无法通过校验
+func (j *MaintenanceJobs) PruneJwtBlacklist(ctx context.Context, task *domainScheduledTask.ScheduledTask) (interface{}, error) {
+	now := time.Now()
+	// 解析不出过期时间的 token，超过 refresh token 有效期后同样视为过期
+	refreshHours := utils.GetEnvAsInt("JWT_REFRESH_TIME_HOUR", 24)
+	deleted, err := j.jwtBlacklistRepo.PruneExpired(now, now.Add(-time.Duration(refreshHours)*time.Hour))
+	if err != nil {
+		return nil, err
+	}
+	j.logger.Info("Pruned expired jwt blacklist entries", zap.Int64("count", deleted))
+	return MaintenanceResult{Deleted: deleted}, nil
+}
+
+// CleanOrphanFiles 删除 NATIVE_STORAGE_UPLOAD_DIR 中没有 sys_files 记录的文件
+func (j *MaintenanceJobs) CleanOrphanFiles(ctx context.Context, task *domainScheduledTask.ScheduledTask) (interface{}, error) {
+	params := OrphanFilesParams{MinAgeHours: 24}
+	if err := decodeFunctionParams(task, &params); err != nil {
+		return nil, err
+	}
+	if params.MinAgeHours < 0 {
+		return nil, executor.NewPermanentError(fmt.Errorf("min_age_hours must not be negative"))
+	}
+
+	uploadDir := os.Getenv("NATIVE_STORAGE_UPLOAD_DIR")
+	if uploadDir == "" {
+		return nil, executor.NewPermanentError(fmt.Errorf("NATIVE_STORAGE_UPLOAD_DIR is not configured"))
+	}
+	dir, err := os.Open(uploadDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return MaintenanceResult{DryRun: params.DryRun}, nil
+		}
+		return nil, err
+	}
+	defer dir.Close()
+
+	cutoff := time.Now().Add(-time.Duration(params.MinAgeHours) * time.Hour)
+	result := MaintenanceResult{DryRun: params.DryRun}
+	// 分批读取目录并只查询这一批文件的 sys_files 记录
+	for {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		entries, readErr := dir.ReadDir(orphanFilesBatchSize)
+		if err := j.cleanOrphanBatch(uploadDir, entries, cutoff, params.DryRun, &result); err != nil {
+			return result, err
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return result, readErr
+		}
+	}
+	j.logger.Info("Cleaned orphan upload files",
+		zap.Int64("scanned", result.Scanned),
+		zap.Int64("deleted", result.Deleted),
+		zap.Bool("dry_run", params.DryRun))
+	return result, nil
+}
+
+// cleanOrphanBatch 删除一批目录项中没有 sys_files 记录且早于 cutoff 的文件
+func (j *MaintenanceJobs) cleanOrphanBatch(uploadDir string, entries []os.DirEntry, cutoff time.Time, dryRun bool, result *MaintenanceResult) error {
+	paths := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			paths = append(paths, filepath.Join(uploadDir, entry.Name()))
+		}
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+	result.Scanned += int64(len(paths))
+	known, err := j.filesRepo.ExistingPaths(paths)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if known[path] {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		if !dryRun {
+			if err := os.Remove(path); err != nil {
+				j.logger.Warn("Failed to remove orphan file", zap.String("path", path), zap.Error(err))
+				continue
+			}
+		}
+		result.Deleted++
+	}
+	return nil
+}
+
+// VacuumRedisKeys 遍历 key 并访问其 TTL，让已过期但尚未被回收的 key 立即删除
+func (j *MaintenanceJobs) VacuumRedisKeys(ctx context.Context, task *domainScheduledTask.ScheduledTask) (interface{}, error) {
+	params := RedisVacuumParams{Match: "*", ScanCount: 1000, MaxKeys: 100000}
+	if err := decodeFunctionParams(task, &params); err != nil {
+		return nil, err
+	}
+	if params.ScanCount <= 0 || params.MaxKeys <= 0 {
+		return nil, executor.NewPermanentError(fmt.Errorf("scan_count and max_keys must be positive"))
+	}
+
+	var result MaintenanceResult
+	var cursor uint64
+	for {
+		keys, next, err := j.redisClient.Scan(ctx, cursor, params.Match, params.ScanCount).Result()
+		if err != nil {
+			return result, err
+		}
+		if len(keys) > 0 {
+			pipe := j.redisClient.Pipeline()
+			ttls := make([]*redis.DurationCmd, len(keys))
+			for i, key := range keys {
+				ttls[i] = pipe.TTL(ctx, key)
+			}
+			if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+				return result, err
+			}
+			for _, ttl := range ttls {
+				// -2 表示 key 已不存在（过期后被回收）
+				if ttl.Val() == -2 {
+					result.Deleted++
+				}
+			}
+			result.Scanned += int64(len(keys))
+		}
+		cursor = next
+		if cursor == 0 || result.Scanned >= int64(params.MaxKeys) {
+			break
+		}
+	}
+	j.logger.Info("Vacuumed redis keys",
+		zap.String("match", params.Match),
+		zap.Int64("scanned", result.Scanned),
+		zap.Int64("expired", result.Deleted))
+	return result, nil
+}
+
+func (p RetentionParams) before() (time.Time, error) {
+	if p.Days <= 0 {
+		return time.Time{}, executor.NewPermanentError(fmt.Errorf("days must be positive, got %d", p.Days))
+	}
+	return time.Now().AddDate(0, 0, -p.Days), nil
+}
+
+// decodeFunctionParams 将 FunctionParams.Params 解析到具体的参数结构体，未配置的字段保留默认值
+func decodeFunctionParams(task *domainScheduledTask.ScheduledTask, out interface{}) error {
+	var params executor.FunctionParams
+	if err := json.Unmarshal(task.TaskParams, &params); err != nil {
+		return executor.NewPermanentError(fmt.Errorf("failed to parse function params: %w", err))
+	}
+	if len(params.Params) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(params.Params)
+	if err != nil {
+		return executor.NewPermanentError(err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return executor.NewPermanentError(fmt.Errorf("invalid params for %s: %w", params.FunctionName, err))
+	}
+	return nil
+}
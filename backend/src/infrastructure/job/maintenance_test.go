@@ -0,0 +1,29 @@
+package job
+
+import (
+	"testing"
+
+	domainScheduledTask "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/lib/executor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeFunctionParams(t *testing.T) {
+	params := RedisVacuumParams{Match: "*", ScanCount: 1000, MaxKeys: 100000}
+	err := decodeFunctionParams(&domainScheduledTask.ScheduledTask{
+		TaskParams: []byte(`{"function_name": "vacuum_redis_keys", "params": {"match": "captcha:*"}}`),
+	}, &params)
+	require.NoError(t, err)
+	assert.Equal(t, RedisVacuumParams{Match: "captcha:*", ScanCount: 1000, MaxKeys: 100000}, params)
+
+	retention := RetentionParams{Days: 30}
+	err = decodeFunctionParams(&domainScheduledTask.ScheduledTask{
+		TaskParams: []byte(`{"function_name": "purge_operation_records", "params": {"days": "7"}}`),
+	}, &retention)
+	require.Error(t, err)
+	assert.True(t, executor.IsPermanent(err))
+
+	_, err = RetentionParams{Days: 0}.before()
+	assert.True(t, executor.IsPermanent(err))
+}
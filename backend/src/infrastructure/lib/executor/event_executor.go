@@ -0,0 +1,131 @@
+// executor/event_executor.go
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/gbrayhan/microservices-go/src/application/event/bus"
+	"github.com/gbrayhan/microservices-go/src/application/event/model"
+	domainScheduledTask "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"go.uber.org/zap"
+)
+
+// 发布事件任务类型，字典中没有对应常量
+const TaskTypePublishEvent = "publish_event"
+
+// EventExecutor 将事件发布到事件总线（内存或 RabbitMQ），用定时任务驱动事件流程
+type EventExecutor struct {
+	eventBus bus.EventBus
+	logger   *logger.Logger
+}
+
+// EventParams publish_event 任务参数
+// EventType 不能是 model.DefaultEventRegistry 中登记的内置事件，避免任务伪造忘记密码、用户删除等系统事件
+// Payload 中的字符串和 EventID 支持模板变量，如 {{.TaskID}}、{{.FireTimestamp}}
+type EventParams struct {
+	EventType string                 `json:"event_type"`
+	EventID   string                 `json:"event_id"` // 默认 task-{{.TaskID}}-{{.FireTimestamp}}，同一次触发的重试使用相同ID，便于订阅方去重
+	Payload   map[string]interface{} `json:"payload"`
+}
+
+const eventParamsSchema = `{
+	"type": "object",
+	"required": ["event_type"],
+	"additionalProperties": false,
+	"properties": {
+		"event_type": {"type": "string", "minLength": 1, "maxLength": 100, "pattern": "^[A-Za-z][A-Za-z0-9_.:-]*$"},
+		"event_id": {"type": "string", "maxLength": 200},
+		"payload": {"type": "object"}
+	}
+}`
+
+// ValidateParams implements ParamsValidator.
+func (e *EventExecutor) ValidateParams(taskParams []byte) error {
+	var params EventParams
+	if err := json.Unmarshal(taskParams, &params); err != nil {
+		return fmt.Errorf("failed to parse event params: %w", err)
+	}
+	return validateEventType(params.EventType)
+}
+
+func validateEventType(eventType string) error {
+	if eventType == "" {
+		return fmt.Errorf("event_type is required")
+	}
+	if model.DefaultEventRegistry.IsRegistered(eventType) {
+		return fmt.Errorf("event_type %s is reserved for internal events", eventType)
+	}
+	return nil
+}
+
+// 默认事件ID，同一次触发（含重试）保持不变
+const defaultEventIDTemplate = "task-{{.TaskID}}-{{.FireTimestamp}}"
+
+func NewEventExecutor(eventBus bus.EventBus, logger *logger.Logger) *EventExecutor {
+	return &EventExecutor{
+		eventBus: eventBus,
+		logger:   logger,
+	}
+}
+
+// Describe implements TaskTypeDescriber.
+func (e *EventExecutor) Describe() TaskTypeMeta {
+	return TaskTypeMeta{
+		Description:  "发布事件到事件总线，载荷支持模板变量",
+		ParamsSchema: json.RawMessage(eventParamsSchema),
+	}
+}
+
+func (e *EventExecutor) Execute(ctx context.Context, task *domainScheduledTask.ScheduledTask) (*ExecutionResult, error) {
+	if e.eventBus == nil {
+		return nil, NewPermanentError(fmt.Errorf("event bus is not configured"))
+	}
+	var params EventParams
+	if err := json.Unmarshal(task.TaskParams, &params); err != nil {
+		return nil, NewPermanentError(fmt.Errorf("failed to parse event params: %w", err))
+	}
+	// 保存前已校验，这里再检查一次，拦截升级前保存的任务
+	if err := validateEventType(params.EventType); err != nil {
+		return nil, NewPermanentError(err)
+	}
+
+	templateData := newHTTPTemplateData(ctx, task)
+	if params.EventID == "" {
+		params.EventID = defaultEventIDTemplate
+	}
+	eventID, err := renderHTTPTemplate("event_id", params.EventID, templateData)
+	if err != nil {
+		return nil, NewPermanentError(err)
+	}
+	payload := map[string]interface{}{}
+	if params.Payload != nil {
+		rendered, err := renderHTTPBody(params.Payload, templateData)
+		if err != nil {
+			return nil, NewPermanentError(err)
+		}
+		payload = rendered.(map[string]interface{})
+	}
+
+	event := &model.GenericEvent{
+		ID:         eventID,
+		Type:       params.EventType,
+		Data:       payload,
+		OccurredAt: templateData.FireTime,
+	}
+	if err := e.eventBus.Publish(ctx, event); err != nil {
+		return nil, fmt.Errorf("failed to publish event %s: %w", params.EventType, err)
+	}
+
+	e.logger.Info("Event task published",
+		zap.Int("task_id", task.ID),
+		zap.String("event_type", params.EventType),
+		zap.String("event_id", eventID))
+	return NewExecutionResult(map[string]interface{}{
+		"event_id":   eventID,
+		"event_type": params.EventType,
+		"payload":    payload,
+	}), nil
+}
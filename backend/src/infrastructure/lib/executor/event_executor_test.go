@@ -0,0 +1,64 @@
+package executor
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gbrayhan/microservices-go/src/application/event/model"
+	domainScheduledTask "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingEventBus struct {
+	events []model.ApplicationEvent
+}
+
+func (b *recordingEventBus) Publish(ctx context.Context, event model.ApplicationEvent) error {
+	b.events = append(b.events, event)
+	return nil
+}
+
+func (b *recordingEventBus) Subscribe(eventType string, handler model.EventHandler) error {
+	return nil
+}
+
+func (b *recordingEventBus) Unsubscribe(eventType string, handler model.EventHandler) error {
+	return nil
+}
+
+func TestEventExecutorPublishesEvent(t *testing.T) {
+	eventBus := &recordingEventBus{}
+	e := NewEventExecutor(eventBus, newTestHTTPExecutor(t).logger)
+	fireTime := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+
+	_, err := e.Execute(WithFireTime(context.Background(), fireTime), &domainScheduledTask.ScheduledTask{
+		ID:         3,
+		TaskParams: []byte(`{"event_type": "ReportRequested", "payload": {"date": "{{.FireTime.Format \"2006-01-02\"}}"}}`),
+	})
+
+	require.NoError(t, err)
+	require.Len(t, eventBus.events, 1)
+	event := eventBus.events[0]
+	assert.Equal(t, "ReportRequested", event.EventType())
+	assert.Equal(t, "task-3-"+strconv.FormatInt(fireTime.Unix(), 10), event.EventID())
+	assert.Equal(t, map[string]interface{}{"date": "2024-05-01"}, event.Payload())
+}
+
+func TestEventExecutorRejectsInternalEventTypes(t *testing.T) {
+	eventBus := &recordingEventBus{}
+	e := NewEventExecutor(eventBus, newTestHTTPExecutor(t).logger)
+
+	assert.Error(t, e.ValidateParams([]byte(`{"event_type": "`+model.ForgetPasswordEventType+`"}`)))
+	assert.Error(t, e.ValidateParams([]byte(`{"event_type": "`+model.UserDeletedEventType+`"}`)))
+	assert.NoError(t, e.ValidateParams([]byte(`{"event_type": "ReportRequested"}`)))
+
+	_, err := e.Execute(context.Background(), &domainScheduledTask.ScheduledTask{
+		TaskParams: []byte(`{"event_type": "` + model.TaskAlertEventType + `"}`),
+	})
+	require.Error(t, err)
+	assert.True(t, IsPermanent(err))
+	assert.Empty(t, eventBus.events)
+}
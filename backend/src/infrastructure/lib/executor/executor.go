@@ -1,7 +1,12 @@
 package executor
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"time"
 
 	domainScheduledTask "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
 	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
@@ -9,8 +14,56 @@ import (
 )
 
 // TaskExecutor 任务执行器接口
+// ctx 由调度器控制，超时、停止任务或手动取消时会被取消，执行器需及时退出
 type TaskExecutor interface {
-	Execute(task *domainScheduledTask.ScheduledTask) error
+	Execute(ctx context.Context, task *domainScheduledTask.ScheduledTask) (*ExecutionResult, error)
+}
+
+// TaskTypeMeta 任务类型的描述和 task_params 的 JSON Schema
+type TaskTypeMeta struct {
+	Description  string
+	ParamsSchema json.RawMessage
+}
+
+// TaskTypeDescriber 执行器可选实现，用于展示任务类型，保存任务时按 ParamsSchema 校验 task_params
+type TaskTypeDescriber interface {
+	Describe() TaskTypeMeta
+}
+
+// ParamsValidator 执行器可选实现，schema 无法表达的校验（如函数参数）在这里完成
+type ParamsValidator interface {
+	ValidateParams(taskParams []byte) error
+}
+
+// TimeoutProvider 执行器可选实现，任务未配置 timeout_seconds 时提供默认超时，返回 0 表示使用调度器默认值
+type TimeoutProvider interface {
+	DefaultTimeout(task *domainScheduledTask.ScheduledTask) time.Duration
+}
+
+// ExecutionResult 单次执行的结果，失败时也可以携带输出便于排查
+// Output 会写入执行日志，过长时由调度器按 SCHEDULER_MAX_OUTPUT_BYTES 截断
+type ExecutionResult struct {
+	Output string
+}
+
+// NewExecutionResult 将任意返回值转换为执行结果，字符串原样保存，其余类型序列化为 JSON
+func NewExecutionResult(value interface{}) *ExecutionResult {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case *ExecutionResult:
+		return v
+	case string:
+		return &ExecutionResult{Output: v}
+	case []byte:
+		return &ExecutionResult{Output: string(v)}
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return &ExecutionResult{Output: fmt.Sprintf("%v", v)}
+		}
+		return &ExecutionResult{Output: string(data)}
+	}
 }
 
 // TaskExecutorManager 任务执行管理器
@@ -32,11 +85,55 @@ func (m *TaskExecutorManager) RegisterExecutor(taskType string, executor TaskExe
 	m.executors[taskType] = executor
 }
 
+// Types 返回已注册的任务类型，按类型排序
+func (m *TaskExecutorManager) Types() []domainScheduledTask.TaskTypeInfo {
+	types := make([]domainScheduledTask.TaskTypeInfo, 0, len(m.executors))
+	for taskType, executor := range m.executors {
+		info := domainScheduledTask.TaskTypeInfo{Type: taskType}
+		if describer, ok := executor.(TaskTypeDescriber); ok {
+			meta := describer.Describe()
+			info.Description = meta.Description
+			info.ParamsSchema = meta.ParamsSchema
+		}
+		types = append(types, info)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].Type < types[j].Type })
+	return types
+}
+
+// ValidateParams 保存任务前校验任务类型和 task_params
+func (m *TaskExecutorManager) ValidateParams(taskType string, taskParams []byte) error {
+	executor, exists := m.executors[taskType]
+	if !exists {
+		return fmt.Errorf("unsupported task type: %s", taskType)
+	}
+	if len(taskParams) == 0 {
+		taskParams = []byte("{}")
+	}
+	if describer, ok := executor.(TaskTypeDescriber); ok {
+		if err := validateJSONSchemaAt(describer.Describe().ParamsSchema, taskParams, "task_params"); err != nil {
+			return err
+		}
+	}
+	if validator, ok := executor.(ParamsValidator); ok {
+		return validator.ValidateParams(taskParams)
+	}
+	return nil
+}
+
+// DefaultTimeout 返回执行器为任务提供的默认超时，未提供时返回 0
+func (m *TaskExecutorManager) DefaultTimeout(task *domainScheduledTask.ScheduledTask) time.Duration {
+	if provider, ok := m.executors[task.TaskType].(TimeoutProvider); ok {
+		return provider.DefaultTimeout(task)
+	}
+	return 0
+}
+
 // Execute 执行任务
-func (m *TaskExecutorManager) Execute(task *domainScheduledTask.ScheduledTask) error {
+func (m *TaskExecutorManager) Execute(ctx context.Context, task *domainScheduledTask.ScheduledTask) (*ExecutionResult, error) {
 	executor, exists := m.executors[task.TaskType]
 	if !exists {
-		return fmt.Errorf("no executor found for task type: %s", task.TaskType)
+		return nil, NewPermanentError(fmt.Errorf("no executor found for task type: %s", task.TaskType))
 	}
 
 	m.logger.Info("Executing task",
@@ -44,5 +141,44 @@ func (m *TaskExecutorManager) Execute(task *domainScheduledTask.ScheduledTask) e
 		zap.String("task_name", task.TaskName),
 		zap.String("task_type", task.TaskType))
 
-	return executor.Execute(task)
+	return executor.Execute(ctx, task)
+}
+
+// PermanentError 不可重试的错误（如参数错误），调度器遇到时不会重试
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// NewPermanentError 包装为不可重试错误
+func NewPermanentError(err error) error {
+	return &PermanentError{Err: err}
+}
+
+// IsPermanent 判断错误是否不可重试
+func IsPermanent(err error) bool {
+	var permanentErr *PermanentError
+	return errors.As(err, &permanentErr)
+}
+
+type fireTimeKey struct{}
+
+// WithFireTime 将本次触发时间写入 ctx，供执行器做模板渲染等用途
+func WithFireTime(ctx context.Context, fireTime time.Time) context.Context {
+	return context.WithValue(ctx, fireTimeKey{}, fireTime)
+}
+
+// FireTimeFromContext 读取触发时间，未设置时返回当前时间
+func FireTimeFromContext(ctx context.Context) time.Time {
+	if fireTime, ok := ctx.Value(fireTimeKey{}).(time.Time); ok {
+		return fireTime
+	}
+	return time.Now()
 }
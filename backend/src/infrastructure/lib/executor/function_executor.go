@@ -2,17 +2,35 @@
 package executor
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"time"
 
 	domainScheduledTask "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
 	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
 	"go.uber.org/zap"
 )
 
+// TaskFunc 可注册的任务函数，需要响应 ctx 取消，返回值会作为执行输出记录到日志
+type TaskFunc func(ctx context.Context, task *domainScheduledTask.ScheduledTask) (interface{}, error)
+
+// FunctionMeta 函数注册信息，用于前端展示以及保存任务时校验参数
+type FunctionMeta struct {
+	Description    string
+	ParamsSchema   json.RawMessage // params 字段的 JSON Schema，为空时不校验
+	DefaultTimeout time.Duration   // 任务未配置 timeout_seconds 时使用，为 0 时使用调度器默认超时
+}
+
+type registeredFunction struct {
+	fn   TaskFunc
+	meta FunctionMeta
+}
+
 // FunctionExecutor 函数任务执行器
 type FunctionExecutor struct {
-	functions map[string]func(*domainScheduledTask.ScheduledTask) error
+	functions map[string]registeredFunction
 	logger    *logger.Logger
 }
 
@@ -23,30 +41,113 @@ type FunctionParams struct {
 
 func NewFunctionExecutor(logger *logger.Logger) *FunctionExecutor {
 	return &FunctionExecutor{
-		functions: make(map[string]func(*domainScheduledTask.ScheduledTask) error),
+		functions: make(map[string]registeredFunction),
 		logger:    logger,
 	}
 }
 
 // RegisterFunction 注册可执行函数
-func (e *FunctionExecutor) RegisterFunction(name string, fn func(*domainScheduledTask.ScheduledTask) error) {
-	e.functions[name] = fn
+func (e *FunctionExecutor) RegisterFunction(name string, fn TaskFunc) {
+	e.RegisterFunctionWithMeta(name, fn, FunctionMeta{})
 }
 
-func (e *FunctionExecutor) Execute(task *domainScheduledTask.ScheduledTask) error {
+// RegisterFunctionWithMeta 注册可执行函数及其描述、参数 schema 和默认超时
+// schema 无法解析属于编码错误，直接 panic
+func (e *FunctionExecutor) RegisterFunctionWithMeta(name string, fn TaskFunc, meta FunctionMeta) {
+	if len(meta.ParamsSchema) > 0 {
+		if _, err := compileSchema(meta.ParamsSchema); err != nil {
+			panic(fmt.Sprintf("function %s: %v", name, err))
+		}
+	}
+	e.functions[name] = registeredFunction{fn: fn, meta: meta}
+}
+
+// Functions 返回已注册的函数，按名称排序
+func (e *FunctionExecutor) Functions() []domainScheduledTask.TaskFunctionInfo {
+	functions := make([]domainScheduledTask.TaskFunctionInfo, 0, len(e.functions))
+	for name, function := range e.functions {
+		functions = append(functions, domainScheduledTask.TaskFunctionInfo{
+			Name:                  name,
+			Description:           function.meta.Description,
+			ParamsSchema:          function.meta.ParamsSchema,
+			DefaultTimeoutSeconds: int(function.meta.DefaultTimeout / time.Second),
+		})
+	}
+	sort.Slice(functions, func(i, j int) bool { return functions[i].Name < functions[j].Name })
+	return functions
+}
+
+// Describe implements TaskTypeDescriber.
+func (e *FunctionExecutor) Describe() TaskTypeMeta {
+	names := make([]string, 0, len(e.functions))
+	for name := range e.functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	schema, _ := json.Marshal(map[string]interface{}{
+		"type":     "object",
+		"required": []string{"function_name"},
+		"properties": map[string]interface{}{
+			"function_name": map[string]interface{}{"type": "string", "enum": names},
+			"params":        map[string]interface{}{"type": []string{"object", "null"}},
+		},
+	})
+	return TaskTypeMeta{
+		Description:  "调用通过 RegisterFunction 注册的内置函数",
+		ParamsSchema: schema,
+	}
+}
+
+// ValidateParams implements ParamsValidator，按函数注册的 schema 校验 params
+func (e *FunctionExecutor) ValidateParams(taskParams []byte) error {
 	var params FunctionParams
-	if err := json.Unmarshal(task.TaskParams, &params); err != nil {
+	if err := json.Unmarshal(taskParams, &params); err != nil {
 		return fmt.Errorf("failed to parse function params: %w", err)
 	}
-
 	function, exists := e.functions[params.FunctionName]
 	if !exists {
 		return fmt.Errorf("function not found: %s", params.FunctionName)
 	}
+	if len(function.meta.ParamsSchema) == 0 {
+		return nil
+	}
+	data := []byte("{}")
+	if params.Params != nil {
+		var err error
+		if data, err = json.Marshal(params.Params); err != nil {
+			return err
+		}
+	}
+	if err := validateJSONSchemaAt(function.meta.ParamsSchema, data, "params"); err != nil {
+		return fmt.Errorf("invalid params for %s: %w", params.FunctionName, err)
+	}
+	return nil
+}
+
+// DefaultTimeout implements TimeoutProvider.
+func (e *FunctionExecutor) DefaultTimeout(task *domainScheduledTask.ScheduledTask) time.Duration {
+	var params FunctionParams
+	if err := json.Unmarshal(task.TaskParams, &params); err != nil {
+		return 0
+	}
+	return e.functions[params.FunctionName].meta.DefaultTimeout
+}
+
+func (e *FunctionExecutor) Execute(ctx context.Context, task *domainScheduledTask.ScheduledTask) (*ExecutionResult, error) {
+	var params FunctionParams
+	if err := json.Unmarshal(task.TaskParams, &params); err != nil {
+		return nil, NewPermanentError(fmt.Errorf("failed to parse function params: %w", err))
+	}
+
+	function, exists := e.functions[params.FunctionName]
+	if !exists {
+		return nil, NewPermanentError(fmt.Errorf("function not found: %s", params.FunctionName))
+	}
 
 	e.logger.Info("Executing function task",
 		zap.Int("task_id", task.ID),
 		zap.String("function_name", params.FunctionName))
 
-	return function(task)
+	value, err := function.fn(ctx, task)
+	return NewExecutionResult(value), err
 }
@@ -0,0 +1,312 @@
+// executor/http_assertion.go
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	domainScheduledTask "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
+)
+
+const (
+	HTTPAssertionJSONPath = "jsonpath"
+	HTTPAssertionRegex    = "regex"
+)
+
+// HTTPStatusRange 期望的响应状态码范围（闭区间），未配置时默认为 200-299
+type HTTPStatusRange struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+// HTTPAssertion 响应体断言
+// jsonpath：按 Path 取值，配置了 Equals 时比较值，配置了 Pattern 时用正则匹配，否则只要求路径存在
+// regex：用 Pattern 匹配整个响应体
+type HTTPAssertion struct {
+	Type    string      `json:"type"`
+	Path    string      `json:"path"`
+	Pattern string      `json:"pattern"`
+	Equals  interface{} `json:"equals"`
+}
+
+// httpTemplateData 请求模板可用的变量，如 {{.TaskID}}、{{.FireTime.Format "2006-01-02"}}、{{.FireTimestamp}}
+type httpTemplateData struct {
+	TaskID        int
+	TaskName      string
+	FireTime      time.Time
+	FireTimestamp int64
+}
+
+// newHTTPTemplateData 按任务和本次触发时间生成模板变量
+func newHTTPTemplateData(ctx context.Context, task *domainScheduledTask.ScheduledTask) httpTemplateData {
+	fireTime := FireTimeFromContext(ctx)
+	return httpTemplateData{
+		TaskID:        task.ID,
+		TaskName:      task.TaskName,
+		FireTime:      fireTime,
+		FireTimestamp: fireTime.Unix(),
+	}
+}
+
+// renderHTTPTemplate 渲染 URL、请求头和请求体中的模板变量
+func renderHTTPTemplate(name, text string, data httpTemplateData) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid template in %s: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render template in %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// renderHTTPBody 递归渲染请求体中的字符串值，避免 JSON 转义影响模板语法
+func renderHTTPBody(body interface{}, data httpTemplateData) (interface{}, error) {
+	switch v := body.(type) {
+	case string:
+		return renderHTTPTemplate("body", v, data)
+	case map[string]interface{}:
+		rendered := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			item, err := renderHTTPBody(value, data)
+			if err != nil {
+				return nil, err
+			}
+			rendered[key] = item
+		}
+		return rendered, nil
+	case []interface{}:
+		rendered := make([]interface{}, len(v))
+		for i, value := range v {
+			item, err := renderHTTPBody(value, data)
+			if err != nil {
+				return nil, err
+			}
+			rendered[i] = item
+		}
+		return rendered, nil
+	default:
+		return v, nil
+	}
+}
+
+// bounds 返回状态码范围，未配置或 min、max 都为 0 时为 200-299，只配置 min 时只匹配该状态码
+func (r *HTTPStatusRange) bounds() (int, int) {
+	if r == nil || (r.Min == 0 && r.Max == 0) {
+		return 200, 299
+	}
+	if r.Max == 0 {
+		return r.Min, r.Min
+	}
+	return r.Min, r.Max
+}
+
+// validate 保存时拒绝永远无法匹配的范围
+func (r *HTTPStatusRange) validate() error {
+	if low, high := r.bounds(); low > high {
+		return fmt.Errorf("invalid expected_status: min %d is greater than max %d", low, high)
+	}
+	return nil
+}
+
+// statusMatches 校验响应状态码
+func (r *HTTPStatusRange) statusMatches(statusCode int) error {
+	low, high := r.bounds()
+	if statusCode < low || statusCode > high {
+		return fmt.Errorf("assertion failed: unexpected HTTP status %d, expected %d-%d", statusCode, low, high)
+	}
+	return nil
+}
+
+// compiledHTTPAssertion 预编译后的断言，参数错误在发送请求前就能发现
+type compiledHTTPAssertion struct {
+	HTTPAssertion
+	path    []jsonPathSegment
+	pattern *regexp.Regexp
+}
+
+func compileHTTPAssertions(assertions []HTTPAssertion) ([]compiledHTTPAssertion, error) {
+	compiled := make([]compiledHTTPAssertion, 0, len(assertions))
+	for i, assertion := range assertions {
+		item := compiledHTTPAssertion{HTTPAssertion: assertion}
+		switch assertion.Type {
+		case HTTPAssertionJSONPath:
+			path, err := parseJSONPath(assertion.Path)
+			if err != nil {
+				return nil, fmt.Errorf("assertion %d: %w", i, err)
+			}
+			item.path = path
+		case HTTPAssertionRegex:
+			if assertion.Pattern == "" {
+				return nil, fmt.Errorf("assertion %d: pattern is required for regex assertion", i)
+			}
+		default:
+			return nil, fmt.Errorf("assertion %d: unsupported assertion type %q", i, assertion.Type)
+		}
+		if assertion.Pattern != "" {
+			pattern, err := regexp.Compile(assertion.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("assertion %d: invalid pattern: %w", i, err)
+			}
+			item.pattern = pattern
+		}
+		compiled = append(compiled, item)
+	}
+	return compiled, nil
+}
+
+// check 对响应体执行断言，doc 为解析后的 JSON，非 JSON 响应时为 nil
+func (a compiledHTTPAssertion) check(body []byte, doc interface{}, docErr error) error {
+	if a.Type == HTTPAssertionRegex {
+		if !a.pattern.Match(body) {
+			return fmt.Errorf("assertion failed: response body does not match %q", a.Pattern)
+		}
+		return nil
+	}
+
+	if docErr != nil {
+		return fmt.Errorf("assertion failed: response body is not valid JSON: %w", docErr)
+	}
+	value, err := evalJSONPath(doc, a.path)
+	if err != nil {
+		return fmt.Errorf("assertion failed: %s: %w", a.Path, err)
+	}
+	if a.Equals != nil {
+		expected, err := normalizeJSONValue(a.Equals)
+		if err != nil {
+			return fmt.Errorf("assertion %s: invalid expected value: %w", a.Path, err)
+		}
+		if !reflect.DeepEqual(value, expected) {
+			return fmt.Errorf("assertion failed: %s is %s, expected %s", a.Path, jsonValueString(value), jsonValueString(expected))
+		}
+	}
+	if a.pattern != nil && !a.pattern.MatchString(jsonValueString(value)) {
+		return fmt.Errorf("assertion failed: %s is %s, does not match %q", a.Path, jsonValueString(value), a.Pattern)
+	}
+	return nil
+}
+
+// extractJSONPaths 按 JSONPath 从响应中提取字段，取不到的字段忽略
+func extractJSONPaths(doc interface{}, extract map[string]string) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(extract))
+	for name, rawPath := range extract {
+		path, err := parseJSONPath(rawPath)
+		if err != nil {
+			return nil, fmt.Errorf("extract %s: %w", name, err)
+		}
+		if value, err := evalJSONPath(doc, path); err == nil {
+			values[name] = value
+		}
+	}
+	return values, nil
+}
+
+// jsonPathSegment JSONPath 的一段，key 或数组下标
+type jsonPathSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// parseJSONPath 解析 JSONPath 的常用子集：$.a.b、$.items[0].name、$['a.b']
+func parseJSONPath(path string) ([]jsonPathSegment, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("invalid JSONPath %q: must start with $", path)
+	}
+	var segments []jsonPathSegment
+	for i := 1; i < len(path); {
+		switch path[i] {
+		case '.':
+			end := i + 1
+			for end < len(path) && path[end] != '.' && path[end] != '[' {
+				end++
+			}
+			if end == i+1 {
+				return nil, fmt.Errorf("invalid JSONPath %q: empty key at %d", path, i)
+			}
+			segments = append(segments, jsonPathSegment{key: path[i+1 : end]})
+			i = end
+		case '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid JSONPath %q: missing ]", path)
+			}
+			inner := path[i+1 : i+end]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				segments = append(segments, jsonPathSegment{key: inner[1 : len(inner)-1]})
+			} else {
+				index, err := strconv.Atoi(inner)
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("invalid JSONPath %q: bad index %q", path, inner)
+				}
+				segments = append(segments, jsonPathSegment{index: index, isIndex: true})
+			}
+			i += end + 1
+		default:
+			return nil, fmt.Errorf("invalid JSONPath %q: unexpected %q at %d", path, path[i], i)
+		}
+	}
+	return segments, nil
+}
+
+// evalJSONPath 在 json.Unmarshal 得到的文档上取值
+func evalJSONPath(doc interface{}, segments []jsonPathSegment) (interface{}, error) {
+	current := doc
+	for _, segment := range segments {
+		if segment.isIndex {
+			items, ok := current.([]interface{})
+			if !ok || segment.index >= len(items) {
+				return nil, fmt.Errorf("index [%d] not found", segment.index)
+			}
+			current = items[segment.index]
+			continue
+		}
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("key %q not found", segment.key)
+		}
+		value, exists := object[segment.key]
+		if !exists {
+			return nil, fmt.Errorf("key %q not found", segment.key)
+		}
+		current = value
+	}
+	return current, nil
+}
+
+// normalizeJSONValue 将期望值转换成与 json.Unmarshal 一致的类型（数字统一为 float64）
+func normalizeJSONValue(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// jsonValueString 字符串原样返回，其余值返回 JSON 表示
+func jsonValueString(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
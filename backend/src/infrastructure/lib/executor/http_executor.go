@@ -6,6 +6,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"time"
 
@@ -14,20 +15,63 @@ import (
 	"go.uber.org/zap"
 )
 
+// 响应体最多读取 1MB，写入日志时还会按配置再次截断
+const maxHTTPResponseBytes = 1 << 20
+
 // HTTPExecutor HTTP请求任务执行器
 type HTTPExecutor struct {
 	client *http.Client
 	logger *logger.Logger
 }
 
+// HTTPParams HTTP 任务参数
+// URL、Headers 的值和 Body 支持模板变量，如 {{.TaskID}}、{{.FireTimestamp}}
 type HTTPParams struct {
-	URL     string            `json:"url"`
-	Method  string            `json:"method"`
-	Headers map[string]string `json:"headers"`
-	Body    interface{}       `json:"body"`
-	Timeout int               `json:"timeout"` // 秒
+	URL            string            `json:"url"`
+	Method         string            `json:"method"`
+	Headers        map[string]string `json:"headers"`
+	Body           interface{}       `json:"body"`
+	ExpectedStatus *HTTPStatusRange  `json:"expected_status"` // 期望状态码范围，默认 200-299
+	Assertions     []HTTPAssertion   `json:"assertions"`      // 响应体断言，任一失败则本次执行失败
+	Extract        map[string]string `json:"extract"`         // 名称 -> JSONPath，提取的值写入执行输出
+	Timeout        int               `json:"timeout"`         // 已废弃：秒，任务未配置 timeout_seconds 时作为默认超时
 }
 
+// httpParamsSchema HTTPParams 的 JSON Schema
+const httpParamsSchema = `{
+	"type": "object",
+	"required": ["url"],
+	"additionalProperties": false,
+	"properties": {
+		"url": {"type": "string", "minLength": 1},
+		"method": {"type": "string", "description": "GET、POST、PUT 等，默认 GET"},
+		"headers": {"type": "object"},
+		"body": {},
+		"expected_status": {
+			"type": ["object", "null"],
+			"properties": {
+				"min": {"type": "integer", "minimum": 100, "maximum": 599},
+				"max": {"type": "integer", "minimum": 100, "maximum": 599}
+			}
+		},
+		"assertions": {
+			"type": "array",
+			"items": {
+				"type": "object",
+				"required": ["type"],
+				"properties": {
+					"type": {"type": "string", "enum": ["jsonpath", "regex"]},
+					"path": {"type": "string"},
+					"pattern": {"type": "string"},
+					"equals": {}
+				}
+			}
+		},
+		"extract": {"type": "object"},
+		"timeout": {"type": "integer", "minimum": 0, "description": "已废弃，请使用任务的 timeout_seconds"}
+	}
+}`
+
 func NewHTTPExecutor(logger *logger.Logger) *HTTPExecutor {
 	return &HTTPExecutor{
 		client: &http.Client{},
@@ -35,59 +79,141 @@ func NewHTTPExecutor(logger *logger.Logger) *HTTPExecutor {
 	}
 }
 
-func (e *HTTPExecutor) Execute(task *domainScheduledTask.ScheduledTask) error {
+// Describe implements TaskTypeDescriber.
+func (e *HTTPExecutor) Describe() TaskTypeMeta {
+	return TaskTypeMeta{
+		Description:  "发送 HTTP 请求，支持模板变量、状态码与响应体断言",
+		ParamsSchema: json.RawMessage(httpParamsSchema),
+	}
+}
+
+// ValidateParams implements ParamsValidator，提前检查断言和提取规则
+func (e *HTTPExecutor) ValidateParams(taskParams []byte) error {
 	var params HTTPParams
-	if err := json.Unmarshal(task.TaskParams, &params); err != nil {
+	if err := json.Unmarshal(taskParams, &params); err != nil {
 		return fmt.Errorf("failed to parse HTTP params: %w", err)
 	}
+	if err := params.ExpectedStatus.validate(); err != nil {
+		return err
+	}
+	if _, err := compileHTTPAssertions(params.Assertions); err != nil {
+		return fmt.Errorf("invalid HTTP assertions: %w", err)
+	}
+	for name, path := range params.Extract {
+		if _, err := parseJSONPath(path); err != nil {
+			return fmt.Errorf("invalid HTTP extract %s: %w", name, err)
+		}
+	}
+	return nil
+}
 
-	// 设置超时
-	timeout := 30 // 默认30秒
-	if params.Timeout > 0 {
-		timeout = params.Timeout
+// DefaultTimeout implements TimeoutProvider，兼容旧任务参数中的 timeout
+func (e *HTTPExecutor) DefaultTimeout(task *domainScheduledTask.ScheduledTask) time.Duration {
+	var params HTTPParams
+	if err := json.Unmarshal(task.TaskParams, &params); err != nil || params.Timeout <= 0 {
+		return 0
+	}
+	return time.Duration(params.Timeout) * time.Second
+}
+
+func (e *HTTPExecutor) Execute(ctx context.Context, task *domainScheduledTask.ScheduledTask) (*ExecutionResult, error) {
+	var params HTTPParams
+	if err := json.Unmarshal(task.TaskParams, &params); err != nil {
+		return nil, NewPermanentError(fmt.Errorf("failed to parse HTTP params: %w", err))
+	}
+
+	// 超时由调度器通过 ctx 统一控制（任务的 timeout_seconds）
+
+	// 断言和提取规则在发送请求前校验，配置错误不重试
+	assertions, err := compileHTTPAssertions(params.Assertions)
+	if err != nil {
+		return nil, NewPermanentError(fmt.Errorf("invalid HTTP assertions: %w", err))
+	}
+	for name, path := range params.Extract {
+		if _, err := parseJSONPath(path); err != nil {
+			return nil, NewPermanentError(fmt.Errorf("invalid HTTP extract %s: %w", name, err))
+		}
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
-	defer cancel()
+	templateData := newHTTPTemplateData(ctx, task)
+	url, err := renderHTTPTemplate("url", params.URL, templateData)
+	if err != nil {
+		return nil, NewPermanentError(err)
+	}
 
 	// 准备请求体
 	var bodyBytes []byte
 	if params.Body != nil {
-		var err error
-		bodyBytes, err = json.Marshal(params.Body)
+		body, err := renderHTTPBody(params.Body, templateData)
+		if err != nil {
+			return nil, NewPermanentError(err)
+		}
+		bodyBytes, err = json.Marshal(body)
 		if err != nil {
-			return fmt.Errorf("failed to marshal request body: %w", err)
+			return nil, NewPermanentError(fmt.Errorf("failed to marshal request body: %w", err))
 		}
 	}
 
 	// 创建请求
-	req, err := http.NewRequestWithContext(ctx, params.Method, params.URL, bytes.NewBuffer(bodyBytes))
+	req, err := http.NewRequestWithContext(ctx, params.Method, url, bytes.NewBuffer(bodyBytes))
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// 设置请求头
-	if params.Headers != nil {
-		for key, value := range params.Headers {
-			req.Header.Set(key, value)
-		}
+		return nil, NewPermanentError(fmt.Errorf("failed to create request: %w", err))
 	}
 
 	if params.Body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
 
+	// 设置请求头
+	for key, value := range params.Headers {
+		value, err = renderHTTPTemplate("header "+key, value, templateData)
+		if err != nil {
+			return nil, NewPermanentError(err)
+		}
+		req.Header.Set(key, value)
+	}
+
 	// 发送请求
 	resp, err := e.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to execute HTTP request: %w", err)
+		return nil, fmt.Errorf("failed to execute HTTP request: %w", err)
 	}
 	defer resp.Body.Close()
 
+	// 读取响应体，只保留前 maxHTTPResponseBytes 字节
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPResponseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HTTP response: %w", err)
+	}
+	result := &ExecutionResult{
+		Output: fmt.Sprintf("%s %s\n%s", resp.Proto, resp.Status, respBody),
+	}
+
+	// 校验状态码和响应体断言
+	if err := params.ExpectedStatus.statusMatches(resp.StatusCode); err != nil {
+		return result, err
+	}
+	var doc interface{}
+	var docErr error
+	if len(assertions) > 0 || len(params.Extract) > 0 {
+		docErr = json.Unmarshal(respBody, &doc)
+	}
+	for _, assertion := range assertions {
+		if err := assertion.check(respBody, doc, docErr); err != nil {
+			return result, err
+		}
+	}
+	if len(params.Extract) > 0 && docErr == nil {
+		values, _ := extractJSONPaths(doc, params.Extract)
+		if data, err := json.Marshal(values); err == nil {
+			result.Output = fmt.Sprintf("[extract]\n%s\n[response]\n%s", data, result.Output)
+		}
+	}
+
 	e.logger.Info("HTTP task executed successfully",
 		zap.Int("task_id", task.ID),
-		zap.String("url", params.URL),
+		zap.String("url", url),
 		zap.Int("status_code", resp.StatusCode))
 
-	return nil
+	return result, nil
 }
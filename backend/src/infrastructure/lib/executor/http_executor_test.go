@@ -0,0 +1,139 @@
+package executor
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	domainScheduledTask "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestHTTPExecutor(t *testing.T) *HTTPExecutor {
+	t.Helper()
+	loggerInstance, err := logger.NewLogger()
+	require.NoError(t, err)
+	return NewHTTPExecutor(loggerInstance)
+}
+
+func newTestHTTPServer(t *testing.T, status int, body string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHTTPExecutorFailsOnUnexpectedStatus(t *testing.T) {
+	server := newTestHTTPServer(t, http.StatusInternalServerError, "boom")
+
+	result, err := newTestHTTPExecutor(t).Execute(context.Background(), &domainScheduledTask.ScheduledTask{
+		TaskParams: []byte(`{"url": "` + server.URL + `", "method": "GET"}`),
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected HTTP status 500, expected 200-299")
+	assert.False(t, IsPermanent(err))
+	require.NotNil(t, result)
+	assert.Contains(t, result.Output, "boom")
+}
+
+func TestHTTPExecutorAssertions(t *testing.T) {
+	server := newTestHTTPServer(t, http.StatusOK, `{"status": "ok", "data": {"items": [{"count": 3}]}}`)
+	e := newTestHTTPExecutor(t)
+
+	_, err := e.Execute(context.Background(), &domainScheduledTask.ScheduledTask{
+		TaskParams: []byte(`{"url": "` + server.URL + `", "method": "GET", "assertions": [
+			{"type": "jsonpath", "path": "$.status", "equals": "ok"},
+			{"type": "jsonpath", "path": "$.data.items[0].count", "equals": 3},
+			{"type": "regex", "pattern": "\"status\":\\s*\"ok\""}
+		], "extract": {"count": "$.data.items[0].count"}}`),
+	})
+	assert.NoError(t, err)
+
+	_, err = e.Execute(context.Background(), &domainScheduledTask.ScheduledTask{
+		TaskParams: []byte(`{"url": "` + server.URL + `", "method": "GET", "assertions": [
+			{"type": "jsonpath", "path": "$.status", "equals": "down"}
+		]}`),
+	})
+	require.Error(t, err)
+	assert.Equal(t, "assertion failed: $.status is ok, expected down", err.Error())
+
+	_, err = e.Execute(context.Background(), &domainScheduledTask.ScheduledTask{
+		TaskParams: []byte(`{"url": "` + server.URL + `", "method": "GET", "assertions": [
+			{"type": "jsonpath", "path": "status"}
+		]}`),
+	})
+	require.Error(t, err)
+	assert.True(t, IsPermanent(err))
+}
+
+func TestHTTPExecutorRendersTemplates(t *testing.T) {
+	var gotHeader, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Get("X-Task-Id")
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+	}))
+	defer server.Close()
+
+	fireTime := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	ctx := WithFireTime(context.Background(), fireTime)
+	_, err := newTestHTTPExecutor(t).Execute(ctx, &domainScheduledTask.ScheduledTask{
+		ID: 42,
+		TaskParams: []byte(`{"url": "` + server.URL + `", "method": "POST",
+			"headers": {"X-Task-Id": "{{.TaskID}}"},
+			"body": {"fired_at": "{{.FireTimestamp}}", "date": "{{.FireTime.Format \"2006-01-02\"}}"}}`),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "42", gotHeader)
+	assert.JSONEq(t, `{"fired_at": "1714550400", "date": "2024-05-01"}`, gotBody)
+}
+
+func TestParseJSONPath(t *testing.T) {
+	segments, err := parseJSONPath(`$.data['a.b'][2].name`)
+	require.NoError(t, err)
+	assert.Equal(t, []jsonPathSegment{
+		{key: "data"},
+		{key: "a.b"},
+		{index: 2, isIndex: true},
+		{key: "name"},
+	}, segments)
+
+	for _, path := range []string{"data", "$.", "$[x]", "$[0"} {
+		_, err := parseJSONPath(path)
+		assert.Error(t, err, path)
+	}
+}
+
+func TestHTTPExecutorDeprecatedTimeout(t *testing.T) {
+	e := newTestHTTPExecutor(t)
+	params := []byte(`{"url": "https://example.com", "timeout": 15}`)
+
+	// 旧任务参数中的 timeout 仍能通过校验，并作为默认超时
+	require.NoError(t, ValidateJSONSchema(e.Describe().ParamsSchema, params))
+	assert.Equal(t, 15*time.Second, e.DefaultTimeout(&domainScheduledTask.ScheduledTask{TaskParams: params}))
+	assert.Zero(t, e.DefaultTimeout(&domainScheduledTask.ScheduledTask{TaskParams: []byte(`{"url": "https://example.com"}`)}))
+}
+
+func TestHTTPStatusRange(t *testing.T) {
+	var unset *HTTPStatusRange
+	assert.NoError(t, unset.statusMatches(204))
+	assert.NoError(t, (&HTTPStatusRange{}).statusMatches(200), "empty range defaults to 200-299")
+	assert.Error(t, (&HTTPStatusRange{}).statusMatches(500))
+	assert.NoError(t, (&HTTPStatusRange{Min: 404}).statusMatches(404))
+
+	e := newTestHTTPExecutor(t)
+	assert.NoError(t, e.ValidateParams([]byte(`{"url": "http://example.com", "expected_status": {}}`)))
+	assert.Error(t, e.ValidateParams([]byte(`{"url": "http://example.com", "expected_status": {"min": 500, "max": 200}}`)))
+	webhook := NewWebhookExecutor(e.logger)
+	assert.Error(t, webhook.ValidateParams([]byte(`{"url": "http://example.com", "secret": "s", "expected_status": {"min": 500, "max": 200}}`)))
+}
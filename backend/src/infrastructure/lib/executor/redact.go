@@ -0,0 +1,78 @@
+// executor/redact.go
+package executor
+
+import "encoding/json"
+
+// RedactedParamValue 接口返回任务时敏感参数的占位值，更新时原样提交表示保持不变
+const RedactedParamValue = "******"
+
+// SecretParamsProvider 执行器可选实现，返回 task_params 中需要脱敏的顶层字段
+type SecretParamsProvider interface {
+	SecretParams() []string
+}
+
+func (m *TaskExecutorManager) secretParams(taskType string) []string {
+	if provider, ok := m.executors[taskType].(SecretParamsProvider); ok {
+		return provider.SecretParams()
+	}
+	return nil
+}
+
+// RedactParams 将非空的敏感字段替换为 RedactedParamValue，无法解析时原样返回
+func (m *TaskExecutorManager) RedactParams(taskType string, taskParams []byte) []byte {
+	fields := m.secretParams(taskType)
+	if len(fields) == 0 || len(taskParams) == 0 {
+		return taskParams
+	}
+	var params map[string]interface{}
+	if err := json.Unmarshal(taskParams, &params); err != nil {
+		return taskParams
+	}
+	redacted := false
+	for _, field := range fields {
+		if value, exists := params[field]; exists && value != "" {
+			params[field] = RedactedParamValue
+			redacted = true
+		}
+	}
+	if !redacted {
+		return taskParams
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return taskParams
+	}
+	return data
+}
+
+// RestoreRedactedParams 将 updated 中仍为 RedactedParamValue 的敏感字段恢复为 current 中的值
+func (m *TaskExecutorManager) RestoreRedactedParams(taskType string, current []byte, updated []byte) []byte {
+	fields := m.secretParams(taskType)
+	if len(fields) == 0 || len(current) == 0 || len(updated) == 0 {
+		return updated
+	}
+	var currentParams, updatedParams map[string]interface{}
+	if json.Unmarshal(current, &currentParams) != nil || json.Unmarshal(updated, &updatedParams) != nil {
+		return updated
+	}
+	restored := false
+	for _, field := range fields {
+		if updatedParams[field] != RedactedParamValue {
+			continue
+		}
+		if value, exists := currentParams[field]; exists {
+			updatedParams[field] = value
+		} else {
+			delete(updatedParams, field)
+		}
+		restored = true
+	}
+	if !restored {
+		return updated
+	}
+	data, err := json.Marshal(updatedParams)
+	if err != nil {
+		return updated
+	}
+	return data
+}
@@ -0,0 +1,216 @@
+// executor/schema.go
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// jsonSchema 支持 JSON Schema 的常用子集，足够描述任务参数：
+// type、properties、required、additionalProperties、enum、minimum、maximum、
+// minLength、maxLength、pattern、items、minItems、maxItems
+type jsonSchema struct {
+	Type                 schemaTypes            `json:"type"`
+	Description          string                 `json:"description"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	Required             []string               `json:"required"`
+	AdditionalProperties *bool                  `json:"additionalProperties"`
+	Enum                 []interface{}          `json:"enum"`
+	Minimum              *float64               `json:"minimum"`
+	Maximum              *float64               `json:"maximum"`
+	MinLength            *int                   `json:"minLength"`
+	MaxLength            *int                   `json:"maxLength"`
+	Pattern              string                 `json:"pattern"`
+	Items                *jsonSchema            `json:"items"`
+	MinItems             *int                   `json:"minItems"`
+	MaxItems             *int                   `json:"maxItems"`
+}
+
+// schemaTypes type 既可以是字符串也可以是字符串数组
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = schemaTypes{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return fmt.Errorf("schema type must be a string or an array of strings")
+	}
+	*t = multiple
+	return nil
+}
+
+// compileSchema 解析 JSON Schema，注册时调用以便尽早发现错误
+func compileSchema(schema json.RawMessage) (*jsonSchema, error) {
+	var compiled jsonSchema
+	if err := json.Unmarshal(schema, &compiled); err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+	if err := compiled.compilePatterns(); err != nil {
+		return nil, err
+	}
+	return &compiled, nil
+}
+
+func (s *jsonSchema) compilePatterns() error {
+	if s == nil {
+		return nil
+	}
+	if s.Pattern != "" {
+		if _, err := regexp.Compile(s.Pattern); err != nil {
+			return fmt.Errorf("invalid schema pattern %q: %w", s.Pattern, err)
+		}
+	}
+	for _, property := range s.Properties {
+		if err := property.compilePatterns(); err != nil {
+			return err
+		}
+	}
+	return s.Items.compilePatterns()
+}
+
+// ValidateJSONSchema 按 schema 校验 JSON 数据，返回第一个不满足的字段
+func ValidateJSONSchema(schema json.RawMessage, data []byte) error {
+	return validateJSONSchemaAt(schema, data, "$")
+}
+
+// validateJSONSchemaAt 同 ValidateJSONSchema，root 为错误信息中的根路径
+func validateJSONSchemaAt(schema json.RawMessage, data []byte, root string) error {
+	if len(schema) == 0 {
+		return nil
+	}
+	compiled, err := compileSchema(schema)
+	if err != nil {
+		return err
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	return compiled.validate(root, value)
+}
+
+func (s *jsonSchema) validate(path string, value interface{}) error {
+	if len(s.Type) > 0 && !s.matchesType(value) {
+		return fmt.Errorf("%s: expected %s, got %s", path, strings.Join(s.Type, " or "), jsonTypeName(value))
+	}
+	if len(s.Enum) > 0 && !containsJSONValue(s.Enum, value) {
+		return fmt.Errorf("%s: must be one of %s", path, formatEnum(s.Enum))
+	}
+
+	switch v := value.(type) {
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			return fmt.Errorf("%s: must be >= %v", path, *s.Minimum)
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			return fmt.Errorf("%s: must be <= %v", path, *s.Maximum)
+		}
+	case string:
+		length := len([]rune(v))
+		if s.MinLength != nil && length < *s.MinLength {
+			return fmt.Errorf("%s: length must be >= %d", path, *s.MinLength)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			return fmt.Errorf("%s: length must be <= %d", path, *s.MaxLength)
+		}
+		if s.Pattern != "" && !regexp.MustCompile(s.Pattern).MatchString(v) {
+			return fmt.Errorf("%s: does not match pattern %q", path, s.Pattern)
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			return fmt.Errorf("%s: must have at least %d items", path, *s.MinItems)
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			return fmt.Errorf("%s: must have at most %d items", path, *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				if err := s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+					return err
+				}
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, exists := v[name]; !exists {
+				return fmt.Errorf("%s.%s: is required", path, name)
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, exists := s.Properties[name]
+			if !exists {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					return fmt.Errorf("%s.%s: unknown property", path, name)
+				}
+				continue
+			}
+			if err := property.validate(path+"."+name, v[name]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *jsonSchema) matchesType(value interface{}) bool {
+	actual := jsonTypeName(value)
+	for _, expected := range s.Type {
+		if expected == actual || (expected == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonTypeName(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func containsJSONValue(values []interface{}, value interface{}) bool {
+	for _, candidate := range values {
+		if fmt.Sprintf("%v", candidate) == fmt.Sprintf("%v", value) && jsonTypeName(candidate) == jsonTypeName(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatEnum(values []interface{}) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		data, _ := json.Marshal(value)
+		parts[i] = string(data)
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
@@ -0,0 +1,66 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	domainScheduledTask "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateJSONSchema(t *testing.T) {
+	schema := json.RawMessage(`{
+		"type": "object",
+		"required": ["name"],
+		"additionalProperties": false,
+		"properties": {
+			"name": {"type": "string", "minLength": 1},
+			"days": {"type": "integer", "minimum": 1},
+			"mode": {"enum": ["fast", "slow"]},
+			"tags": {"type": "array", "items": {"type": "string"}}
+		}
+	}`)
+
+	assert.NoError(t, ValidateJSONSchema(schema, []byte(`{"name": "a", "days": 3, "mode": "fast", "tags": ["x"]}`)))
+
+	cases := map[string]string{
+		`{"days": 3}`:                  "$.name: is required",
+		`{"name": "a", "days": 1.5}`:   "$.days: expected integer, got number",
+		`{"name": "a", "days": 0}`:     "$.days: must be >= 1",
+		`{"name": "a", "mode": "x"}`:   `$.mode: must be one of ["fast", "slow"]`,
+		`{"name": "a", "tags": [1]}`:   "$.tags[0]: expected string, got integer",
+		`{"name": "a", "other": true}`: "$.other: unknown property",
+	}
+	for data, expected := range cases {
+		err := ValidateJSONSchema(schema, []byte(data))
+		require.Error(t, err, data)
+		assert.Equal(t, expected, err.Error())
+	}
+}
+
+func TestFunctionExecutorValidateParams(t *testing.T) {
+	loggerInstance, err := logger.NewLogger()
+	require.NoError(t, err)
+	manager := NewTaskExecutorManager(loggerInstance)
+	functions := NewFunctionExecutor(loggerInstance)
+	manager.RegisterExecutor("function", functions)
+	functions.RegisterFunctionWithMeta("purge", func(ctx context.Context, task *domainScheduledTask.ScheduledTask) (interface{}, error) {
+		return nil, nil
+	}, FunctionMeta{ParamsSchema: json.RawMessage(`{"type": "object", "properties": {"days": {"type": "integer"}}}`)})
+
+	assert.NoError(t, manager.ValidateParams("function", []byte(`{"function_name": "purge", "params": {"days": 7}}`)))
+	assert.NoError(t, manager.ValidateParams("function", []byte(`{"function_name": "purge"}`)))
+
+	err = manager.ValidateParams("function", []byte(`{"function_name": "purge", "params": {"days": "7"}}`))
+	require.Error(t, err)
+	assert.Equal(t, "invalid params for purge: params.days: expected integer, got string", err.Error())
+
+	err = manager.ValidateParams("function", []byte(`{"function_name": "missing"}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "task_params.function_name: must be one of")
+
+	assert.Error(t, manager.ValidateParams("unknown", []byte(`{}`)))
+}
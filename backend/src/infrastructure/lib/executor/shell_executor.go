@@ -1,123 +1,355 @@
 package executor
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
 	domainScheduledTask "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/gbrayhan/microservices-go/src/shared/utils"
+	"go.uber.org/zap"
 )
 
+// 脚本进程默认 PATH，不继承服务进程的 PATH
+const defaultShellPath = "/usr/local/bin:/usr/bin:/bin"
+
+// 不允许任务参数覆盖的环境变量：影响动态链接、shell 启动和解释器加载路径的变量
+var blockedShellEnv = map[string]bool{
+	"PATH":           true,
+	"HOME":           true,
+	"ENV":            true,
+	"IFS":            true,
+	"SHELL":          true,
+	"SHELLOPTS":      true,
+	"CDPATH":         true,
+	"GLOBIGNORE":     true,
+	"PS4":            true,
+	"PROMPT_COMMAND": true,
+	"GCONV_PATH":     true,
+	"HOSTALIASES":    true,
+	"LOCALDOMAIN":    true,
+	"RES_OPTIONS":    true,
+	"TMPDIR":         true,
+	"PYTHONPATH":     true,
+	"PYTHONSTARTUP":  true,
+	"PYTHONHOME":     true,
+	"PERL5LIB":       true,
+	"PERL5OPT":       true,
+	"PERLLIB":        true,
+	"RUBYLIB":        true,
+	"RUBYOPT":        true,
+	"NODE_OPTIONS":   true,
+	"NODE_PATH":      true,
+}
+
+// 以这些前缀开头的环境变量同样不允许覆盖，如 LD_PRELOAD、BASH_ENV、BASH_FUNC_*、DYLD_INSERT_LIBRARIES
+var blockedShellEnvPrefixes = []string{"LD_", "BASH_", "DYLD_"}
+
+// 环境变量名只允许字母、数字和下划线
+var shellEnvNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// isBlockedShellEnv 判断环境变量是否不允许透传或由任务参数设置
+func isBlockedShellEnv(key string) bool {
+	if !shellEnvNamePattern.MatchString(key) {
+		return true
+	}
+	key = strings.ToUpper(key)
+	if blockedShellEnv[key] {
+		return true
+	}
+	for _, prefix := range blockedShellEnvPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // ShellCommandParams shell 命令参数结构
 type ShellCommandParams struct {
-	Command string            `json:"command"`  // 要执行的命令
+	Command string            `json:"command"`  // 要执行的命令（必须在白名单中）
 	Args    []string          `json:"args"`     // 命令参数
-	Timeout int               `json:"timeout"`  // 超时时间（秒），默认300秒
-	WorkDir string            `json:"work_dir"` // 工作目录
+	WorkDir string            `json:"work_dir"` // 工作目录，相对于沙箱根目录
 	Env     map[string]string `json:"env"`      // 环境变量
+	Timeout int               `json:"timeout"`  // 已废弃：秒，任务未配置 timeout_seconds 时作为默认超时
 }
 
-// ShellExecutor 执行 shell 脚本的执行器
-func ShellExecutor(task *domainScheduledTask.ScheduledTask) error {
-	// 解析任务参数
-	params, err := parseShellParams(task.TaskParams)
-	if err != nil {
-		return fmt.Errorf("failed to parse shell params: %w", err)
-	}
+// ShellExecutorConfig 脚本执行沙箱配置
+type ShellExecutorConfig struct {
+	AllowedCommands []string // 允许执行的命令，名称或绝对路径
+	RootDir         string   // 工作目录根，任务只能在该目录内执行
+	PassEnv         []string // 从服务进程透传的环境变量
+	MaxOutputBytes  int      // stdout/stderr 各自最多保留的字节数
+}
 
-	// 如果没有指定命令，使用 TaskDescription
-	if params.Command == "" {
-		params.Command = task.TaskDescription
+// defaultShellMaxOutputBytes stdout/stderr 各自默认保留的字节数
+const defaultShellMaxOutputBytes = 64 * 1024
+
+// DefaultShellExecutorConfig 从环境变量加载沙箱配置
+func DefaultShellExecutorConfig() ShellExecutorConfig {
+	return ShellExecutorConfig{
+		AllowedCommands: splitCommaList(os.Getenv("SCHEDULER_SHELL_ALLOWED_COMMANDS")),
+		RootDir:         utils.GetEnv("SCHEDULER_SHELL_ROOT_DIR", "scripts"),
+		PassEnv:         splitCommaList(os.Getenv("SCHEDULER_SHELL_PASS_ENV")),
+		MaxOutputBytes:  utils.GetEnvAsInt("SCHEDULER_SHELL_MAX_OUTPUT_BYTES", defaultShellMaxOutputBytes),
 	}
+}
+
+// ShellExecutor 执行 shell 命令的执行器，不经过 shell 解释，只允许白名单内的命令
+type ShellExecutor struct {
+	config ShellExecutorConfig
+	logger *logger.Logger
+}
 
-	if params.Command == "" {
-		return fmt.Errorf("no command specified for shell execution")
+func NewShellExecutor(logger *logger.Logger, config ShellExecutorConfig) *ShellExecutor {
+	// 不大于 0 时所有输出都会被丢弃，使用默认值
+	if config.MaxOutputBytes <= 0 {
+		logger.Warn("Invalid shell max output bytes, using default",
+			zap.Int("max_output_bytes", config.MaxOutputBytes), zap.Int("default", defaultShellMaxOutputBytes))
+		config.MaxOutputBytes = defaultShellMaxOutputBytes
 	}
+	return &ShellExecutor{
+		config: config,
+		logger: logger,
+	}
+}
 
-	// 设置默认超时时间
-	if params.Timeout <= 0 {
-		params.Timeout = 300 // 默认5分钟
+// Describe implements TaskTypeDescriber，command 限定为白名单内的命令
+func (e *ShellExecutor) Describe() TaskTypeMeta {
+	command := map[string]interface{}{"type": "string", "minLength": 1}
+	if len(e.config.AllowedCommands) > 0 {
+		command["enum"] = e.config.AllowedCommands
+	}
+	schema, _ := json.Marshal(map[string]interface{}{
+		"type":                 "object",
+		"required":             []string{"command"},
+		"additionalProperties": false,
+		"properties": map[string]interface{}{
+			"command":  command,
+			"args":     map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+			"work_dir": map[string]interface{}{"type": "string"},
+			"env":      map[string]interface{}{"type": "object"},
+			"timeout":  map[string]interface{}{"type": "integer", "minimum": 0, "description": "已废弃，请使用任务的 timeout_seconds"},
+		},
+	})
+	return TaskTypeMeta{
+		Description:  "在沙箱目录中执行白名单内的命令，不经过 shell 解释",
+		ParamsSchema: schema,
 	}
+}
 
-	// 准备命令和参数
-	var cmd *exec.Cmd
-	var ctx context.Context
-	var cancel context.CancelFunc
+// DefaultTimeout implements TimeoutProvider，兼容旧任务参数中的 timeout
+func (e *ShellExecutor) DefaultTimeout(task *domainScheduledTask.ScheduledTask) time.Duration {
+	params, err := parseShellParams(json.RawMessage(task.TaskParams))
+	if err != nil || params.Timeout <= 0 {
+		return 0
+	}
+	return time.Duration(params.Timeout) * time.Second
+}
 
-	// 创建带超时的上下文
-	ctx, cancel = context.WithTimeout(context.Background(), time.Duration(params.Timeout)*time.Second)
-	defer cancel()
+func (e *ShellExecutor) Execute(ctx context.Context, task *domainScheduledTask.ScheduledTask) (*ExecutionResult, error) {
+	// 解析任务参数
+	params, err := parseShellParams(json.RawMessage(task.TaskParams))
+	if err != nil {
+		return nil, NewPermanentError(fmt.Errorf("failed to parse shell params: %w", err))
+	}
 
-	// 构建命令
-	if len(params.Args) > 0 {
-		cmd = exec.CommandContext(ctx, params.Command, params.Args...)
-	} else {
-		// 如果没有明确的参数，尝试分割命令字符串
-		args := strings.Fields(params.Command)
-		if len(args) > 1 {
-			cmd = exec.CommandContext(ctx, args[0], args[1:]...)
-		} else {
-			cmd = exec.CommandContext(ctx, args[0])
+	// 如果没有明确的参数，尝试分割命令字符串
+	command := strings.TrimSpace(params.Command)
+	args := params.Args
+	if len(args) == 0 {
+		fields := strings.Fields(command)
+		if len(fields) > 0 {
+			command, args = fields[0], fields[1:]
 		}
 	}
+	if command == "" {
+		return nil, NewPermanentError(fmt.Errorf("no command specified for shell execution"))
+	}
 
-	// 设置工作目录
-	if params.WorkDir != "" {
-		cmd.Dir = params.WorkDir
+	binary, err := e.resolveCommand(command)
+	if err != nil {
+		return nil, NewPermanentError(err)
+	}
+	workDir, err := e.resolveWorkDir(params.WorkDir)
+	if err != nil {
+		return nil, NewPermanentError(err)
 	}
 
-	// 设置环境变量
-	if len(params.Env) > 0 {
-		env := append([]string{}, cmd.Env...) // 复制当前环境变量
-		for key, value := range params.Env {
-			env = append(env, fmt.Sprintf("%s=%s", key, value))
+	// 超时由调度器通过 ctx 统一控制
+	cmd := exec.CommandContext(ctx, binary, args...)
+	cmd.Dir = workDir
+	cmd.Env = e.buildEnv(workDir, params.Env)
+
+	stdout := newLimitedBuffer(e.config.MaxOutputBytes)
+	stderr := newLimitedBuffer(e.config.MaxOutputBytes)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	e.logger.Info("Executing shell task",
+		zap.Int("task_id", task.ID),
+		zap.String("command", binary),
+		zap.Strings("args", args),
+		zap.String("work_dir", workDir))
+
+	err = cmd.Run()
+	result := &ExecutionResult{
+		Output: formatShellOutput(stdout, stderr),
+	}
+	if err != nil {
+		return result, fmt.Errorf("shell command failed: %w", err)
+	}
+	return result, nil
+}
+
+// resolveCommand 校验命令是否在白名单中并返回可执行文件路径
+func (e *ShellExecutor) resolveCommand(command string) (string, error) {
+	for _, allowed := range e.config.AllowedCommands {
+		if filepath.IsAbs(allowed) {
+			if command == allowed {
+				return allowed, nil
+			}
+			continue
+		}
+		// 白名单中的命令名只匹配同名命令，不允许带路径
+		if command == allowed && !strings.ContainsRune(command, filepath.Separator) {
+			for _, dir := range filepath.SplitList(defaultShellPath) {
+				candidate := filepath.Join(dir, command)
+				if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+					return candidate, nil
+				}
+			}
+			return "", fmt.Errorf("command %q not found in %s", command, defaultShellPath)
 		}
-		cmd.Env = env
 	}
+	return "", fmt.Errorf("command %q is not in the allowed list", command)
+}
 
-	// 执行命令
-	output, err := cmd.CombinedOutput()
+// resolveWorkDir 工作目录必须位于沙箱根目录之内
+func (e *ShellExecutor) resolveWorkDir(workDir string) (string, error) {
+	root, err := filepath.Abs(e.config.RootDir)
+	if err != nil {
+		return "", fmt.Errorf("invalid shell root dir: %w", err)
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create shell root dir: %w", err)
+	}
+	root, err = filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", fmt.Errorf("invalid shell root dir: %w", err)
+	}
 
-	// 记录执行结果
+	target := filepath.Join(root, filepath.Clean("/"+workDir))
+	target, err = filepath.EvalSymlinks(target)
 	if err != nil {
-		return fmt.Errorf("shell command failed: %w, output: %s", err, string(output))
+		return "", fmt.Errorf("invalid work dir %q: %w", workDir, err)
 	}
+	rel, err := filepath.Rel(root, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("work dir %q escapes shell root dir", workDir)
+	}
+	return target, nil
+}
 
-	fmt.Printf("Shell command executed successfully:\n%s\n", string(output))
-	return nil
+// buildEnv 构造精简后的环境变量，不继承服务进程的敏感配置
+func (e *ShellExecutor) buildEnv(workDir string, extra map[string]string) []string {
+	env := []string{
+		"PATH=" + defaultShellPath,
+		"HOME=" + workDir,
+	}
+	for _, key := range e.config.PassEnv {
+		if isBlockedShellEnv(key) {
+			continue
+		}
+		if value, ok := os.LookupEnv(key); ok {
+			env = append(env, fmt.Sprintf("%s=%s", key, value))
+		}
+	}
+	for key, value := range extra {
+		if isBlockedShellEnv(key) {
+			continue
+		}
+		env = append(env, fmt.Sprintf("%s=%s", key, value))
+	}
+	return env
 }
 
 // parseShellParams 解析 shell 参数
-func parseShellParams(params interface{}) (*ShellCommandParams, error) {
-	if params == nil {
-		return &ShellCommandParams{}, nil
+func parseShellParams(params json.RawMessage) (*ShellCommandParams, error) {
+	var shellParams ShellCommandParams
+	if len(params) == 0 {
+		return &shellParams, nil
+	}
+	if err := json.Unmarshal(params, &shellParams); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal shell params: %w", err)
 	}
+	return &shellParams, nil
+}
 
-	// 如果已经是 ShellCommandParams 类型
-	if shellParams, ok := params.(*ShellCommandParams); ok {
-		return shellParams, nil
+// formatShellOutput 合并 stdout 和 stderr 用于持久化
+func formatShellOutput(stdout, stderr *limitedBuffer) string {
+	var builder strings.Builder
+	builder.WriteString("[stdout]\n")
+	builder.WriteString(stdout.String())
+	if stderr.Len() > 0 {
+		builder.WriteString("\n[stderr]\n")
+		builder.WriteString(stderr.String())
 	}
+	return builder.String()
+}
 
-	// 如果是 JSON 格式
-	if jsonData, ok := params.(json.RawMessage); ok {
-		var shellParams ShellCommandParams
-		if err := json.Unmarshal(jsonData, &shellParams); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal shell params: %w", err)
-		}
-		return &shellParams, nil
+// limitedBuffer 只保留前 limit 个字节，超出部分丢弃但不报错，避免阻塞子进程
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func newLimitedBuffer(limit int) *limitedBuffer {
+	return &limitedBuffer{limit: limit}
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	remaining := b.limit - b.buf.Len()
+	if remaining <= 0 {
+		b.truncated = b.truncated || len(p) > 0
+		return len(p), nil
 	}
+	if len(p) > remaining {
+		b.buf.Write(p[:remaining])
+		b.truncated = true
+		return len(p), nil
+	}
+	b.buf.Write(p)
+	return len(p), nil
+}
 
-	// 如果是字符串格式的 JSON
-	if jsonStr, ok := params.(string); ok {
-		var shellParams ShellCommandParams
-		if err := json.Unmarshal([]byte(jsonStr), &shellParams); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal shell params from string: %w", err)
-		}
-		return &shellParams, nil
+func (b *limitedBuffer) Len() int {
+	return b.buf.Len()
+}
+
+func (b *limitedBuffer) String() string {
+	if b.truncated {
+		return b.buf.String() + "\n...(truncated)"
 	}
+	return b.buf.String()
+}
 
-	return &ShellCommandParams{}, nil
+// splitCommaList 解析逗号分隔的配置
+func splitCommaList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
@@ -0,0 +1,98 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	domainScheduledTask "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestShellExecutor(t *testing.T, allowed ...string) (*ShellExecutor, string) {
+	t.Helper()
+	root := t.TempDir()
+	loggerInstance, err := logger.NewLogger()
+	require.NoError(t, err)
+	return NewShellExecutor(loggerInstance, ShellExecutorConfig{
+		AllowedCommands: allowed,
+		RootDir:         root,
+		MaxOutputBytes:  16,
+	}), root
+}
+
+func TestShellExecutorRejectsCommandOutsideAllowList(t *testing.T) {
+	e, _ := newTestShellExecutor(t, "echo")
+
+	_, err := e.Execute(context.Background(), &domainScheduledTask.ScheduledTask{
+		TaskParams: []byte(`{"command": "rm -rf /"}`),
+	})
+
+	assert.Error(t, err)
+	assert.True(t, IsPermanent(err))
+	assert.Contains(t, err.Error(), "not in the allowed list")
+}
+
+func TestShellExecutorRejectsWorkDirEscape(t *testing.T) {
+	e, root := newTestShellExecutor(t, "echo")
+	outside := t.TempDir()
+	require.NoError(t, os.Symlink(outside, filepath.Join(root, "link")))
+
+	_, err := e.resolveWorkDir("link")
+	assert.Error(t, err)
+
+	dir, err := e.resolveWorkDir("../../")
+	assert.NoError(t, err)
+	resolvedRoot, _ := filepath.EvalSymlinks(root)
+	assert.Equal(t, resolvedRoot, dir)
+}
+
+func TestShellExecutorCapturesTruncatedOutput(t *testing.T) {
+	e, _ := newTestShellExecutor(t, "echo")
+
+	result, err := e.Execute(context.Background(), &domainScheduledTask.ScheduledTask{
+		TaskParams: []byte(`{"command": "echo", "args": ["0123456789abcdefghij"]}`),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "[stdout]\n0123456789abcdef\n...(truncated)", result.Output)
+}
+
+func TestShellExecutorScrubsEnvironment(t *testing.T) {
+	t.Setenv("SHELL_EXECUTOR_SECRET", "secret")
+	e, root := newTestShellExecutor(t)
+
+	env := e.buildEnv(root, map[string]string{
+		"FOO":               "bar",
+		"LD_PRELOAD":        "evil.so",
+		"ld_audit":          "evil.so",
+		"BASH_ENV":          "/tmp/evil.sh",
+		"BASH_FUNC_ls%%":    "() { evil; }",
+		"ENV":               "/tmp/evil.sh",
+		"IFS":               "/",
+		"PYTHONPATH":        "/tmp",
+		"DYLD_LIBRARY_PATH": "/tmp",
+	})
+
+	assert.Contains(t, env, "FOO=bar")
+	assert.Contains(t, env, "PATH="+defaultShellPath)
+	assert.Len(t, env, 3, "only PATH, HOME and FOO are set: %v", env)
+	assert.NotContains(t, env, "SHELL_EXECUTOR_SECRET=secret")
+}
+
+func TestShellExecutorDefaultsNonPositiveMaxOutput(t *testing.T) {
+	loggerInstance, err := logger.NewLogger()
+	require.NoError(t, err)
+	e := NewShellExecutor(loggerInstance, ShellExecutorConfig{AllowedCommands: []string{"echo"}, RootDir: t.TempDir()})
+
+	result, err := e.Execute(context.Background(), &domainScheduledTask.ScheduledTask{
+		TaskParams: []byte(`{"command": "echo", "args": ["hello"]}`),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, defaultShellMaxOutputBytes, e.config.MaxOutputBytes)
+	assert.Contains(t, result.Output, "hello")
+}
@@ -0,0 +1,236 @@
+// executor/webhook_executor.go
+package executor
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	domainScheduledTask "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"go.uber.org/zap"
+)
+
+// 签名 webhook 任务类型，字典中没有对应常量
+const TaskTypeSignedWebhook = "signed_webhook"
+
+// 默认请求头
+const (
+	DefaultSignatureHeader   = "X-Signature"
+	DefaultTimestampHeader   = "X-Timestamp"
+	DefaultIdempotencyHeader = "Idempotency-Key"
+)
+
+// secret_env 只能引用该前缀的环境变量，避免任务读取数据库密码等其他配置
+const WebhookSecretEnvPrefix = "WEBHOOK_SECRET_"
+
+// 默认幂等键，同一次触发（含重试）保持不变
+const defaultIdempotencyKeyTemplate = "task-{{.TaskID}}-{{.FireTimestamp}}"
+
+// WebhookExecutor 发送带 HMAC 签名和幂等键的 webhook
+// 签名为 sha256=hex(HMAC-SHA256(secret, timestamp + "." + body))，接收方用相同密钥校验并拒绝过旧的时间戳
+type WebhookExecutor struct {
+	client *http.Client
+	logger *logger.Logger
+}
+
+// WebhookParams signed_webhook 任务参数
+// 密钥二选一：secret 直接配置（接口返回时脱敏），secret_env 从 WEBHOOK_SECRET_ 开头的环境变量读取，避免密钥保存在任务参数中
+type WebhookParams struct {
+	URL               string            `json:"url"`
+	Method            string            `json:"method"` // 默认 POST
+	Headers           map[string]string `json:"headers"`
+	Body              interface{}       `json:"body"`
+	Secret            string            `json:"secret"`
+	SecretEnv         string            `json:"secret_env"`
+	SignatureHeader   string            `json:"signature_header"`
+	TimestampHeader   string            `json:"timestamp_header"`
+	IdempotencyHeader string            `json:"idempotency_header"`
+	IdempotencyKey    string            `json:"idempotency_key"` // 支持模板变量，默认 task-{{.TaskID}}-{{.FireTimestamp}}
+	ExpectedStatus    *HTTPStatusRange  `json:"expected_status"`
+}
+
+const webhookParamsSchema = `{
+	"type": "object",
+	"required": ["url"],
+	"additionalProperties": false,
+	"properties": {
+		"url": {"type": "string", "minLength": 1},
+		"method": {"type": "string", "enum": ["POST", "PUT", "PATCH"]},
+		"headers": {"type": "object"},
+		"body": {},
+		"secret": {"type": "string"},
+		"secret_env": {"type": "string", "pattern": "^WEBHOOK_SECRET_[A-Za-z0-9_]+$"},
+		"signature_header": {"type": "string"},
+		"timestamp_header": {"type": "string"},
+		"idempotency_header": {"type": "string"},
+		"idempotency_key": {"type": "string", "maxLength": 200},
+		"expected_status": {
+			"type": ["object", "null"],
+			"properties": {
+				"min": {"type": "integer", "minimum": 100, "maximum": 599},
+				"max": {"type": "integer", "minimum": 100, "maximum": 599}
+			}
+		}
+	}
+}`
+
+func NewWebhookExecutor(logger *logger.Logger) *WebhookExecutor {
+	return &WebhookExecutor{
+		client: &http.Client{},
+		logger: logger,
+	}
+}
+
+// SignWebhookPayload 计算 webhook 签名，返回 sha256=<hex>
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Describe implements TaskTypeDescriber.
+func (e *WebhookExecutor) Describe() TaskTypeMeta {
+	return TaskTypeMeta{
+		Description:  "发送带 HMAC-SHA256 签名和幂等键的 webhook",
+		ParamsSchema: json.RawMessage(webhookParamsSchema),
+	}
+}
+
+// SecretParams implements SecretParamsProvider.
+func (e *WebhookExecutor) SecretParams() []string {
+	return []string{"secret"}
+}
+
+// ValidateParams implements ParamsValidator.
+func (e *WebhookExecutor) ValidateParams(taskParams []byte) error {
+	var params WebhookParams
+	if err := json.Unmarshal(taskParams, &params); err != nil {
+		return fmt.Errorf("failed to parse webhook params: %w", err)
+	}
+	if (params.Secret == "") == (params.SecretEnv == "") {
+		return fmt.Errorf("exactly one of secret and secret_env is required")
+	}
+	return params.ExpectedStatus.validate()
+}
+
+func (e *WebhookExecutor) Execute(ctx context.Context, task *domainScheduledTask.ScheduledTask) (*ExecutionResult, error) {
+	var params WebhookParams
+	if err := json.Unmarshal(task.TaskParams, &params); err != nil {
+		return nil, NewPermanentError(fmt.Errorf("failed to parse webhook params: %w", err))
+	}
+	secret, err := params.resolveSecret()
+	if err != nil {
+		return nil, NewPermanentError(err)
+	}
+
+	templateData := newHTTPTemplateData(ctx, task)
+	url, err := renderHTTPTemplate("url", params.URL, templateData)
+	if err != nil {
+		return nil, NewPermanentError(err)
+	}
+	if params.IdempotencyKey == "" {
+		params.IdempotencyKey = defaultIdempotencyKeyTemplate
+	}
+	idempotencyKey, err := renderHTTPTemplate("idempotency_key", params.IdempotencyKey, templateData)
+	if err != nil {
+		return nil, NewPermanentError(err)
+	}
+	var bodyBytes []byte
+	if params.Body != nil {
+		body, err := renderHTTPBody(params.Body, templateData)
+		if err != nil {
+			return nil, NewPermanentError(err)
+		}
+		if bodyBytes, err = json.Marshal(body); err != nil {
+			return nil, NewPermanentError(fmt.Errorf("failed to marshal webhook body: %w", err))
+		}
+	}
+
+	method := strings.ToUpper(params.Method)
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(bodyBytes))
+	if err != nil {
+		return nil, NewPermanentError(fmt.Errorf("failed to create webhook request: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range params.Headers {
+		if value, err = renderHTTPTemplate("header "+key, value, templateData); err != nil {
+			return nil, NewPermanentError(err)
+		}
+		req.Header.Set(key, value)
+	}
+
+	// 每次尝试使用当前时间戳重新签名，幂等键在重试间保持不变
+	timestamp := time.Now().Unix()
+	req.Header.Set(headerOrDefault(params.TimestampHeader, DefaultTimestampHeader), strconv.FormatInt(timestamp, 10))
+	req.Header.Set(headerOrDefault(params.SignatureHeader, DefaultSignatureHeader), SignWebhookPayload(secret, timestamp, bodyBytes))
+	req.Header.Set(headerOrDefault(params.IdempotencyHeader, DefaultIdempotencyHeader), idempotencyKey)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPResponseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook response: %w", err)
+	}
+	result := &ExecutionResult{
+		Output: fmt.Sprintf("idempotency key: %s\n%s %s\n%s", idempotencyKey, resp.Proto, resp.Status, respBody),
+	}
+	if err := params.ExpectedStatus.statusMatches(resp.StatusCode); err != nil {
+		// 4xx（429 除外）说明请求本身有问题，重试也不会成功
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return result, NewPermanentError(err)
+		}
+		return result, err
+	}
+
+	e.logger.Info("Webhook task executed successfully",
+		zap.Int("task_id", task.ID),
+		zap.String("url", url),
+		zap.String("idempotency_key", idempotencyKey),
+		zap.Int("status_code", resp.StatusCode))
+	return result, nil
+}
+
+func (p *WebhookParams) resolveSecret() (string, error) {
+	if p.Secret != "" {
+		return p.Secret, nil
+	}
+	if p.SecretEnv == "" {
+		return "", fmt.Errorf("webhook secret is not configured")
+	}
+	// 保存前 schema 已限制前缀，这里再检查一次，拦截升级前保存的任务
+	if !strings.HasPrefix(p.SecretEnv, WebhookSecretEnvPrefix) {
+		return "", fmt.Errorf("webhook secret env %s must start with %s", p.SecretEnv, WebhookSecretEnvPrefix)
+	}
+	secret := os.Getenv(p.SecretEnv)
+	if secret == "" {
+		return "", fmt.Errorf("webhook secret env %s is empty", p.SecretEnv)
+	}
+	return secret, nil
+}
+
+func headerOrDefault(header, fallback string) string {
+	if header == "" {
+		return fallback
+	}
+	return header
+}
@@ -0,0 +1,87 @@
+package executor
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	domainScheduledTask "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookExecutorSignsRequest(t *testing.T) {
+	var signature, idempotencyKey string
+	var verified bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(DefaultTimestampHeader), 10, 64)
+		signature = r.Header.Get(DefaultSignatureHeader)
+		idempotencyKey = r.Header.Get(DefaultIdempotencyHeader)
+		verified = signature == SignWebhookPayload("s3cret", timestamp, body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(server.Close)
+
+	e := NewWebhookExecutor(newTestHTTPExecutor(t).logger)
+	fireTime := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	task := &domainScheduledTask.ScheduledTask{
+		ID:         7,
+		TaskParams: []byte(`{"url": "` + server.URL + `", "secret": "s3cret", "body": {"task": "{{.TaskID}}"}}`),
+	}
+
+	_, err := e.Execute(WithFireTime(context.Background(), fireTime), task)
+
+	require.NoError(t, err)
+	assert.True(t, verified, "signature %s does not verify", signature)
+	assert.Equal(t, "task-7-"+strconv.FormatInt(fireTime.Unix(), 10), idempotencyKey)
+}
+
+func TestWebhookExecutorClientErrorIsPermanent(t *testing.T) {
+	server := newTestHTTPServer(t, http.StatusBadRequest, "bad signature")
+	e := NewWebhookExecutor(newTestHTTPExecutor(t).logger)
+
+	_, err := e.Execute(context.Background(), &domainScheduledTask.ScheduledTask{
+		TaskParams: []byte(`{"url": "` + server.URL + `", "secret": "s3cret"}`),
+	})
+
+	require.Error(t, err)
+	assert.True(t, IsPermanent(err))
+	assert.Error(t, e.ValidateParams([]byte(`{"url": "http://example.com"}`)))
+}
+
+func TestWebhookExecutorSecretEnvPrefix(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "postgres")
+	e := NewWebhookExecutor(newTestHTTPExecutor(t).logger)
+	m := NewTaskExecutorManager(e.logger)
+	m.RegisterExecutor(TaskTypeSignedWebhook, e)
+
+	assert.Error(t, m.ValidateParams(TaskTypeSignedWebhook, []byte(`{"url": "http://example.com", "secret_env": "DB_PASSWORD"}`)))
+	assert.NoError(t, m.ValidateParams(TaskTypeSignedWebhook, []byte(`{"url": "http://example.com", "secret_env": "WEBHOOK_SECRET_CRM"}`)))
+
+	// 升级前保存的任务在执行时拒绝
+	_, err := e.Execute(context.Background(), &domainScheduledTask.ScheduledTask{
+		TaskParams: []byte(`{"url": "http://example.com", "secret_env": "DB_PASSWORD"}`),
+	})
+	require.Error(t, err)
+	assert.True(t, IsPermanent(err))
+}
+
+func TestWebhookSecretIsRedacted(t *testing.T) {
+	m := NewTaskExecutorManager(newTestHTTPExecutor(t).logger)
+	m.RegisterExecutor(TaskTypeSignedWebhook, NewWebhookExecutor(m.logger))
+	stored := []byte(`{"url": "http://example.com", "secret": "s3cret"}`)
+
+	redacted := m.RedactParams(TaskTypeSignedWebhook, stored)
+	assert.JSONEq(t, `{"url": "http://example.com", "secret": "******"}`, string(redacted))
+
+	// 原样提交占位值时保留库中的密钥，提交新值时使用新值
+	updated := m.RestoreRedactedParams(TaskTypeSignedWebhook, stored, []byte(`{"url": "http://example.org", "secret": "******"}`))
+	assert.JSONEq(t, `{"url": "http://example.org", "secret": "s3cret"}`, string(updated))
+	updated = m.RestoreRedactedParams(TaskTypeSignedWebhook, stored, []byte(`{"url": "http://example.org", "secret": "rotated"}`))
+	assert.JSONEq(t, `{"url": "http://example.org", "secret": "rotated"}`, string(updated))
+}
@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/ThreeDotsLabs/watermill"
 	"github.com/gbrayhan/microservices-go/src/domain/constants"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -293,3 +294,43 @@ func (l *GormZapLogger) Trace(ctx context.Context, begin time.Time, fc func() (s
 		l.zap.Warnf("SLOW ≥ %s | %.3fms | rows:%d | %s", l.config.SlowThreshold, float64(elapsed.Nanoseconds())/1e6, rows, sql)
 	}
 }
+
+// WatermillZapLogger 把 watermill 的日志输出到 zap
+type WatermillZapLogger struct {
+	zap    *zap.Logger
+	fields watermill.LogFields
+}
+
+func NewWatermillLogger(base *zap.Logger) *WatermillZapLogger {
+	return &WatermillZapLogger{zap: base}
+}
+
+func (l *WatermillZapLogger) Error(msg string, err error, fields watermill.LogFields) {
+	l.zap.Error(msg, append(l.zapFields(fields), zap.Error(err))...)
+}
+
+func (l *WatermillZapLogger) Info(msg string, fields watermill.LogFields) {
+	l.zap.Info(msg, l.zapFields(fields)...)
+}
+
+func (l *WatermillZapLogger) Debug(msg string, fields watermill.LogFields) {
+	l.zap.Debug(msg, l.zapFields(fields)...)
+}
+
+// Trace watermill 的 trace 日志量很大，按 debug 级别输出
+func (l *WatermillZapLogger) Trace(msg string, fields watermill.LogFields) {
+	l.zap.Debug(msg, l.zapFields(fields)...)
+}
+
+func (l *WatermillZapLogger) With(fields watermill.LogFields) watermill.LoggerAdapter {
+	return &WatermillZapLogger{zap: l.zap, fields: l.fields.Add(fields)}
+}
+
+func (l *WatermillZapLogger) zapFields(fields watermill.LogFields) []zap.Field {
+	all := l.fields.Add(fields)
+	zapFields := make([]zap.Field, 0, len(all))
+	for key, value := range all {
+		zapFields = append(zapFields, zap.Any(key, value))
+	}
+	return zapFields
+}
@@ -0,0 +1,304 @@
+// scheduler/concurrency.go
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	domainScheduledTask "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
+	domainTaskExecutionLog "github.com/gbrayhan/microservices-go/src/domain/sys/task_execution_log"
+	"go.uber.org/zap"
+)
+
+// runningTask 同一任务所有正在进行的执行
+type runningTask struct {
+	wg      sync.WaitGroup
+	cancels map[string]context.CancelFunc // executionID -> cancel
+}
+
+func (r *runningTask) cancelAll() {
+	for _, cancel := range r.cancels {
+		cancel()
+	}
+}
+
+// beginRun 按任务的并发策略在本节点登记一次执行，返回错误表示本次触发应跳过
+//   - forbid：上一次执行未结束时跳过本次
+//   - replace：取消正在进行的执行后开始本次
+//   - allow（默认）：允许并行执行
+//
+// 其他节点上的执行由 registerRun 处理
+func (s *TaskScheduler) beginRun(task *domainScheduledTask.ScheduledTask, executionID string) (context.Context, context.CancelFunc, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// StopTask 正在等待执行结束，此时不能再调用 wg.Add
+	if s.stopping[task.ID] {
+		return nil, nil, errors.New("task is being stopped")
+	}
+	run, exists := s.running[task.ID]
+	if exists && len(run.cancels) > 0 {
+		switch task.ConcurrencyPolicy {
+		case domainScheduledTask.ConcurrencyForbid:
+			return nil, nil, errors.New("previous execution is still running (concurrency policy forbid)")
+		case domainScheduledTask.ConcurrencyReplace:
+			s.logger.Info("Replacing running execution",
+				zap.Int("task_id", task.ID),
+				zap.String("execution_id", executionID))
+			run.cancelAll()
+		}
+	}
+	if !exists {
+		run = &runningTask{cancels: make(map[string]context.CancelFunc)}
+		s.running[task.ID] = run
+	}
+
+	runCtx, runCancel := context.WithCancel(s.ctx)
+	run.cancels[executionID] = runCancel
+	run.wg.Add(1)
+	s.runningWg.Add(1)
+	return runCtx, runCancel, nil
+}
+
+// registerRun 在 Redis 中登记本次执行，使取消和运行状态查询跨节点生效；
+// forbid/replace 任务同时登记独占的执行：forbid 时其他节点仍在执行则跳过，
+// replace 时覆盖登记，原执行在续期时发现被替换后自行取消。返回的 release 在执行结束时调用
+func (s *TaskScheduler) registerRun(task *domainScheduledTask.ScheduledTask, executionID string, runCancel context.CancelFunc) (func(), error) {
+	if s.runRegistry == nil {
+		return func() {}, nil
+	}
+
+	claimKey := ""
+	if policy := task.ConcurrencyPolicy; policy == domainScheduledTask.ConcurrencyForbid || policy == domainScheduledTask.ConcurrencyReplace {
+		claimKey = GetTaskRunningKey(task.ID)
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		claimed, err := s.runRegistry.Claim(ctx, claimKey, executionID, s.lockTTL, policy == domainScheduledTask.ConcurrencyReplace)
+		cancel()
+		if err != nil {
+			s.logger.Error("Failed to register running execution",
+				zap.Int("task_id", task.ID),
+				zap.String("execution_id", executionID),
+				zap.Error(err))
+			return nil, fmt.Errorf("failed to check running executions: %w", err)
+		}
+		if !claimed {
+			return nil, errors.New("previous execution is still running on another node (concurrency policy forbid)")
+		}
+	}
+
+	// 登记失败时只影响跨节点取消，续期时会重新登记
+	executionsKey := GetTaskExecutionsKey(task.ID)
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	if err := s.runRegistry.Register(ctx, executionsKey, executionID, s.lockTTL); err != nil {
+		s.logger.Warn("Failed to register execution",
+			zap.Int("task_id", task.ID),
+			zap.String("execution_id", executionID),
+			zap.Error(err))
+	}
+	cancel()
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		s.keepRunning(task, claimKey, executionID, runCancel, done)
+	}()
+	return func() {
+		close(done)
+		<-stopped
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		if err := s.runRegistry.Unregister(ctx, executionsKey, executionID); err != nil {
+			s.logger.Warn("Failed to unregister execution",
+				zap.Int("task_id", task.ID),
+				zap.String("execution_id", executionID),
+				zap.Error(err))
+		}
+		if claimKey == "" {
+			return
+		}
+		if err := s.runRegistry.Release(ctx, claimKey, executionID); err != nil {
+			s.logger.Warn("Failed to release running execution",
+				zap.Int("task_id", task.ID),
+				zap.String("execution_id", executionID),
+				zap.Error(err))
+		}
+	}, nil
+}
+
+// keepRunning 执行期间定期续期登记；执行在其他节点上被取消，或独占的登记已被其他执行替换时取消本次执行
+func (s *TaskScheduler) keepRunning(task *domainScheduledTask.ScheduledTask, claimKey string, executionID string, runCancel context.CancelFunc, done <-chan struct{}) {
+	ticker := time.NewTicker(s.lockTTL / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		cancelled, err := s.runRegistry.Heartbeat(ctx, GetTaskExecutionsKey(task.ID), executionID, s.lockTTL)
+		cancel()
+		if err != nil {
+			s.logger.Warn("Failed to refresh execution",
+				zap.Int("task_id", task.ID),
+				zap.String("execution_id", executionID),
+				zap.Error(err))
+		} else if cancelled {
+			s.logger.Info("Execution cancelled",
+				zap.Int("task_id", task.ID),
+				zap.String("execution_id", executionID))
+			runCancel()
+			return
+		}
+		if claimKey == "" {
+			continue
+		}
+
+		ctx, cancel = context.WithTimeout(context.Background(), 3*time.Second)
+		alive, err := s.runRegistry.Refresh(ctx, claimKey, executionID, s.lockTTL)
+		cancel()
+		if err != nil {
+			s.logger.Warn("Failed to refresh running execution",
+				zap.Int("task_id", task.ID),
+				zap.String("execution_id", executionID),
+				zap.Error(err))
+			continue
+		}
+		if !alive {
+			s.logger.Info("Execution replaced by another execution, cancelling",
+				zap.Int("task_id", task.ID),
+				zap.String("execution_id", executionID))
+			runCancel()
+			return
+		}
+	}
+}
+
+// endRun 注销一次执行
+func (s *TaskScheduler) endRun(taskID int, executionID string, runCancel context.CancelFunc) {
+	runCancel()
+	s.mutex.Lock()
+	run := s.running[taskID]
+	delete(run.cancels, executionID)
+	if len(run.cancels) == 0 {
+		delete(s.running, taskID)
+	}
+	s.mutex.Unlock()
+	run.wg.Done()
+	s.runningWg.Done()
+}
+
+// isRunning 任务在当前节点是否有正在进行的执行
+func (s *TaskScheduler) isRunning(taskID int) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	_, exists := s.running[taskID]
+	return exists
+}
+
+// isRunningAnywhere 任务在当前节点或其他节点上是否有正在进行的执行
+func (s *TaskScheduler) isRunningAnywhere(taskID int) bool {
+	if s.isRunning(taskID) {
+		return true
+	}
+	if s.runRegistry == nil {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	executions, err := s.runRegistry.Executions(ctx, GetTaskExecutionsKey(taskID))
+	if err != nil {
+		s.logger.Warn("Failed to check running executions", zap.Int("task_id", taskID), zap.Error(err))
+		return false
+	}
+	return executions > 0
+}
+
+// CancelExecution 取消任务当前正在进行的所有执行，不影响后续调度；
+// 本节点的执行立即取消，其他节点上的执行在下次续期时（lockTTL/3 内）取消
+func (s *TaskScheduler) CancelExecution(taskID int) error {
+	s.mutex.RLock()
+	run, local := s.running[taskID]
+	if local {
+		run.cancelAll()
+	}
+	s.mutex.RUnlock()
+
+	remote := 0
+	if s.runRegistry != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		count, err := s.runRegistry.Cancel(ctx, GetTaskExecutionsKey(taskID))
+		cancel()
+		if err != nil {
+			s.logger.Error("Failed to cancel executions on other nodes", zap.Int("task_id", taskID), zap.Error(err))
+			if !local {
+				return fmt.Errorf("failed to cancel executions on other nodes: %w", err)
+			}
+		}
+		remote = count
+	}
+
+	if !local && remote == 0 {
+		s.logger.Warn("No running execution to cancel", zap.Int("task_id", taskID))
+		return fmt.Errorf("task is not running")
+	}
+
+	s.logger.Info("Task execution cancelled", zap.Int("task_id", taskID), zap.Bool("local", local), zap.Int("registered", remote))
+	return nil
+}
+
+// acquireWorker 占用全局工作池的一个位置，ctx 取消时放弃并返回 false
+func (s *TaskScheduler) acquireWorker(ctx context.Context, task *domainScheduledTask.ScheduledTask) bool {
+	select {
+	case s.workers <- struct{}{}:
+		return true
+	default:
+	}
+
+	s.logger.Warn("Worker pool is full, waiting for a free worker",
+		zap.Int("task_id", task.ID),
+		zap.Int("pool_size", cap(s.workers)))
+	select {
+	case s.workers <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (s *TaskScheduler) releaseWorker() {
+	<-s.workers
+}
+
+// recordSkipped 记录被跳过的触发，便于在执行日志中排查
+func (s *TaskScheduler) recordSkipped(task *domainScheduledTask.ScheduledTask, executionID string, reason string) {
+	s.logger.Warn("Task fire skipped",
+		zap.Int("task_id", task.ID),
+		zap.String("task_name", task.TaskName),
+		zap.String("execution_id", executionID),
+		zap.String("reason", reason))
+
+	duration := 0
+	logData := &domainTaskExecutionLog.TaskExecutionLog{
+		TaskID:          uint(task.ID),
+		ExecuteTime:     time.Now(),
+		ExecuteDuration: &duration,
+		ExecuteResult:   domainTaskExecutionLog.ExecuteResultSkipped,
+		ErrorMessage:    reason,
+		ExecuteNode:     s.instanceID,
+		ExecutionID:     executionID,
+		Attempt:         1,
+	}
+	taskLogData, err := s.taskExecutionLogRepo.Create(logData)
+	if err != nil {
+		s.logger.Error("Failed to create task execution log",
+			zap.Int("task_id", task.ID),
+			zap.Error(err))
+		return
+	}
+	s.wsHandler.NotifyLogToTaskSubscribers(task.ID, taskLogData)
+}
@@ -0,0 +1,149 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	domainScheduledTask "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestScheduler(t *testing.T) *TaskScheduler {
+	t.Helper()
+	loggerInstance, err := logger.NewLogger()
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	return &TaskScheduler{
+		logger:  loggerInstance,
+		ctx:     ctx,
+		running: make(map[int]*runningTask),
+	}
+}
+
+func TestBeginRunConcurrencyPolicies(t *testing.T) {
+	s := newTestScheduler(t)
+
+	forbid := &domainScheduledTask.ScheduledTask{ID: 1, ConcurrencyPolicy: domainScheduledTask.ConcurrencyForbid}
+	_, cancelFirst, err := s.beginRun(forbid, "a")
+	require.NoError(t, err)
+	_, _, err = s.beginRun(forbid, "b")
+	assert.Error(t, err, "forbid skips overlapping fires")
+	s.endRun(forbid.ID, "a", cancelFirst)
+	assert.False(t, s.isRunning(forbid.ID))
+
+	allow := &domainScheduledTask.ScheduledTask{ID: 2}
+	_, cancelA, err := s.beginRun(allow, "a")
+	require.NoError(t, err)
+	_, cancelB, err := s.beginRun(allow, "b")
+	require.NoError(t, err)
+	s.endRun(allow.ID, "a", cancelA)
+	assert.True(t, s.isRunning(allow.ID), "other execution is still tracked")
+	s.endRun(allow.ID, "b", cancelB)
+	assert.False(t, s.isRunning(allow.ID))
+
+	replace := &domainScheduledTask.ScheduledTask{ID: 3, ConcurrencyPolicy: domainScheduledTask.ConcurrencyReplace}
+	oldCtx, cancelOld, err := s.beginRun(replace, "old")
+	require.NoError(t, err)
+	newCtx, cancelNew, err := s.beginRun(replace, "new")
+	require.NoError(t, err)
+	assert.Error(t, oldCtx.Err(), "replace cancels the running execution")
+	assert.NoError(t, newCtx.Err())
+	s.endRun(replace.ID, "old", cancelOld)
+	s.endRun(replace.ID, "new", cancelNew)
+}
+
+func TestBeginRunRejectsStoppingTask(t *testing.T) {
+	s := newTestScheduler(t)
+	s.stopping = map[int]bool{1: true}
+
+	_, _, err := s.beginRun(&domainScheduledTask.ScheduledTask{ID: 1}, "a")
+	assert.EqualError(t, err, "task is being stopped")
+	assert.False(t, s.isRunning(1))
+}
+
+// newTestNode 共用同一个 Redis 的调度节点
+func newTestNode(t *testing.T, server *miniredis.Miniredis) *TaskScheduler {
+	t.Helper()
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	s := newTestScheduler(t)
+	s.runRegistry = NewRedisTaskLocker(client)
+	s.lockTTL = 30 * time.Millisecond
+	return s
+}
+
+func TestRegisterRunAcrossNodes(t *testing.T) {
+	server := miniredis.RunT(t)
+	nodeA, nodeB := newTestNode(t, server), newTestNode(t, server)
+
+	forbid := &domainScheduledTask.ScheduledTask{ID: 1, ConcurrencyPolicy: domainScheduledTask.ConcurrencyForbid}
+	release, err := nodeA.registerRun(forbid, "a", func() {})
+	require.NoError(t, err)
+	assert.True(t, nodeB.isRunningAnywhere(forbid.ID))
+	_, err = nodeB.registerRun(forbid, "b", func() {})
+	assert.ErrorContains(t, err, "running on another node", "forbid skips fires on other nodes")
+	release()
+	assert.False(t, nodeB.isRunningAnywhere(forbid.ID))
+
+	replace := &domainScheduledTask.ScheduledTask{ID: 2, ConcurrencyPolicy: domainScheduledTask.ConcurrencyReplace}
+	oldCtx, cancelOld := context.WithCancel(context.Background())
+	defer cancelOld()
+	releaseOld, err := nodeA.registerRun(replace, "old", cancelOld)
+	require.NoError(t, err)
+	releaseNew, err := nodeB.registerRun(replace, "new", func() {})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return oldCtx.Err() != nil }, time.Second, 5*time.Millisecond,
+		"replaced execution on the other node is cancelled")
+	releaseOld()
+	assert.True(t, server.Exists(GetTaskRunningKey(replace.ID)), "old execution does not release the new claim")
+	releaseNew()
+	assert.False(t, server.Exists(GetTaskRunningKey(replace.ID)))
+}
+
+func TestCancelExecutionAcrossNodes(t *testing.T) {
+	server := miniredis.RunT(t)
+	nodeA, nodeB := newTestNode(t, server), newTestNode(t, server)
+
+	task := &domainScheduledTask.ScheduledTask{ID: 5}
+	runCtx, runCancel := context.WithCancel(context.Background())
+	defer runCancel()
+	release, err := nodeA.registerRun(task, "a", runCancel)
+	require.NoError(t, err)
+	assert.True(t, nodeB.isRunningAnywhere(task.ID), "allow executions are registered too")
+
+	// 执行在 nodeA 上，取消请求落在 nodeB
+	require.NoError(t, nodeB.CancelExecution(task.ID))
+	require.Eventually(t, func() bool { return runCtx.Err() != nil }, time.Second, 5*time.Millisecond,
+		"execution on the other node is cancelled")
+	release()
+
+	assert.False(t, nodeB.isRunningAnywhere(task.ID))
+	assert.EqualError(t, nodeB.CancelExecution(task.ID), "task is not running")
+}
+
+func TestWorkerWaitCancelledRecordsSkipped(t *testing.T) {
+	s := newTestScheduler(t)
+	logs := withExecutionLog(t, s)
+	s.workers = make(chan struct{}, 1)
+	s.workers <- struct{}{}
+
+	task := &domainScheduledTask.ScheduledTask{ID: 4, TaskName: "report"}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.executeTask(task, "exec-1", fireManual, time.Now())
+	}()
+	require.Eventually(t, func() bool { return s.isRunning(task.ID) }, time.Second, 5*time.Millisecond)
+	require.NoError(t, s.CancelExecution(task.ID))
+	<-done
+
+	require.Len(t, logs.logs, 1)
+	assert.Equal(t, "exec-1", logs.logs[0].ExecutionID)
+	assert.Contains(t, logs.logs[0].ErrorMessage, "waiting for a free worker")
+}
@@ -0,0 +1,78 @@
+// scheduler/config.go
+package scheduler
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/gbrayhan/microservices-go/src/shared/utils"
+	"go.uber.org/zap"
+)
+
+// resolveInstanceID 获取当前节点标识，优先使用 SCHEDULER_INSTANCE_ID
+func resolveInstanceID() string {
+	if id := os.Getenv("SCHEDULER_INSTANCE_ID"); id != "" {
+		return id
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+// resolveLockTTL 获取锁过期时间，默认60秒
+func resolveLockTTL() time.Duration {
+	return time.Duration(getPositiveEnvAsInt("SCHEDULER_LOCK_TTL_SECOND", 60)) * time.Second
+}
+
+// resolveDefaultTimeout 任务未配置 timeout_seconds 时的默认超时，默认300秒
+func resolveDefaultTimeout() time.Duration {
+	return time.Duration(getPositiveEnvAsInt("SCHEDULER_DEFAULT_TIMEOUT_SECOND", 300)) * time.Second
+}
+
+// resolveMaxOutputBytes 执行输出写入日志前的最大字节数，默认64KB
+func resolveMaxOutputBytes() int {
+	return getPositiveEnvAsInt("SCHEDULER_MAX_OUTPUT_BYTES", 64*1024)
+}
+
+// resolveWorkerPoolSize 全局同时执行的任务数上限，默认10
+func resolveWorkerPoolSize() int {
+	return getPositiveEnvAsInt("SCHEDULER_WORKER_POOL_SIZE", 10)
+}
+
+// resolveDefaultLocation 任务未配置时区时使用的 IANA 时区，读取 SCHEDULER_DEFAULT_TIMEZONE，默认 UTC
+func resolveDefaultLocation(logger *logger.Logger) *time.Location {
+	name := os.Getenv("SCHEDULER_DEFAULT_TIMEZONE")
+	if name == "" {
+		return time.UTC
+	}
+	loc, err := LoadTimezone(name)
+	if err != nil {
+		logger.Warn("Invalid SCHEDULER_DEFAULT_TIMEZONE, using UTC", zap.String("timezone", name), zap.Error(err))
+		return time.UTC
+	}
+	return loc
+}
+
+// LoadTimezone 加载 IANA 时区；Local 取决于服务器配置，各节点可能不一致，不允许使用
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, fmt.Errorf("invalid timezone %q", name)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q", name)
+	}
+	return loc, nil
+}
+
+// getPositiveEnvAsInt 读取正整数环境变量，不合法或不大于0时使用默认值
+func getPositiveEnvAsInt(key string, defaultValue int) int {
+	if value := utils.GetEnvAsInt(key, defaultValue); value > 0 {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,269 @@
+// scheduler/cron.go
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// 与 gocron 的 Cron / CronWithSeconds 使用相同的解析规则
+var secondsCronParser = cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// 预览时最多返回的触发次数
+const maxCronPreviewCount = 100
+
+// ParseCronExpression 解析 5 位或 6 位（含秒）cron 表达式，loc 为空时使用 UTC
+func ParseCronExpression(expression string, loc *time.Location) (cron.Schedule, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	withLocation := expression
+	if !strings.HasPrefix(expression, "TZ=") && !strings.HasPrefix(expression, "CRON_TZ=") {
+		withLocation = fmt.Sprintf("CRON_TZ=%s %s", loc.String(), expression)
+	}
+	if len(cronFields(expression)) == 6 {
+		return secondsCronParser.Parse(withLocation)
+	}
+	return cron.ParseStandard(withLocation)
+}
+
+// ValidateCronExpression 保存任务前校验 cron 表达式，支持 5 位、6 位（含秒）和 @daily 等描述符
+func ValidateCronExpression(expression string) error {
+	fields := cronFields(expression)
+	if len(fields) == 0 {
+		return fmt.Errorf("cron expression is required")
+	}
+	if !strings.HasPrefix(fields[0], "@") && len(fields) != 5 && len(fields) != 6 {
+		return fmt.Errorf("cron expression must have 5 or 6 fields, got %d", len(fields))
+	}
+	if _, err := ParseCronExpression(strings.TrimSpace(expression), time.UTC); err != nil {
+		return fmt.Errorf("invalid cron expression %q: %w", expression, err)
+	}
+	return nil
+}
+
+// NextFireTimes 计算 from 之后的 count 次触发时间，结果使用 loc 时区
+func NextFireTimes(expression string, loc *time.Location, from time.Time, count int) ([]time.Time, error) {
+	if count <= 0 || count > maxCronPreviewCount {
+		return nil, fmt.Errorf("count must be between 1 and %d", maxCronPreviewCount)
+	}
+	if err := ValidateCronExpression(expression); err != nil {
+		return nil, err
+	}
+	schedule, err := ParseCronExpression(strings.TrimSpace(expression), loc)
+	if err != nil {
+		return nil, err
+	}
+	times := make([]time.Time, 0, count)
+	next := from
+	for len(times) < count {
+		next = schedule.Next(next)
+		// 表达式在未来 5 年内不会触发（如 2 月 30 日）
+		if next.IsZero() {
+			break
+		}
+		times = append(times, next.In(loc))
+	}
+	return times, nil
+}
+
+// cronFields 拆分表达式字段，去掉开头的 TZ= / CRON_TZ= 时区
+func cronFields(expression string) []string {
+	fields := strings.Fields(expression)
+	if len(fields) > 0 && (strings.HasPrefix(fields[0], "TZ=") || strings.HasPrefix(fields[0], "CRON_TZ=")) {
+		return fields[1:]
+	}
+	return fields
+}
+
+var cronWeekdayNames = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}
+
+var cronMonthNames = []string{"", "January", "February", "March", "April", "May", "June",
+	"July", "August", "September", "October", "November", "December"}
+
+// DescribeCronExpression 生成 cron 表达式的英文描述，如 "0 9 * * 1-5" -> "At 09:00, on Monday through Friday"
+func DescribeCronExpression(expression string) (string, error) {
+	if err := ValidateCronExpression(expression); err != nil {
+		return "", err
+	}
+	fields := cronFields(expression)
+	if strings.HasPrefix(fields[0], "@") {
+		return describeCronDescriptor(strings.Join(fields, " ")), nil
+	}
+
+	second := "0"
+	if len(fields) == 6 {
+		second, fields = fields[0], fields[1:]
+	}
+	minute, hour, dom, month, dow := fields[0], fields[1], fields[2], fields[3], fields[4]
+
+	parts := []string{describeCronTime(second, minute, hour)}
+	if days := describeCronDays(dom, dow); days != "" {
+		parts = append(parts, days)
+	}
+	if !isCronWildcard(month) {
+		if strings.Contains(month, "/") {
+			parts = append(parts, describeCronField(month, "month", cronMonthName))
+		} else {
+			parts = append(parts, "in "+describeCronField(month, "month", cronMonthName))
+		}
+	}
+	return strings.Join(parts, ", "), nil
+}
+
+func describeCronDescriptor(descriptor string) string {
+	switch descriptor {
+	case "@yearly", "@annually":
+		return "At 00:00, on day 1 of the month, in January"
+	case "@monthly":
+		return "At 00:00, on day 1 of the month"
+	case "@weekly":
+		return "At 00:00, on Sunday"
+	case "@daily", "@midnight":
+		return "At 00:00"
+	case "@hourly":
+		return "At minute 0"
+	}
+	if interval, ok := strings.CutPrefix(descriptor, "@every "); ok {
+		return "Every " + strings.TrimSpace(interval)
+	}
+	return descriptor
+}
+
+func describeCronTime(second, minute, hour string) string {
+	if isCronNumber(second) && isCronNumber(minute) && isCronNumber(hour) {
+		h, _ := strconv.Atoi(hour)
+		m, _ := strconv.Atoi(minute)
+		s, _ := strconv.Atoi(second)
+		if s == 0 {
+			return fmt.Sprintf("At %02d:%02d", h, m)
+		}
+		return fmt.Sprintf("At %02d:%02d:%02d", h, m, s)
+	}
+
+	var clauses []string
+	if second != "0" {
+		clauses = append(clauses, describeCronTimeField(second, "second"))
+	}
+	if !isCronWildcard(minute) || len(clauses) == 0 {
+		clauses = append(clauses, describeCronTimeField(minute, "minute"))
+	}
+	if !isCronWildcard(hour) {
+		clauses = append(clauses, describeCronTimeField(hour, "hour"))
+	}
+	text := strings.Join(clauses, ", ")
+	return strings.ToUpper(text[:1]) + text[1:]
+}
+
+func describeCronTimeField(field, unit string) string {
+	text := describeCronField(field, unit, nil)
+	if strings.HasPrefix(text, "every") {
+		return text
+	}
+	if strings.ContainsAny(field, ",-") {
+		return "at " + unit + "s " + text
+	}
+	return "at " + unit + " " + text
+}
+
+func describeCronDays(dom, dow string) string {
+	var clauses []string
+	if !isCronWildcard(dom) {
+		text := describeCronField(dom, "day", nil)
+		if !strings.HasPrefix(text, "every") {
+			if strings.ContainsAny(dom, ",-") {
+				text = "on days " + text + " of the month"
+			} else {
+				text = "on day " + text + " of the month"
+			}
+		}
+		clauses = append(clauses, text)
+	}
+	if !isCronWildcard(dow) {
+		text := describeCronField(dow, "day of the week", cronWeekdayName)
+		if !strings.HasPrefix(text, "every") {
+			text = "on " + text
+		}
+		clauses = append(clauses, text)
+	}
+	// 同时限定日期和星期时，满足任意一个即触发
+	return strings.Join(clauses, " or ")
+}
+
+// describeCronField 描述单个字段：*/5 -> every 5 minutes；1-5 -> 1 through 5；1,15 -> 1 and 15
+func describeCronField(field, unit string, name func(string) string) string {
+	if isCronWildcard(field) {
+		return "every " + unit
+	}
+	if name == nil {
+		name = func(value string) string { return value }
+	}
+	parts := strings.Split(field, ",")
+	items := make([]string, 0, len(parts))
+	for _, part := range parts {
+		base, step, hasStep := strings.Cut(part, "/")
+		low, high, isRange := strings.Cut(base, "-")
+		var text string
+		switch {
+		case hasStep && isCronWildcard(base):
+			text = fmt.Sprintf("every %s %s", step, pluralCronUnit(unit))
+		case hasStep && isRange:
+			text = fmt.Sprintf("every %s %s from %s through %s", step, pluralCronUnit(unit), name(low), name(high))
+		case hasStep:
+			text = fmt.Sprintf("every %s %s starting at %s", step, pluralCronUnit(unit), name(base))
+		case isRange:
+			text = name(low) + " through " + name(high)
+		default:
+			text = name(base)
+		}
+		items = append(items, text)
+	}
+	if len(items) == 1 {
+		return items[0]
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+}
+
+func pluralCronUnit(unit string) string {
+	if rest, ok := strings.CutPrefix(unit, "day of"); ok {
+		return "days of" + rest
+	}
+	return unit + "s"
+}
+
+func cronWeekdayName(value string) string {
+	if n, err := strconv.Atoi(value); err == nil && n >= 0 && n < len(cronWeekdayNames) {
+		return cronWeekdayNames[n]
+	}
+	for _, day := range cronWeekdayNames {
+		if strings.EqualFold(day[:3], value) {
+			return day
+		}
+	}
+	return value
+}
+
+func cronMonthName(value string) string {
+	if n, err := strconv.Atoi(value); err == nil && n > 0 && n < len(cronMonthNames) {
+		return cronMonthNames[n]
+	}
+	for _, month := range cronMonthNames[1:] {
+		if strings.EqualFold(month[:3], value) {
+			return month
+		}
+	}
+	return value
+}
+
+func isCronWildcard(field string) bool {
+	return field == "*" || field == "?"
+}
+
+func isCronNumber(field string) bool {
+	_, err := strconv.Atoi(field)
+	return err == nil
+}
@@ -0,0 +1,95 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	domainScheduledTask "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCronExpression(t *testing.T) {
+	for _, expression := range []string{"*/5 * * * *", "0 30 8 * * 1-5", "@daily", "CRON_TZ=Asia/Shanghai 0 9 * * *"} {
+		assert.NoError(t, ValidateCronExpression(expression), expression)
+	}
+
+	err := ValidateCronExpression("* * * *")
+	require.Error(t, err)
+	assert.Equal(t, "cron expression must have 5 or 6 fields, got 4", err.Error())
+	assert.Error(t, ValidateCronExpression(""))
+	assert.Error(t, ValidateCronExpression("61 * * * *"))
+}
+
+func TestDescribeCronExpression(t *testing.T) {
+	cases := map[string]string{
+		"* * * * *":        "Every minute",
+		"*/5 * * * *":      "Every 5 minutes",
+		"0 9 * * 1-5":      "At 09:00, on Monday through Friday",
+		"30 0 8 1,15 * *":  "At 08:00:30, on days 1 and 15 of the month",
+		"0 9-17 * * *":     "At minute 0, at hours 9 through 17",
+		"*/10 * * * * *":   "Every 10 seconds",
+		"0 0 1 JAN,JUL *":  "At 00:00, on day 1 of the month, in January and July",
+		"0 12 * * SAT,SUN": "At 12:00, on Saturday and Sunday",
+		"@every 1h30m":     "Every 1h30m",
+		"0 8 1 * 1":        "At 08:00, on day 1 of the month or on Monday",
+	}
+	for expression, expected := range cases {
+		description, err := DescribeCronExpression(expression)
+		require.NoError(t, err, expression)
+		assert.Equal(t, expected, description, expression)
+	}
+}
+
+func TestNextFireTimes(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	require.NoError(t, err)
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	times, err := NextFireTimes("0 9 * * *", shanghai, from, 2)
+	require.NoError(t, err)
+	require.Len(t, times, 2)
+	assert.Equal(t, time.Date(2024, 5, 1, 9, 0, 0, 0, shanghai), times[0])
+	assert.Equal(t, time.Date(2024, 5, 2, 9, 0, 0, 0, shanghai), times[1])
+
+	_, err = NextFireTimes("0 9 * * *", time.UTC, from, 0)
+	assert.Error(t, err)
+}
+
+func TestMissedFireTimeUsesTaskTimezone(t *testing.T) {
+	s := &TaskScheduler{location: time.UTC}
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	require.NoError(t, err)
+	task := &domainScheduledTask.ScheduledTask{
+		CronExpression:  "0 2 * * *",
+		Timezone:        "Asia/Shanghai",
+		LastExecuteTime: time.Date(2024, 4, 30, 2, 0, 0, 0, shanghai),
+	}
+
+	missedAt, missed := s.missedFireTime(task, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	assert.True(t, missed)
+	assert.Equal(t, time.Date(2024, 5, 1, 2, 0, 0, 0, shanghai), missedAt.In(shanghai))
+}
+
+func TestLoadTimezone(t *testing.T) {
+	loc, err := LoadTimezone("Asia/Shanghai")
+	require.NoError(t, err)
+	assert.Equal(t, "Asia/Shanghai", loc.String())
+
+	for _, name := range []string{"", "Local", "Mars/Olympus"} {
+		_, err := LoadTimezone(name)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestResolveDefaultLocationFallsBackToUTC(t *testing.T) {
+	loggerInstance, err := logger.NewLogger()
+	require.NoError(t, err)
+
+	t.Setenv("SCHEDULER_DEFAULT_TIMEZONE", "Asia/Shanghai")
+	assert.Equal(t, "Asia/Shanghai", resolveDefaultLocation(loggerInstance).String())
+
+	t.Setenv("SCHEDULER_DEFAULT_TIMEZONE", "Mars/Olympus")
+	assert.Equal(t, time.UTC, resolveDefaultLocation(loggerInstance))
+}
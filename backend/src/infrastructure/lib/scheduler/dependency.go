@@ -0,0 +1,146 @@
+// scheduler/dependency.go
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	domainScheduledTask "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
+	scheduleTaskConstants "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task/constants"
+	"go.uber.org/zap"
+)
+
+// triggerDownstream 上游任务执行结束后，启动满足触发条件的下游任务
+func (s *TaskScheduler) triggerDownstream(upstream *domainScheduledTask.ScheduledTask, runErr error) {
+	if s.dependencyRepo == nil || s.ctx.Err() != nil {
+		return
+	}
+
+	dependencies, err := s.dependencyRepo.GetByUpstreamTaskID(upstream.ID)
+	if err != nil {
+		s.logger.Error("Failed to load downstream tasks",
+			zap.Int("task_id", upstream.ID),
+			zap.Error(err))
+		return
+	}
+
+	for _, dependency := range *dependencies {
+		if !matchTriggerCondition(dependency.TriggerCondition, runErr == nil) {
+			continue
+		}
+
+		task, err := s.repo.GetByID(dependency.TaskID)
+		if err != nil {
+			s.logger.Error("Failed to get downstream task",
+				zap.Int("task_id", dependency.TaskID),
+				zap.Error(err))
+			continue
+		}
+		if strconv.Itoa(task.Status) == scheduleTaskConstants.TaskStatusDisabled {
+			s.logger.Info("Downstream task is disabled, skipping",
+				zap.Int("task_id", task.ID),
+				zap.Int("upstream_task_id", upstream.ID))
+			continue
+		}
+
+		ready, executionSet, err := s.otherUpstreamsSatisfied(task, upstream.ID)
+		if err != nil {
+			s.logger.Error("Failed to check upstream tasks",
+				zap.Int("task_id", task.ID),
+				zap.Error(err))
+			continue
+		}
+		if !ready {
+			s.logger.Debug("Downstream task is waiting for other upstream tasks",
+				zap.Int("task_id", task.ID),
+				zap.Int("upstream_task_id", upstream.ID))
+			continue
+		}
+
+		executionID := newExecutionID()
+		if !s.acquireFanInLock(task, executionID, executionSet) {
+			continue
+		}
+		s.logger.Info("Triggering downstream task",
+			zap.Int("task_id", task.ID),
+			zap.String("task_name", task.TaskName),
+			zap.Int("upstream_task_id", upstream.ID))
+		go s.executeTask(task, executionID, fireDependency, time.Now())
+	}
+}
+
+// acquireFanInLock 汇聚场景下多个上游几乎同时结束时，各自都会看到其余上游已完成；
+// 按这一组上游执行加锁，保证下游只被触发一次。只有一个上游时 executionSet 为空，不需要加锁
+func (s *TaskScheduler) acquireFanInLock(task *domainScheduledTask.ScheduledTask, executionID string, executionSet string) bool {
+	if s.locker == nil || executionSet == "" {
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	acquired, err := s.locker.TryLock(ctx, GetTaskFanInLockKey(task.ID, executionSet), s.instanceID, s.lockTTL)
+	if err != nil {
+		s.logger.Error("Failed to acquire fan-in lock, skipping downstream trigger",
+			zap.Int("task_id", task.ID),
+			zap.Error(err))
+		s.recordSkipped(task, executionID, fmt.Sprintf("skipped: failed to acquire fan-in lock: %v", err))
+		return false
+	}
+	if !acquired {
+		s.logger.Debug("Downstream task already triggered by another upstream",
+			zap.Int("task_id", task.ID))
+	}
+	return acquired
+}
+
+// otherUpstreamsSatisfied 汇聚场景下，其余上游需在下游上次执行之后跑完且满足各自的触发条件；
+// 同时返回这一组上游执行的标识（上游ID和上次执行时间），只有一个上游时为空
+func (s *TaskScheduler) otherUpstreamsSatisfied(task *domainScheduledTask.ScheduledTask, triggeredBy int) (bool, string, error) {
+	dependencies, err := s.dependencyRepo.GetByTaskID(task.ID)
+	if err != nil {
+		return false, "", err
+	}
+	if len(*dependencies) < 2 {
+		return true, "", nil
+	}
+	executions := make([]string, 0, len(*dependencies))
+	for _, dependency := range *dependencies {
+		upstream, err := s.repo.GetByID(dependency.UpstreamTaskID)
+		if err != nil {
+			return false, "", err
+		}
+		executions = append(executions, fmt.Sprintf("%d@%d", upstream.ID, upstream.LastExecuteTime.UnixMicro()))
+		if dependency.UpstreamTaskID == triggeredBy {
+			continue
+		}
+		if !upstream.LastExecuteTime.After(task.LastExecuteTime) {
+			return false, "", nil
+		}
+		status := strconv.Itoa(upstream.Status)
+		if status == scheduleTaskConstants.TaskStatusRunning {
+			return false, "", nil
+		}
+		if !matchTriggerCondition(dependency.TriggerCondition, status != scheduleTaskConstants.TaskStatusError) {
+			return false, "", nil
+		}
+	}
+	sort.Strings(executions)
+	return true, strings.Join(executions, ","), nil
+}
+
+// matchTriggerCondition 判断上游执行结果是否满足触发条件，未配置时按成功处理
+func matchTriggerCondition(condition string, succeeded bool) bool {
+	switch condition {
+	case domainScheduledTask.TriggerAlways:
+		return true
+	case domainScheduledTask.TriggerOnFailure:
+		return !succeeded
+	default:
+		return succeeded
+	}
+}
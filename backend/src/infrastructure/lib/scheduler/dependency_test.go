@@ -0,0 +1,84 @@
+package scheduler
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	domainScheduledTask "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
+	scheduleTaskConstants "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task/constants"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDependencyRepository struct {
+	dependencies []domainScheduledTask.TaskDependency
+}
+
+func (r *fakeDependencyRepository) GetAll() (*[]domainScheduledTask.TaskDependency, error) {
+	return &r.dependencies, nil
+}
+
+func (r *fakeDependencyRepository) GetByTaskID(taskID int) (*[]domainScheduledTask.TaskDependency, error) {
+	dependencies := make([]domainScheduledTask.TaskDependency, 0)
+	for _, dependency := range r.dependencies {
+		if dependency.TaskID == taskID {
+			dependencies = append(dependencies, dependency)
+		}
+	}
+	return &dependencies, nil
+}
+
+func (r *fakeDependencyRepository) GetByUpstreamTaskID(upstreamTaskID int) (*[]domainScheduledTask.TaskDependency, error) {
+	dependencies := make([]domainScheduledTask.TaskDependency, 0)
+	for _, dependency := range r.dependencies {
+		if dependency.UpstreamTaskID == upstreamTaskID {
+			dependencies = append(dependencies, dependency)
+		}
+	}
+	return &dependencies, nil
+}
+
+func (r *fakeDependencyRepository) ReplaceByTaskID(taskID int, dependencies []domainScheduledTask.TaskDependency) error {
+	return nil
+}
+
+func (r *fakeDependencyRepository) DeleteByTaskIDs(taskIDs []int) error {
+	return nil
+}
+
+func TestFanInTriggersDownstreamOnce(t *testing.T) {
+	s := newTestScheduler(t)
+	withExecutionLog(t, s)
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { client.Close() })
+	s.locker = NewRedisTaskLocker(client)
+	s.lockTTL = time.Minute
+
+	enabled, _ := strconv.Atoi(scheduleTaskConstants.TaskStatusEnabled)
+	lastRun := time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC)
+	s.repo = &fakeTaskRepository{tasks: map[int]domainScheduledTask.ScheduledTask{
+		1: {ID: 1, Status: enabled, LastExecuteTime: lastRun.Add(time.Hour)},
+		2: {ID: 2, Status: enabled, LastExecuteTime: lastRun.Add(time.Hour + time.Second)},
+		3: {ID: 3, Status: enabled, LastExecuteTime: lastRun},
+	}}
+	s.dependencyRepo = &fakeDependencyRepository{dependencies: []domainScheduledTask.TaskDependency{
+		{TaskID: 3, UpstreamTaskID: 1},
+		{TaskID: 3, UpstreamTaskID: 2},
+	}}
+	downstream := &domainScheduledTask.ScheduledTask{ID: 3, LastExecuteTime: lastRun}
+
+	// 两个上游同时结束，都看到另一个上游已完成
+	readyA, setA, err := s.otherUpstreamsSatisfied(downstream, 1)
+	require.NoError(t, err)
+	readyB, setB, err := s.otherUpstreamsSatisfied(downstream, 2)
+	require.NoError(t, err)
+	require.True(t, readyA)
+	require.True(t, readyB)
+	assert.Equal(t, setA, setB, "both upstreams see the same execution set")
+
+	assert.True(t, s.acquireFanInLock(downstream, "exec-a", setA))
+	assert.False(t, s.acquireFanInLock(downstream, "exec-b", setB), "downstream is triggered only once")
+}
@@ -0,0 +1,182 @@
+// scheduler/lock.go
+package scheduler
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	TaskFireLockKeyPrefix   = "scheduler:lock:%d:%d"
+	TaskRunningKeyPrefix    = "scheduler:running:%d"
+	TaskExecutionsKeyPrefix = "scheduler:executions:%d"
+	TaskFanInLockKeyPrefix  = "scheduler:fanin:%d:%s"
+)
+
+// TaskLocker 分布式锁接口，保证同一次触发只在一个节点上执行
+type TaskLocker interface {
+	TryLock(ctx context.Context, key string, owner string, ttl time.Duration) (bool, error)
+}
+
+// TaskRunRegistry 在多个节点间登记任务的执行：
+// Claim/Refresh/Release 记录 forbid/replace 任务独占的执行，使并发策略跨节点生效；
+// Register/Heartbeat/Unregister 记录所有执行，使取消和运行状态查询跨节点生效
+type TaskRunRegistry interface {
+	// Claim 登记执行；replace 为 false 且已有执行时返回 false，为 true 时覆盖已有执行
+	Claim(ctx context.Context, key string, executionID string, ttl time.Duration, replace bool) (bool, error)
+	// Refresh 续期，返回 false 表示已被其他执行替换或已过期
+	Refresh(ctx context.Context, key string, executionID string, ttl time.Duration) (bool, error)
+	// Release 执行结束时注销，只删除自己登记的执行
+	Release(ctx context.Context, key string, executionID string) error
+	// Register 登记一次执行，ttl 内没有 Heartbeat 视为节点已退出
+	Register(ctx context.Context, key string, executionID string, ttl time.Duration) error
+	// Heartbeat 续期，返回 true 表示执行已被取消
+	Heartbeat(ctx context.Context, key string, executionID string, ttl time.Duration) (bool, error)
+	// Unregister 执行结束时注销
+	Unregister(ctx context.Context, key string, executionID string) error
+	// Cancel 把所有未过期的执行标记为取消，返回标记的执行数
+	Cancel(ctx context.Context, key string) (int, error)
+	// Executions 未过期的执行数
+	Executions(ctx context.Context, key string) (int, error)
+}
+
+// refreshRunningScript 仍为当前执行时续期
+var refreshRunningScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+    return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseRunningScript 仍为当前执行时删除
+var releaseRunningScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+    return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// 执行登记在每个任务一个 hash 中：field 为执行ID，值为过期时间（毫秒时间戳），取消后改为负数
+
+// heartbeatExecutionScript 续期执行，已被取消时保留取消标记并返回 1
+var heartbeatExecutionScript = redis.NewScript(`
+local value = redis.call('HGET', KEYS[1], ARGV[1])
+local cancelled = value and string.sub(value, 1, 1) == '-'
+if cancelled then
+    redis.call('HSET', KEYS[1], ARGV[1], '-' .. ARGV[2])
+else
+    redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+end
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+if cancelled then
+    return 1
+end
+return 0
+`)
+
+// liveExecutionsScript 清理已过期的执行并返回未过期的执行数，ARGV[2] 为 cancel 时同时标记为取消
+var liveExecutionsScript = redis.NewScript(`
+local count = 0
+local entries = redis.call('HGETALL', KEYS[1])
+for i = 1, #entries, 2 do
+    local value = entries[i + 1]
+    if math.abs(tonumber(value)) <= tonumber(ARGV[1]) then
+        redis.call('HDEL', KEYS[1], entries[i])
+    else
+        if ARGV[2] == 'cancel' and string.sub(value, 1, 1) ~= '-' then
+            redis.call('HSET', KEYS[1], entries[i], '-' .. value)
+        end
+        count = count + 1
+    end
+end
+return count
+`)
+
+// RedisTaskLocker 基于 Redis SETNX 的锁实现，同时实现 TaskRunRegistry
+type RedisTaskLocker struct {
+	client *redis.Client
+}
+
+// NewRedisTaskLocker 创建 Redis 锁
+func NewRedisTaskLocker(client *redis.Client) *RedisTaskLocker {
+	return &RedisTaskLocker{client: client}
+}
+
+// TryLock 尝试获取锁，获取成功返回 true
+// 锁在任务结束后不会主动释放，依赖 TTL 过期，防止时钟略有偏差的节点重复执行同一次触发
+func (l *RedisTaskLocker) TryLock(ctx context.Context, key string, owner string, ttl time.Duration) (bool, error) {
+	return l.client.SetNX(ctx, key, owner, ttl).Result()
+}
+
+// GetTaskFireLockKey 按任务ID和计划触发时间（秒）生成锁键
+func GetTaskFireLockKey(taskID int, fireTime time.Time) string {
+	return fmt.Sprintf(TaskFireLockKeyPrefix, taskID, fireTime.Unix())
+}
+
+// Claim implements TaskRunRegistry.
+func (l *RedisTaskLocker) Claim(ctx context.Context, key string, executionID string, ttl time.Duration, replace bool) (bool, error) {
+	if replace {
+		return true, l.client.Set(ctx, key, executionID, ttl).Err()
+	}
+	return l.client.SetNX(ctx, key, executionID, ttl).Result()
+}
+
+// Refresh implements TaskRunRegistry.
+func (l *RedisTaskLocker) Refresh(ctx context.Context, key string, executionID string, ttl time.Duration) (bool, error) {
+	refreshed, err := refreshRunningScript.Run(ctx, l.client, []string{key}, executionID, ttl.Milliseconds()).Int()
+	return refreshed == 1, err
+}
+
+// Release implements TaskRunRegistry.
+func (l *RedisTaskLocker) Release(ctx context.Context, key string, executionID string) error {
+	return releaseRunningScript.Run(ctx, l.client, []string{key}, executionID).Err()
+}
+
+// Register implements TaskRunRegistry.
+func (l *RedisTaskLocker) Register(ctx context.Context, key string, executionID string, ttl time.Duration) error {
+	_, err := l.Heartbeat(ctx, key, executionID, ttl)
+	return err
+}
+
+// Heartbeat implements TaskRunRegistry.
+func (l *RedisTaskLocker) Heartbeat(ctx context.Context, key string, executionID string, ttl time.Duration) (bool, error) {
+	deadline := time.Now().Add(ttl).UnixMilli()
+	cancelled, err := heartbeatExecutionScript.Run(ctx, l.client, []string{key}, executionID, deadline, ttl.Milliseconds()).Int()
+	return cancelled == 1, err
+}
+
+// Unregister implements TaskRunRegistry.
+func (l *RedisTaskLocker) Unregister(ctx context.Context, key string, executionID string) error {
+	return l.client.HDel(ctx, key, executionID).Err()
+}
+
+// Cancel implements TaskRunRegistry.
+func (l *RedisTaskLocker) Cancel(ctx context.Context, key string) (int, error) {
+	return liveExecutionsScript.Run(ctx, l.client, []string{key}, time.Now().UnixMilli(), "cancel").Int()
+}
+
+// Executions implements TaskRunRegistry.
+func (l *RedisTaskLocker) Executions(ctx context.Context, key string) (int, error) {
+	return liveExecutionsScript.Run(ctx, l.client, []string{key}, time.Now().UnixMilli(), "").Int()
+}
+
+// GetTaskRunningKey 任务当前执行的登记键
+func GetTaskRunningKey(taskID int) string {
+	return fmt.Sprintf(TaskRunningKeyPrefix, taskID)
+}
+
+// GetTaskExecutionsKey 任务所有执行的登记键
+func GetTaskExecutionsKey(taskID int) string {
+	return fmt.Sprintf(TaskExecutionsKeyPrefix, taskID)
+}
+
+// GetTaskFanInLockKey 按下游任务ID和触发它的一组上游执行生成锁键
+func GetTaskFanInLockKey(taskID int, executionSet string) string {
+	sum := sha1.Sum([]byte(executionSet))
+	return fmt.Sprintf(TaskFanInLockKeyPrefix, taskID, hex.EncodeToString(sum[:]))
+}
@@ -0,0 +1,107 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gbrayhan/microservices-go/src/domain"
+	domainScheduledTask "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
+	domainTaskExecutionLog "github.com/gbrayhan/microservices-go/src/domain/sys/task_execution_log"
+	wsHandler "github.com/gbrayhan/microservices-go/src/infrastructure/ws/handler/task_execution_log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeLocker struct {
+	mutex sync.Mutex
+	keys  []string
+	err   error
+}
+
+func (l *fakeLocker) TryLock(ctx context.Context, key string, owner string, ttl time.Duration) (bool, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.keys = append(l.keys, key)
+	return l.err == nil, l.err
+}
+
+type fakeExecutionLogRepository struct {
+	mutex sync.Mutex
+	logs  []domainTaskExecutionLog.TaskExecutionLog
+}
+
+func (r *fakeExecutionLogRepository) GetAll() (*[]domainTaskExecutionLog.TaskExecutionLog, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	logs := append([]domainTaskExecutionLog.TaskExecutionLog(nil), r.logs...)
+	return &logs, nil
+}
+
+func (r *fakeExecutionLogRepository) Create(logDomain *domainTaskExecutionLog.TaskExecutionLog) (*domainTaskExecutionLog.TaskExecutionLog, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	logDomain.ID = len(r.logs) + 1
+	r.logs = append(r.logs, *logDomain)
+	return logDomain, nil
+}
+
+func (r *fakeExecutionLogRepository) GetByID(id int) (*domainTaskExecutionLog.TaskExecutionLog, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *fakeExecutionLogRepository) Update(id int, apiMap map[string]interface{}) (*domainTaskExecutionLog.TaskExecutionLog, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *fakeExecutionLogRepository) Delete(ids []int) error {
+	return nil
+}
+
+func (r *fakeExecutionLogRepository) SearchPaginated(filters domain.DataFilters) (*domain.PaginatedResult[domainTaskExecutionLog.TaskExecutionLog], error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *fakeExecutionLogRepository) SearchByProperty(property string, searchText string) (*[]string, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *fakeExecutionLogRepository) GetByTaskID(taskID uint, limit int) (*[]domainTaskExecutionLog.TaskExecutionLog, error) {
+	return r.GetAll()
+}
+
+func (r *fakeExecutionLogRepository) GetSince(taskID uint, since time.Time, limit int) (*[]domainTaskExecutionLog.TaskExecutionLog, error) {
+	return r.GetAll()
+}
+
+func (r *fakeExecutionLogRepository) PurgeBefore(before time.Time) (int64, error) {
+	return 0, nil
+}
+
+// withExecutionLog 让测试调度器可以写执行日志
+func withExecutionLog(t *testing.T, s *TaskScheduler) *fakeExecutionLogRepository {
+	t.Helper()
+	repo := &fakeExecutionLogRepository{}
+	s.taskExecutionLogRepo = repo
+	s.wsHandler = wsHandler.NewLogHandler(nil, s.logger)
+	return repo
+}
+
+func TestFireLockErrorRecordsSkipped(t *testing.T) {
+	s := newTestScheduler(t)
+	logs := withExecutionLog(t, s)
+	locker := &fakeLocker{err: errors.New("redis unavailable")}
+	s.locker = locker
+
+	fireTime := time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC)
+	task := &domainScheduledTask.ScheduledTask{ID: 3, TaskName: "report"}
+	s.executeTask(task, "exec-1", fireScheduled, fireTime)
+
+	require.Equal(t, []string{GetTaskFireLockKey(3, fireTime)}, locker.keys)
+	require.Len(t, logs.logs, 1)
+	assert.Equal(t, domainTaskExecutionLog.ExecuteResultSkipped, logs.logs[0].ExecuteResult)
+	assert.Equal(t, "exec-1", logs.logs[0].ExecutionID)
+	assert.Contains(t, logs.logs[0].ErrorMessage, "redis unavailable")
+}
@@ -19,5 +19,5 @@ type TaskManager interface {
 
 	// 状态查询
 	GetTaskStatus(taskID int) (bool, error)
-	ListAllTasks() map[int]bool
+	ListAllTasks() map[int]string
 }
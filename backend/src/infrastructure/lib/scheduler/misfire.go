@@ -0,0 +1,129 @@
+// scheduler/misfire.go
+package scheduler
+
+import (
+	"time"
+
+	domainScheduledTask "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
+	"github.com/go-co-op/gocron"
+	"go.uber.org/zap"
+)
+
+// 启动时刚好到点的触发由 gocron 执行，不算错过
+const misfireGracePeriod = 5 * time.Second
+
+// 计算计划触发时间时向前回看的范围，gocron 延迟超过该值时退回到当前秒
+const fireTimeLookback = time.Minute
+
+// scheduledFireTime 本次 cron 触发对应的计划时间，即不晚于 now 的最近一个触发点。
+// 各节点按计划时间而不是本地时钟竞争锁，时钟偏差或跨秒抖动不会产生不同的锁键
+func (s *TaskScheduler) scheduledFireTime(task *domainScheduledTask.ScheduledTask, now time.Time) time.Time {
+	fallback := now.Truncate(time.Second)
+	schedule, err := ParseCronExpression(task.CronExpression, s.TaskLocation(task))
+	if err != nil {
+		return fallback
+	}
+	var fireTime time.Time
+	for next := schedule.Next(now.Add(-fireTimeLookback)); !next.IsZero() && !next.After(now); next = schedule.Next(next) {
+		fireTime = next
+	}
+	if fireTime.IsZero() {
+		return fallback
+	}
+	return fireTime
+}
+
+// nextRunTime 计算任务下次触发时间，优先使用 gocron job 已排定的时间
+func (s *TaskScheduler) nextRunTime(task *domainScheduledTask.ScheduledTask, job *gocron.Job) (time.Time, bool) {
+	now := time.Now()
+	if job != nil {
+		if next := job.NextRun(); next.After(now) {
+			return next, true
+		}
+	}
+	schedule, err := ParseCronExpression(task.CronExpression, s.TaskLocation(task))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return schedule.Next(now), true
+}
+
+// saveNextExecuteTime 持久化任务的下次触发时间
+func (s *TaskScheduler) saveNextExecuteTime(task *domainScheduledTask.ScheduledTask, job *gocron.Job) {
+	next, ok := s.nextRunTime(task, job)
+	if !ok {
+		return
+	}
+	updateData := map[string]interface{}{
+		"next_execute_time": next,
+	}
+	if _, err := s.repo.Update(task.ID, updateData); err != nil {
+		s.logger.Error("Failed to update next execute time",
+			zap.Int("task_id", task.ID),
+			zap.Error(err))
+	}
+}
+
+// saveAllNextExecuteTimes 调度器启动后写入所有任务的下次触发时间
+func (s *TaskScheduler) saveAllNextExecuteTimes() {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for taskID, job := range s.tasks {
+		next := job.NextRun()
+		if next.IsZero() {
+			continue
+		}
+		updateData := map[string]interface{}{
+			"next_execute_time": next,
+		}
+		if _, err := s.repo.Update(taskID, updateData); err != nil {
+			s.logger.Error("Failed to update next execute time",
+				zap.Int("task_id", taskID),
+				zap.Error(err))
+		}
+	}
+}
+
+// checkMisfire 检查服务停机期间是否错过触发，fire_once 策略下补执行一次，否则跳过
+func (s *TaskScheduler) checkMisfire(task *domainScheduledTask.ScheduledTask) {
+	missedAt, missed := s.missedFireTime(task, time.Now())
+	if !missed {
+		return
+	}
+
+	if task.MisfirePolicy != domainScheduledTask.MisfirePolicyFireOnce {
+		s.logger.Warn("Task missed scheduled fire, skipping",
+			zap.Int("task_id", task.ID),
+			zap.String("task_name", task.TaskName),
+			zap.Time("missed_at", missedAt))
+		return
+	}
+
+	// 多副本同时启动时，executeTask 按错过的触发时间加锁，只补执行一次
+	s.logger.Warn("Task missed scheduled fire, firing once",
+		zap.Int("task_id", task.ID),
+		zap.String("task_name", task.TaskName),
+		zap.Time("missed_at", missedAt))
+	go s.executeTask(task, newExecutionID(), fireScheduled, missedAt)
+}
+
+// missedFireTime 根据上次执行时间（从未执行时为创建时间）计算最早错过的触发时间
+func (s *TaskScheduler) missedFireTime(task *domainScheduledTask.ScheduledTask, now time.Time) (time.Time, bool) {
+	reference := task.LastExecuteTime
+	if reference.IsZero() {
+		reference = task.CreatedAt
+	}
+	if reference.IsZero() {
+		return time.Time{}, false
+	}
+	schedule, err := ParseCronExpression(task.CronExpression, s.TaskLocation(task))
+	if err != nil {
+		return time.Time{}, false
+	}
+	missedAt := schedule.Next(reference)
+	if missedAt.IsZero() || !missedAt.Before(now.Add(-misfireGracePeriod)) {
+		return time.Time{}, false
+	}
+	return missedAt, true
+}
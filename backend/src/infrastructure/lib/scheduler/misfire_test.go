@@ -0,0 +1,66 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	domainScheduledTask "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCronExpression(t *testing.T) {
+	from := time.Date(2024, 5, 1, 8, 0, 30, 0, time.UTC)
+
+	schedule, err := ParseCronExpression("*/15 * * * *", nil)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 1, 8, 15, 0, 0, time.UTC), schedule.Next(from))
+
+	schedule, err = ParseCronExpression("0 0 9 * * *", time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC), schedule.Next(from))
+
+	_, err = ParseCronExpression("not a cron", nil)
+	assert.Error(t, err)
+}
+
+func TestMissedFireTime(t *testing.T) {
+	s := &TaskScheduler{location: time.UTC}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	task := &domainScheduledTask.ScheduledTask{
+		CronExpression:  "0 * * * *",
+		LastExecuteTime: time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC),
+	}
+
+	missedAt, missed := s.missedFireTime(task, now)
+	assert.True(t, missed)
+	assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), missedAt)
+
+	task.LastExecuteTime = time.Date(2024, 5, 1, 11, 0, 0, 0, time.UTC)
+	_, missed = s.missedFireTime(task, now)
+	assert.False(t, missed, "fire due right now is left to gocron")
+
+	task.LastExecuteTime = time.Time{}
+	task.CreatedAt = time.Date(2024, 5, 1, 11, 30, 0, 0, time.UTC)
+	_, missed = s.missedFireTime(task, now)
+	assert.False(t, missed)
+}
+
+func TestScheduledFireTimeIgnoresLocalJitter(t *testing.T) {
+	s := &TaskScheduler{location: time.UTC}
+	task := &domainScheduledTask.ScheduledTask{CronExpression: "*/5 * * * *"}
+	fire := time.Date(2024, 5, 1, 12, 5, 0, 0, time.UTC)
+
+	// 两个节点分别在计划时间后 10ms 和 1.2s 触发，锁键相同
+	assert.Equal(t, fire, s.scheduledFireTime(task, fire.Add(10*time.Millisecond)))
+	assert.Equal(t, fire, s.scheduledFireTime(task, fire.Add(1200*time.Millisecond)))
+	assert.Equal(t, GetTaskFireLockKey(1, fire), GetTaskFireLockKey(1, s.scheduledFireTime(task, fire.Add(999*time.Millisecond))))
+
+	secondly := &domainScheduledTask.ScheduledTask{CronExpression: "*/10 * * * * *"}
+	assert.Equal(t, fire.Add(10*time.Second), s.scheduledFireTime(secondly, fire.Add(10*time.Second+300*time.Millisecond)))
+
+	// 延迟超过回看范围时退回到当前秒
+	late := fire.Add(2*time.Minute + 500*time.Millisecond)
+	daily := &domainScheduledTask.ScheduledTask{CronExpression: "5 12 * * *"}
+	assert.Equal(t, late.Truncate(time.Second), s.scheduledFireTime(daily, late))
+}
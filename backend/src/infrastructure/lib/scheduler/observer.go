@@ -0,0 +1,34 @@
+// scheduler/observer.go
+package scheduler
+
+import (
+	"time"
+
+	domainScheduledTask "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
+	"go.uber.org/zap"
+)
+
+// ExecutionObserver 任务一次触发（含重试）执行结束后的回调，用于统计、告警等
+type ExecutionObserver interface {
+	OnExecutionFinished(task *domainScheduledTask.ScheduledTask, executionID string, duration time.Duration, err error)
+}
+
+// SetExecutionObserver 设置执行结束回调
+func (s *TaskScheduler) SetExecutionObserver(observer ExecutionObserver) {
+	s.observer = observer
+}
+
+// notifyExecutionFinished 通知执行结束，回调 panic 不影响调度
+func (s *TaskScheduler) notifyExecutionFinished(task *domainScheduledTask.ScheduledTask, executionID string, duration time.Duration, err error) {
+	if s.observer == nil {
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("Execution observer panicked",
+				zap.Int("task_id", task.ID),
+				zap.Any("panic", r))
+		}
+	}()
+	s.observer.OnExecutionFinished(task, executionID, duration, err)
+}
@@ -0,0 +1,21 @@
+// scheduler/output.go
+package scheduler
+
+import "unicode/utf8"
+
+const outputTruncatedSuffix = "\n...(truncated)"
+
+// truncateOutput 将输出截断到 limit 字节以内，不会截断多字节字符
+func truncateOutput(output string, limit int) string {
+	if limit <= 0 || len(output) <= limit {
+		return output
+	}
+	cut := limit - len(outputTruncatedSuffix)
+	if cut < 0 {
+		cut = 0
+	}
+	for cut > 0 && !utf8.RuneStart(output[cut]) {
+		cut--
+	}
+	return output[:cut] + outputTruncatedSuffix
+}
@@ -0,0 +1,24 @@
+package scheduler
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncateOutput(t *testing.T) {
+	assert.Equal(t, "short", truncateOutput("short", 64))
+	assert.Equal(t, "unlimited", truncateOutput("unlimited", 0))
+
+	long := strings.Repeat("a", 100)
+	truncated := truncateOutput(long, 40)
+	assert.LessOrEqual(t, len(truncated), 40)
+	assert.True(t, strings.HasSuffix(truncated, outputTruncatedSuffix))
+
+	chinese := strings.Repeat("任务输出", 20)
+	truncated = truncateOutput(chinese, 41)
+	assert.LessOrEqual(t, len(truncated), 41)
+	assert.True(t, utf8.ValidString(truncated))
+}
@@ -0,0 +1,211 @@
+// scheduler/pause.go
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	domainScheduledTask "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
+	scheduleTaskConstants "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task/constants"
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+)
+
+// fireSource 触发来源
+type fireSource int
+
+const (
+	fireScheduled  fireSource = iota // cron 触发和错过补执行，按计划时间加锁，受暂停和停止窗口限制
+	fireDependency                   // 上游触发，只发生在执行上游的节点上，不加锁，受暂停和停止窗口限制
+	fireManual                       // 手动执行，执行ID唯一，不加锁，不受暂停和停止窗口限制
+)
+
+// isSchedulable 启用和暂停的任务需要保持调度，暂停期间的触发记为跳过
+func isSchedulable(task *domainScheduledTask.ScheduledTask) bool {
+	value := strconv.Itoa(task.Status)
+	return task.Paused || value == scheduleTaskConstants.TaskStatusEnabled || value == scheduleTaskConstants.TaskStatusPaused
+}
+
+// ParseBlackoutWindows 解析并校验任务的停止窗口
+func ParseBlackoutWindows(data datatypes.JSON) ([]domainScheduledTask.BlackoutWindow, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+	var windows []domainScheduledTask.BlackoutWindow
+	if err := json.Unmarshal(data, &windows); err != nil {
+		return nil, fmt.Errorf("blackout_windows must be an array of {weekdays, start, end}: %w", err)
+	}
+	for i, window := range windows {
+		for _, day := range window.Weekdays {
+			if day < 0 || day > 6 {
+				return nil, fmt.Errorf("blackout_windows[%d].weekdays: %d is not between 0 (Sunday) and 6 (Saturday)", i, day)
+			}
+		}
+		if (window.Start == "") != (window.End == "") {
+			return nil, fmt.Errorf("blackout_windows[%d]: start and end must be set together", i)
+		}
+		if window.Start == "" {
+			continue
+		}
+		start, err := parseClock(window.Start)
+		if err != nil || start >= 24*60 {
+			return nil, fmt.Errorf("blackout_windows[%d].start: %q is not a valid HH:MM time", i, window.Start)
+		}
+		end, err := parseClock(window.End)
+		if err != nil {
+			return nil, fmt.Errorf("blackout_windows[%d].end: %q is not a valid HH:MM time", i, window.End)
+		}
+		if start == end {
+			return nil, fmt.Errorf("blackout_windows[%d]: start and end must differ", i)
+		}
+	}
+	return windows, nil
+}
+
+// inBlackoutWindow 判断 t（已转换到任务时区）是否落在某个停止窗口内
+func inBlackoutWindow(windows []domainScheduledTask.BlackoutWindow, t time.Time) (domainScheduledTask.BlackoutWindow, bool) {
+	minute := t.Hour()*60 + t.Minute()
+	weekday := int(t.Weekday())
+	previousDay := (weekday + 6) % 7
+	for _, window := range windows {
+		if window.Start == "" {
+			if matchWeekday(window.Weekdays, weekday) {
+				return window, true
+			}
+			continue
+		}
+		start, _ := parseClock(window.Start)
+		end, _ := parseClock(window.End)
+		if start < end {
+			if matchWeekday(window.Weekdays, weekday) && minute >= start && minute < end {
+				return window, true
+			}
+			continue
+		}
+		// 跨过午夜的窗口属于开始那一天
+		if (matchWeekday(window.Weekdays, weekday) && minute >= start) ||
+			(matchWeekday(window.Weekdays, previousDay) && minute < end) {
+			return window, true
+		}
+	}
+	return domainScheduledTask.BlackoutWindow{}, false
+}
+
+func matchWeekday(weekdays []int, weekday int) bool {
+	if len(weekdays) == 0 {
+		return true
+	}
+	for _, day := range weekdays {
+		if day == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// parseClock 解析 HH:MM 为当天的分钟数，允许 24:00 表示一天结束
+func parseClock(value string) (int, error) {
+	hourText, minuteText, ok := strings.Cut(value, ":")
+	if !ok || len(minuteText) != 2 {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+	hour, err := strconv.Atoi(hourText)
+	if err != nil {
+		return 0, err
+	}
+	minute, err := strconv.Atoi(minuteText)
+	if err != nil {
+		return 0, err
+	}
+	if hour < 0 || minute < 0 || minute > 59 || hour > 24 || (hour == 24 && minute != 0) {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+	return hour*60 + minute, nil
+}
+
+func formatBlackoutWindow(window domainScheduledTask.BlackoutWindow) string {
+	days := "every day"
+	if len(window.Weekdays) > 0 {
+		names := make([]string, len(window.Weekdays))
+		for i, day := range window.Weekdays {
+			names[i] = cronWeekdayNames[day]
+		}
+		days = strings.Join(names, ",")
+	}
+	if window.Start == "" {
+		return days
+	}
+	return fmt.Sprintf("%s %s-%s", days, window.Start, window.End)
+}
+
+// checkPaused 计划触发前检查任务是否已暂停或处于停止窗口内，返回跳过原因
+// 使用库中最新的暂停标记而不是 status，暂停的任务被手动执行时 status 为运行中；暂停到期时自动恢复
+func (s *TaskScheduler) checkPaused(task *domainScheduledTask.ScheduledTask, now time.Time) (string, bool) {
+	if task.Paused {
+		if task.PausedUntil == nil || now.Before(*task.PausedUntil) {
+			reason := "skipped: task is paused"
+			if task.PausedUntil != nil {
+				reason += " until " + task.PausedUntil.In(s.TaskLocation(task)).Format(time.RFC3339)
+			}
+			return reason, true
+		}
+		s.autoResume(task)
+	}
+
+	windows, err := ParseBlackoutWindows(task.BlackoutWindows)
+	if err != nil {
+		s.logger.Warn("Invalid blackout windows, ignoring",
+			zap.Int("task_id", task.ID),
+			zap.Error(err))
+		return "", false
+	}
+	if window, ok := inBlackoutWindow(windows, now.In(s.TaskLocation(task))); ok {
+		return "skipped: inside blackout window " + formatBlackoutWindow(window), true
+	}
+	return "", false
+}
+
+// autoResume 暂停到期后恢复为启用状态，正在手动执行时 status 由执行结束时更新
+func (s *TaskScheduler) autoResume(task *domainScheduledTask.ScheduledTask) {
+	updateData := map[string]interface{}{
+		"paused":       false,
+		"paused_until": nil,
+	}
+	if strconv.Itoa(task.Status) == scheduleTaskConstants.TaskStatusPaused {
+		updateData["status"] = scheduleTaskConstants.TaskStatusEnabled
+		task.Status, _ = strconv.Atoi(scheduleTaskConstants.TaskStatusEnabled)
+	}
+	if _, err := s.repo.Update(task.ID, updateData); err != nil {
+		s.logger.Error("Failed to resume task after pause expired",
+			zap.Int("task_id", task.ID),
+			zap.Error(err))
+	}
+	task.Paused = false
+	task.PausedUntil = nil
+	s.logger.Info("Task pause expired, resumed", zap.Int("task_id", task.ID))
+}
+
+// latestTask 计划触发时读取库中最新的任务配置，失败时使用调度时的副本
+func (s *TaskScheduler) latestTask(task *domainScheduledTask.ScheduledTask) *domainScheduledTask.ScheduledTask {
+	current, err := s.repo.GetByID(task.ID)
+	if err != nil || current == nil || current.ID == 0 {
+		s.logger.Warn("Failed to reload task before fire, using scheduled copy",
+			zap.Int("task_id", task.ID),
+			zap.Error(err))
+		copied := *task
+		return &copied
+	}
+	return current
+}
+
+// isPausedInStore 执行结束时读取库中的暂停标记：执行前已暂停、执行期间被暂停或恢复都以库中为准
+func (s *TaskScheduler) isPausedInStore(task *domainScheduledTask.ScheduledTask) bool {
+	current, err := s.repo.GetByID(task.ID)
+	if err != nil || current == nil || current.ID == 0 {
+		return task.Paused
+	}
+	return current.Paused
+}
@@ -0,0 +1,151 @@
+package scheduler
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	domainScheduledTask "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
+	scheduleTaskConstants "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task/constants"
+	domainTaskExecutionLog "github.com/gbrayhan/microservices-go/src/domain/sys/task_execution_log"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/scheduled_task"
+	"github.com/go-co-op/gocron"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+func TestParseBlackoutWindows(t *testing.T) {
+	windows, err := ParseBlackoutWindows(datatypes.JSON(`[{"weekdays":[0,6]},{"start":"22:00","end":"06:00"}]`))
+	require.NoError(t, err)
+	assert.Len(t, windows, 2)
+
+	windows, err = ParseBlackoutWindows(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, windows)
+
+	_, err = ParseBlackoutWindows(datatypes.JSON(`[{"weekdays":[7]}]`))
+	assert.ErrorContains(t, err, "weekdays")
+	_, err = ParseBlackoutWindows(datatypes.JSON(`[{"start":"09:00"}]`))
+	assert.ErrorContains(t, err, "start and end must be set together")
+	_, err = ParseBlackoutWindows(datatypes.JSON(`[{"start":"25:00","end":"26:00"}]`))
+	assert.ErrorContains(t, err, "start")
+	_, err = ParseBlackoutWindows(datatypes.JSON(`{"weekdays":[0]}`))
+	assert.Error(t, err)
+}
+
+func TestInBlackoutWindow(t *testing.T) {
+	weekends := []domainScheduledTask.BlackoutWindow{{Weekdays: []int{0, 6}}}
+	// 2024-05-04 是周六
+	saturday := time.Date(2024, 5, 4, 12, 0, 0, 0, time.UTC)
+	monday := time.Date(2024, 5, 6, 12, 0, 0, 0, time.UTC)
+	_, in := inBlackoutWindow(weekends, saturday)
+	assert.True(t, in)
+	_, in = inBlackoutWindow(weekends, monday)
+	assert.False(t, in)
+
+	// 周五 22:00 到次日 06:00
+	fridayNight := []domainScheduledTask.BlackoutWindow{{Weekdays: []int{5}, Start: "22:00", End: "06:00"}}
+	_, in = inBlackoutWindow(fridayNight, time.Date(2024, 5, 3, 23, 0, 0, 0, time.UTC))
+	assert.True(t, in)
+	_, in = inBlackoutWindow(fridayNight, time.Date(2024, 5, 4, 5, 59, 0, 0, time.UTC))
+	assert.True(t, in)
+	_, in = inBlackoutWindow(fridayNight, time.Date(2024, 5, 4, 6, 0, 0, 0, time.UTC))
+	assert.False(t, in)
+	_, in = inBlackoutWindow(fridayNight, time.Date(2024, 5, 3, 5, 0, 0, 0, time.UTC))
+	assert.False(t, in, "early Friday belongs to Thursday's window")
+
+	lunch := []domainScheduledTask.BlackoutWindow{{Start: "12:00", End: "13:00"}}
+	_, in = inBlackoutWindow(lunch, monday)
+	assert.True(t, in)
+}
+
+func TestCheckPausedUntil(t *testing.T) {
+	s := &TaskScheduler{location: time.UTC}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	until := now.Add(time.Hour)
+	task := &domainScheduledTask.ScheduledTask{ID: 1, Status: 3, Paused: true, PausedUntil: &until}
+
+	reason, skipped := s.checkPaused(task, now)
+	assert.True(t, skipped)
+	assert.Equal(t, "skipped: task is paused until 2024-05-01T13:00:00Z", reason)
+
+	task.Status = 0
+	task.Paused = false
+	task.BlackoutWindows = datatypes.JSON(`[{"start":"11:00","end":"13:00"}]`)
+	reason, skipped = s.checkPaused(task, now)
+	assert.True(t, skipped)
+	assert.Equal(t, "skipped: inside blackout window every day 11:00-13:00", reason)
+}
+
+type fakeTaskRepository struct {
+	scheduled_task.IScheduledTaskRepository
+	mutex sync.Mutex
+	tasks map[int]domainScheduledTask.ScheduledTask
+}
+
+func (r *fakeTaskRepository) GetAll() (*[]domainScheduledTask.ScheduledTask, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	tasks := make([]domainScheduledTask.ScheduledTask, 0, len(r.tasks))
+	for _, task := range r.tasks {
+		tasks = append(tasks, task)
+	}
+	return &tasks, nil
+}
+
+func (r *fakeTaskRepository) GetByID(id int) (*domainScheduledTask.ScheduledTask, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	task := r.tasks[id]
+	return &task, nil
+}
+
+func (r *fakeTaskRepository) Update(id int, taskMap map[string]interface{}) (*domainScheduledTask.ScheduledTask, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	task := r.tasks[id]
+	if status, ok := taskMap["status"].(string); ok {
+		task.Status, _ = strconv.Atoi(status)
+	}
+	if paused, ok := taskMap["paused"].(bool); ok {
+		task.Paused = paused
+	}
+	r.tasks[id] = task
+	return &task, nil
+}
+
+func TestPausedTaskSkipsFireDuringManualRun(t *testing.T) {
+	s := newTestScheduler(t)
+	logs := withExecutionLog(t, s)
+	s.location = time.UTC
+	running, _ := strconv.Atoi(scheduleTaskConstants.TaskStatusRunning)
+	// 暂停的任务正在手动执行：status 为运行中，暂停标记仍在
+	repo := &fakeTaskRepository{tasks: map[int]domainScheduledTask.ScheduledTask{
+		5: {ID: 5, TaskName: "report", Status: running, Paused: true},
+	}}
+	s.repo = repo
+
+	s.executeTask(&domainScheduledTask.ScheduledTask{ID: 5, TaskName: "report"}, "exec-1", fireScheduled, time.Now())
+
+	require.Len(t, logs.logs, 1)
+	assert.Equal(t, domainTaskExecutionLog.ExecuteResultSkipped, logs.logs[0].ExecuteResult)
+	assert.Equal(t, "skipped: task is paused", logs.logs[0].ErrorMessage)
+	assert.True(t, s.isPausedInStore(&domainScheduledTask.ScheduledTask{ID: 5}), "manual run keeps the task paused")
+}
+
+func TestListAllTasksReadsPausedFromStore(t *testing.T) {
+	s := newTestScheduler(t)
+	s.tasks = map[int]*gocron.Job{1: nil, 2: nil}
+	// 在其他节点上暂停的任务
+	s.repo = &fakeTaskRepository{tasks: map[int]domainScheduledTask.ScheduledTask{
+		1: {ID: 1, Paused: true},
+		2: {ID: 2},
+	}}
+
+	assert.Equal(t, map[int]string{
+		1: scheduleTaskConstants.TaskStatusPaused,
+		2: scheduleTaskConstants.TaskStatusEnabled,
+	}, s.ListAllTasks())
+}
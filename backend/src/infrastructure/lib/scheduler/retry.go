@@ -0,0 +1,76 @@
+// scheduler/retry.go
+package scheduler
+
+import (
+	"strings"
+	"time"
+
+	domainScheduledTask "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/lib/executor"
+)
+
+const (
+	defaultRetryInterval = 10 * time.Second
+	maxRetryInterval     = 1 * time.Hour
+)
+
+// RetryPolicy 任务重试策略
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     string
+	Interval    time.Duration
+	RetryOn     []string
+}
+
+// NewRetryPolicy 根据任务配置生成重试策略
+func NewRetryPolicy(task *domainScheduledTask.ScheduledTask) RetryPolicy {
+	policy := RetryPolicy{
+		MaxAttempts: task.RetryMaxAttempts,
+		Backoff:     task.RetryBackoff,
+		Interval:    time.Duration(task.RetryIntervalSeconds) * time.Second,
+	}
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	if policy.Interval <= 0 {
+		policy.Interval = defaultRetryInterval
+	}
+	for _, keyword := range strings.Split(task.RetryOnErrors, ",") {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			policy.RetryOn = append(policy.RetryOn, keyword)
+		}
+	}
+	return policy
+}
+
+// ShouldRetry 判断第 attempt 次执行失败后是否需要重试
+func (p RetryPolicy) ShouldRetry(attempt int, err error) bool {
+	if err == nil || attempt >= p.MaxAttempts || executor.IsPermanent(err) {
+		return false
+	}
+	if len(p.RetryOn) == 0 {
+		return true
+	}
+	message := err.Error()
+	for _, keyword := range p.RetryOn {
+		if strings.Contains(message, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// Delay 计算第 attempt 次失败后的等待时间
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	if p.Backoff != domainScheduledTask.RetryBackoffExponential {
+		return p.Interval
+	}
+	delay := p.Interval
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= maxRetryInterval {
+			return maxRetryInterval
+		}
+	}
+	return delay
+}
@@ -0,0 +1,52 @@
+package scheduler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	domainScheduledTask "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/lib/executor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRetryPolicyDefaults(t *testing.T) {
+	policy := NewRetryPolicy(&domainScheduledTask.ScheduledTask{})
+
+	assert.Equal(t, 1, policy.MaxAttempts)
+	assert.Equal(t, defaultRetryInterval, policy.Interval)
+	assert.Empty(t, policy.RetryOn)
+	assert.False(t, policy.ShouldRetry(1, errors.New("boom")))
+}
+
+func TestRetryPolicyShouldRetry(t *testing.T) {
+	policy := NewRetryPolicy(&domainScheduledTask.ScheduledTask{
+		RetryMaxAttempts: 3,
+		RetryOnErrors:    "timeout, connection refused",
+	})
+
+	assert.True(t, policy.ShouldRetry(1, errors.New("dial tcp: connection refused")))
+	assert.True(t, policy.ShouldRetry(2, errors.New("context deadline exceeded (timeout)")))
+	assert.False(t, policy.ShouldRetry(3, errors.New("timeout")))
+	assert.False(t, policy.ShouldRetry(1, errors.New("invalid credentials")))
+	assert.False(t, policy.ShouldRetry(1, executor.NewPermanentError(errors.New("timeout"))))
+	assert.False(t, policy.ShouldRetry(1, nil))
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	fixed := NewRetryPolicy(&domainScheduledTask.ScheduledTask{
+		RetryBackoff:         domainScheduledTask.RetryBackoffFixed,
+		RetryIntervalSeconds: 5,
+	})
+	assert.Equal(t, 5*time.Second, fixed.Delay(1))
+	assert.Equal(t, 5*time.Second, fixed.Delay(4))
+
+	exponential := NewRetryPolicy(&domainScheduledTask.ScheduledTask{
+		RetryBackoff:         domainScheduledTask.RetryBackoffExponential,
+		RetryIntervalSeconds: 5,
+	})
+	assert.Equal(t, 5*time.Second, exponential.Delay(1))
+	assert.Equal(t, 10*time.Second, exponential.Delay(2))
+	assert.Equal(t, 20*time.Second, exponential.Delay(3))
+	assert.Equal(t, maxRetryInterval, exponential.Delay(20))
+}
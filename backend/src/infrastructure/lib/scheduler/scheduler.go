@@ -2,6 +2,8 @@
 package scheduler
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -9,17 +11,19 @@ import (
 
 	"sync"
 
-	"github.com/gbrayhan/microservices-go/src/domain"
 	domainScheduledTask "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
 	scheduleTaskConstants "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task/constants"
 	domainTaskExecutionLog "github.com/gbrayhan/microservices-go/src/domain/sys/task_execution_log"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/lib/executor"
 	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/scheduled_task"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/task_dependency"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/task_execution_log"
 	wsHandler "github.com/gbrayhan/microservices-go/src/infrastructure/ws/handler/task_execution_log"
 	"github.com/go-co-op/gocron"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
+	"gorm.io/datatypes"
 )
 
 type TaskScheduler struct {
@@ -29,9 +33,23 @@ type TaskScheduler struct {
 	tasks                map[int]*gocron.Job
 	executor             *executor.TaskExecutorManager
 	mutex                sync.RWMutex
-	taskWg               map[int]*sync.WaitGroup
 	taskExecutionLogRepo task_execution_log.ITaskExecutionLogRepository
+	dependencyRepo       task_dependency.ITaskDependencyRepository
 	wsHandler            *wsHandler.LogHandler
+	locker               TaskLocker
+	runRegistry          TaskRunRegistry
+	lockTTL              time.Duration
+	instanceID           string
+	defaultTimeout       time.Duration
+	maxOutputBytes       int
+	location             *time.Location
+	ctx                  context.Context
+	cancel               context.CancelFunc
+	running              map[int]*runningTask // 正在执行的任务，用于并发控制和取消
+	stopping             map[int]bool         // 正在 StopTask 中等待执行结束的任务，不再接受新的执行
+	runningWg            sync.WaitGroup
+	workers              chan struct{} // 全局工作池，限制同时执行的任务数
+	observer             ExecutionObserver
 }
 
 func NewTaskScheduler(
@@ -39,10 +57,16 @@ func NewTaskScheduler(
 	logger *logger.Logger,
 	executor *executor.TaskExecutorManager,
 	taskExecutionLogRepo task_execution_log.ITaskExecutionLogRepository,
+	dependencyRepo task_dependency.ITaskDependencyRepository,
+	locker TaskLocker,
+	runRegistry TaskRunRegistry,
 ) *TaskScheduler {
-	// 创建支持秒级的调度器
-	scheduler := gocron.NewScheduler(time.UTC)
-	scheduler.SetMaxConcurrentJobs(10, gocron.RescheduleMode)
+	// 创建支持秒级的调度器，任务未配置时区时使用 SCHEDULER_DEFAULT_TIMEZONE
+	location := resolveDefaultLocation(logger)
+	scheduler := gocron.NewScheduler(location)
+
+	// 根上下文，Stop 时取消所有正在执行的任务
+	ctx, cancel := context.WithCancel(context.Background())
 
 	return &TaskScheduler{
 		scheduler:            scheduler,
@@ -51,43 +75,99 @@ func NewTaskScheduler(
 		tasks:                make(map[int]*gocron.Job),
 		executor:             executor,
 		taskExecutionLogRepo: taskExecutionLogRepo,
+		dependencyRepo:       dependencyRepo,
+		locker:               locker,
+		runRegistry:          runRegistry,
+		lockTTL:              resolveLockTTL(),
+		instanceID:           resolveInstanceID(),
+		defaultTimeout:       resolveDefaultTimeout(),
+		maxOutputBytes:       resolveMaxOutputBytes(),
+		location:             location,
+		ctx:                  ctx,
+		cancel:               cancel,
+		running:              make(map[int]*runningTask),
+		stopping:             make(map[int]bool),
+		workers:              make(chan struct{}, resolveWorkerPoolSize()),
 	}
 }
 
+// InstanceID 当前调度节点标识
+func (s *TaskScheduler) InstanceID() string {
+	return s.instanceID
+}
+
+// Location 调度器默认时区
+func (s *TaskScheduler) Location() *time.Location {
+	return s.location
+}
+
+// TaskLocation 任务的时区，未配置或无法识别时使用调度器默认时区
+func (s *TaskScheduler) TaskLocation(task *domainScheduledTask.ScheduledTask) *time.Location {
+	if task.Timezone != "" {
+		if loc, err := LoadTimezone(task.Timezone); err == nil {
+			return loc
+		}
+		s.logger.Warn("Invalid task timezone, using default",
+			zap.Int("task_id", task.ID),
+			zap.String("timezone", task.Timezone))
+	}
+	return s.location
+}
+
 func (s *TaskScheduler) SetWsHandler(handler *wsHandler.LogHandler) {
 	s.wsHandler = handler
 }
 
 func (s *TaskScheduler) Start() {
-	s.loadTasks()
+	s.loadTasks(true)
 	s.scheduler.StartAsync()
-	s.logger.Info("Task scheduler started")
+	s.saveAllNextExecuteTimes()
+	s.logger.Info("Task scheduler started", zap.String("instance_id", s.instanceID))
 }
 
 func (s *TaskScheduler) Stop() {
 	s.scheduler.Stop()
+	// 取消所有正在执行的任务，并等待其写完执行日志
+	s.cancel()
+	done := make(chan struct{})
+	go func() {
+		s.runningWg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		s.logger.Warn("Timed out waiting for running tasks to stop")
+	}
 	s.logger.Info("Task scheduler stopped")
 }
-func (s *TaskScheduler) loadTasks() {
-	filters := domain.DataFilters{
-		Matches: map[string][]string{
-			"status": {scheduleTaskConstants.TaskStatusEnabled}, // 只加载启用的任务
-		},
-	}
 
-	result, err := s.repo.SearchPaginated(filters)
+// loadTasks 加载所有启用的任务，checkMisfire 为 true 时（服务启动）按策略处理停机期间错过的触发
+func (s *TaskScheduler) loadTasks(checkMisfire bool) {
+	tasks, err := s.repo.GetAll()
 	if err != nil {
 		s.logger.Error("Failed to load tasks", zap.Error(err))
 		return
 	}
 
+	var loaded []*domainScheduledTask.ScheduledTask
 	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
-	for _, task := range *result.Data {
+	for _, task := range *tasks {
+		// 只加载启用和暂停的任务
+		if !isSchedulable(&task) {
+			continue
+		}
 		// 为每个任务创建本地副本以避免闭包问题
 		taskCopy := task
 		s.addTaskToScheduleInternal(&taskCopy)
+		loaded = append(loaded, &taskCopy)
+	}
+	s.mutex.Unlock()
+
+	if checkMisfire {
+		for _, task := range loaded {
+			s.checkMisfire(task)
+		}
 	}
 }
 
@@ -95,7 +175,7 @@ func (s *TaskScheduler) loadTasks() {
 func (s *TaskScheduler) addTaskToScheduleInternal(task *domainScheduledTask.ScheduledTask) {
 	// 创建一个闭包来捕获当前任务
 	taskFunc := func() {
-		s.executeTask(task)
+		s.executeTask(task, newExecutionID(), fireScheduled, s.scheduledFireTime(task, time.Now()))
 	}
 
 	// 使用gocron解析cron表达式并调度任务
@@ -103,14 +183,20 @@ func (s *TaskScheduler) addTaskToScheduleInternal(task *domainScheduledTask.Sche
 	var job *gocron.Job
 	var err error
 
+	// 按任务时区解析，表达式自带 CRON_TZ 时以表达式为准
+	expression := task.CronExpression
+	if len(cronFields(expression)) == len(strings.Fields(expression)) {
+		expression = fmt.Sprintf("CRON_TZ=%s %s", s.TaskLocation(task).String(), expression)
+	}
+
 	// 检查cron表达式的字段数
-	fields := strings.Fields(task.CronExpression)
+	fields := cronFields(task.CronExpression)
 	if len(fields) == 6 {
 		// 6字段表达式，使用秒级解析
-		job, err = s.scheduler.CronWithSeconds(task.CronExpression).Do(taskFunc)
+		job, err = s.scheduler.CronWithSeconds(expression).Do(taskFunc)
 	} else {
 		// 标准5字段表达式
-		job, err = s.scheduler.Cron(task.CronExpression).Do(taskFunc)
+		job, err = s.scheduler.Cron(expression).Do(taskFunc)
 	}
 
 	if err != nil {
@@ -127,6 +213,11 @@ func (s *TaskScheduler) addTaskToScheduleInternal(task *domainScheduledTask.Sche
 		zap.Int("task_id", task.ID),
 		zap.String("task_name", task.TaskName),
 		zap.String("cron", task.CronExpression))
+
+	// 调度器未启动时 job 还没有下次执行时间，由 Start 统一写入
+	if s.scheduler.IsRunning() {
+		s.saveNextExecuteTime(task, job)
+	}
 }
 
 // 公共方法，加锁
@@ -138,30 +229,77 @@ func (s *TaskScheduler) addTaskToSchedule(task *domainScheduledTask.ScheduledTas
 	return nil
 }
 
-func (s *TaskScheduler) executeTask(task *domainScheduledTask.ScheduledTask) {
-	// 初始化 WaitGroup
-	s.mutex.Lock()
-	if s.taskWg == nil {
-		s.taskWg = make(map[int]*sync.WaitGroup)
+// acquireFireLock 多副本部署时保证同一次计划触发只有一个节点执行。
+// 被其他节点抢到时由该节点写执行日志；无法确认锁状态时不执行，并记为跳过，避免触发无声丢失
+func (s *TaskScheduler) acquireFireLock(task *domainScheduledTask.ScheduledTask, executionID string, fireTime time.Time) bool {
+	if s.locker == nil {
+		return true
 	}
-	wg := &sync.WaitGroup{}
-	wg.Add(1)
-	s.taskWg[task.ID] = wg
-	s.mutex.Unlock()
 
-	// 确保在函数结束时调用 Done
-	defer func() {
-		s.mutex.Lock()
-		if wg, exists := s.taskWg[task.ID]; exists {
-			wg.Done()
-			delete(s.taskWg, task.ID)
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	acquired, err := s.locker.TryLock(ctx, GetTaskFireLockKey(task.ID, fireTime), s.instanceID, s.lockTTL)
+	if err != nil {
+		s.logger.Error("Failed to acquire task lock, skipping fire",
+			zap.Int("task_id", task.ID),
+			zap.String("instance_id", s.instanceID),
+			zap.Error(err))
+		s.recordSkipped(task, executionID, fmt.Sprintf("skipped: failed to acquire fire lock for %s: %v", fireTime.Format(time.RFC3339), err))
+		return false
+	}
+	if !acquired {
+		s.logger.Debug("Task fire already claimed by another instance",
+			zap.Int("task_id", task.ID),
+			zap.Time("fire_time", fireTime))
+	}
+	return acquired
+}
+
+// executeTask 执行一次任务触发，executionID 标识本次触发，所有重试的日志共用；
+// fireTime 为计划触发时间，各节点按它竞争同一把锁
+func (s *TaskScheduler) executeTask(task *domainScheduledTask.ScheduledTask, executionID string, source fireSource, fireTime time.Time) {
+	// 只有计划触发需要在节点间去重；手动和上游触发只在一个节点上发起
+	if source == fireScheduled && !s.acquireFireLock(task, executionID, fireTime) {
+		return
+	}
+
+	// 计划触发和上游触发使用库中最新的配置，任务已暂停或处于停止窗口内时记为跳过
+	if source != fireManual {
+		task = s.latestTask(task)
+		if reason, paused := s.checkPaused(task, time.Now()); paused {
+			s.recordSkipped(task, executionID, reason)
+			return
 		}
-		s.mutex.Unlock()
-	}()
+	}
+
+	// 按并发策略登记本次执行，上一次仍在执行（包括在其他节点上）时可能被跳过
+	runCtx, runCancel, err := s.beginRun(task, executionID)
+	if err != nil {
+		s.recordSkipped(task, executionID, "skipped: "+err.Error())
+		return
+	}
+	defer s.endRun(task.ID, executionID, runCancel)
+	release, err := s.registerRun(task, executionID, runCancel)
+	if err != nil {
+		s.recordSkipped(task, executionID, "skipped: "+err.Error())
+		return
+	}
+	defer release()
+	runCtx = executor.WithFireTime(runCtx, fireTime)
+
+	// 等待全局工作池的空位
+	if !s.acquireWorker(runCtx, task) {
+		s.recordSkipped(task, executionID, "skipped: cancelled while waiting for a free worker")
+		return
+	}
+	defer s.releaseWorker()
 
 	s.logger.Info("Executing task",
 		zap.Int("task_id", task.ID),
-		zap.String("task_name", task.TaskName))
+		zap.String("task_name", task.TaskName),
+		zap.String("execution_id", executionID),
+		zap.String("instance_id", s.instanceID))
 
 	// 更新任务状态为"运行中"
 	now := time.Now()
@@ -170,14 +308,42 @@ func (s *TaskScheduler) executeTask(task *domainScheduledTask.ScheduledTask) {
 		"last_execute_time": &now,
 	}
 
-	_, err := s.repo.Update(task.ID, updateData)
+	_, err = s.repo.Update(task.ID, updateData)
 	if err != nil {
 		s.logger.Error("Failed to update task status to running",
 			zap.Int("task_id", task.ID),
 			zap.Error(err))
 	}
-	// 执行任务
-	err = s.executor.Execute(task)
+	// 执行任务，失败时按重试策略重试，每次尝试单独记录日志
+	policy := NewRetryPolicy(task)
+	var parentLogID *int
+	for attempt := 1; ; attempt++ {
+		attemptStart := time.Now()
+		result, execErr := s.executeAttempt(runCtx, task)
+		err = execErr
+
+		taskLogData := s.recordExecution(task, executionID, attemptStart, attempt, parentLogID, result, err)
+		if parentLogID == nil && taskLogData != nil {
+			firstLogID := taskLogData.ID
+			parentLogID = &firstLogID
+		}
+		// Notify subscribers about the task execution log
+		s.wsHandler.NotifyLogToTaskSubscribers(task.ID, taskLogData)
+
+		if !policy.ShouldRetry(attempt, err) {
+			break
+		}
+		delay := policy.Delay(attempt)
+		s.logger.Warn("Task execution failed, retrying",
+			zap.Int("task_id", task.ID),
+			zap.Int("attempt", attempt),
+			zap.Int("max_attempts", policy.MaxAttempts),
+			zap.Duration("delay", delay),
+			zap.Error(err))
+		if !sleepWithContext(runCtx, delay) {
+			break
+		}
+	}
 
 	// 执行完成后，根据任务类型更新状态
 	// 对于周期性任务，执行完成后恢复为"启用"状态
@@ -188,63 +354,42 @@ func (s *TaskScheduler) executeTask(task *domainScheduledTask.ScheduledTask) {
 	isOneTimeTask := task.ExecType == scheduleTaskConstants.TaskExecOnetime
 
 	// 如果是一次性任务，则可以设置为已完成
-	if isOneTimeTask && err == nil { // 假设有这样的字段标识一次性任务
+	if isOneTimeTask && err == nil {
 		finalStatus = scheduleTaskConstants.TaskStatusCompleted // "5" 表示已完成
 	}
 
-	var taskLogData *domainTaskExecutionLog.TaskExecutionLog
-	duration := int(time.Since(now).Seconds())
 	if err != nil {
-		s.logger.Error("Task execution failed",
-			zap.Int("task_id", task.ID),
-			zap.String("task_name", task.TaskName),
-			zap.Error(err))
-
-		// 执行失败，更新状态为"错误"
+		// 重试耗尽仍失败，更新状态为"错误"
 		finalStatus = scheduleTaskConstants.TaskStatusError // "4" 表示错误
+	}
 
-		taskLogData, err = s.taskExecutionLogRepo.Create(&domainTaskExecutionLog.TaskExecutionLog{
-			TaskID:          uint(task.ID),
-			ExecuteTime:     now,
-			ExecuteDuration: &duration,
-			ErrorMessage:    err.Error(),
-			ExecuteResult:   0,
-		})
-		if err != nil {
-			s.logger.Error("Error creating task execution log",
-				zap.Int("task_id", task.ID),
-				zap.Error(err))
-		}
-	} else {
-		s.logger.Info("Task executed successfully",
-			zap.Int("task_id", task.ID),
-			zap.String("task_name", task.TaskName))
-
-		taskLogData, err = s.taskExecutionLogRepo.Create(&domainTaskExecutionLog.TaskExecutionLog{
-			TaskID:          uint(task.ID),
-			ExecuteTime:     now,
-			ExecuteDuration: &duration,
-			ErrorMessage:    "",
-			ExecuteResult:   1,
-		})
-		if err != nil {
-			s.logger.Error("Failed to create task execution log",
-				zap.Int("task_id", task.ID),
-				zap.String("task_name", task.TaskName),
-				zap.Error(err))
-		}
+	// 手动执行已禁用的任务，执行后保持禁用
+	if strconv.Itoa(task.Status) == scheduleTaskConstants.TaskStatusDisabled {
+		finalStatus = scheduleTaskConstants.TaskStatusDisabled
+	}
+
+	// 手动执行已暂停的任务，或执行期间任务被暂停，执行后保持暂停
+	if s.isPausedInStore(task) {
+		finalStatus = scheduleTaskConstants.TaskStatusPaused
 	}
 
 	// update result
 	updateData = map[string]interface{}{
 		"status": finalStatus,
 	}
+	if !isOneTimeTask && finalStatus != scheduleTaskConstants.TaskStatusDisabled {
+		s.mutex.RLock()
+		job := s.tasks[task.ID]
+		s.mutex.RUnlock()
+		if next, ok := s.nextRunTime(task, job); ok {
+			updateData["next_execute_time"] = next
+		}
+	}
 
-	_, err = s.repo.Update(task.ID, updateData)
-	if err != nil {
+	if _, updateErr := s.repo.Update(task.ID, updateData); updateErr != nil {
 		s.logger.Error("Failed to update task execution result",
 			zap.Int("task_id", task.ID),
-			zap.Error(err))
+			zap.Error(updateErr))
 	}
 
 	// If it is a one-time task, remove it from the scheduler after execution is completed.
@@ -259,8 +404,131 @@ func (s *TaskScheduler) executeTask(task *domainScheduledTask.ScheduledTask) {
 		}
 		s.mutex.Unlock()
 	}
-	// Notify subscribers about the task execution log
-	s.wsHandler.NotifyLogToTaskSubscribers(task.ID, taskLogData)
+
+	s.notifyExecutionFinished(task, executionID, time.Since(now), err)
+
+	// 触发满足条件的下游任务
+	s.triggerDownstream(task, err)
+}
+
+// executeAttempt 在任务超时时间内执行一次
+func (s *TaskScheduler) executeAttempt(runCtx context.Context, task *domainScheduledTask.ScheduledTask) (*executor.ExecutionResult, error) {
+	timeout := s.defaultTimeout
+	if executorTimeout := s.executor.DefaultTimeout(task); executorTimeout > 0 {
+		timeout = executorTimeout
+	}
+	if task.TimeoutSeconds > 0 {
+		timeout = time.Duration(task.TimeoutSeconds) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(runCtx, timeout)
+	defer cancel()
+
+	result, err := s.executor.Execute(ctx, task)
+	if err != nil {
+		switch {
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			return result, fmt.Errorf("task execution timed out after %s: %w", timeout, err)
+		case errors.Is(runCtx.Err(), context.Canceled):
+			return result, executor.NewPermanentError(fmt.Errorf("task execution cancelled: %w", err))
+		}
+	}
+	return result, err
+}
+
+// RunNow 立即触发一次任务，params 不为空时仅本次执行使用该参数，返回执行ID
+// 执行日志仍通过 /ws/scheduleLog 推送，前端可按 execution_id 过滤
+func (s *TaskScheduler) RunNow(taskID int, params datatypes.JSON) (string, error) {
+	if s.ctx.Err() != nil {
+		return "", fmt.Errorf("scheduler is stopped")
+	}
+	task, err := s.repo.GetByID(taskID)
+	if err != nil {
+		return "", err
+	}
+
+	// forbid 策略下直接返回错误，避免返回一个会被跳过的执行ID
+	if task.ConcurrencyPolicy == domainScheduledTask.ConcurrencyForbid && s.isRunningAnywhere(taskID) {
+		return "", fmt.Errorf("task is already running")
+	}
+
+	if len(params) > 0 {
+		task.TaskParams = params
+	}
+	executionID := newExecutionID()
+	s.logger.Info("Task triggered manually",
+		zap.Int("task_id", task.ID),
+		zap.String("execution_id", executionID),
+		zap.Bool("params_override", len(params) > 0))
+	go s.executeTask(task, executionID, fireManual, time.Now())
+	return executionID, nil
+}
+
+// newExecutionID 生成执行ID
+func newExecutionID() string {
+	return uuid.New().String()
+}
+
+// sleepWithContext 等待指定时间，ctx 被取消时提前返回 false
+func sleepWithContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// recordExecution 写入单次执行日志，parentLogID 非空时表示重试
+func (s *TaskScheduler) recordExecution(
+	task *domainScheduledTask.ScheduledTask,
+	executionID string,
+	startTime time.Time,
+	attempt int,
+	parentLogID *int,
+	result *executor.ExecutionResult,
+	execErr error,
+) *domainTaskExecutionLog.TaskExecutionLog {
+	duration := int(time.Since(startTime).Seconds())
+	logData := &domainTaskExecutionLog.TaskExecutionLog{
+		TaskID:          uint(task.ID),
+		ExecuteTime:     startTime,
+		ExecuteDuration: &duration,
+		ExecuteResult:   domainTaskExecutionLog.ExecuteResultSuccess,
+		ExecuteNode:     s.instanceID,
+		ExecutionID:     executionID,
+		Attempt:         attempt,
+		ParentLogID:     parentLogID,
+	}
+	if result != nil {
+		logData.ExecuteOutput = truncateOutput(result.Output, s.maxOutputBytes)
+	}
+
+	if execErr != nil {
+		s.logger.Error("Task execution failed",
+			zap.Int("task_id", task.ID),
+			zap.String("task_name", task.TaskName),
+			zap.Int("attempt", attempt),
+			zap.Error(execErr))
+		logData.ExecuteResult = domainTaskExecutionLog.ExecuteResultFailed
+		logData.ErrorMessage = execErr.Error()
+	} else {
+		s.logger.Info("Task executed successfully",
+			zap.Int("task_id", task.ID),
+			zap.String("task_name", task.TaskName),
+			zap.Int("attempt", attempt))
+	}
+
+	taskLogData, err := s.taskExecutionLogRepo.Create(logData)
+	if err != nil {
+		s.logger.Error("Failed to create task execution log",
+			zap.Int("task_id", task.ID),
+			zap.String("task_name", task.TaskName),
+			zap.Error(err))
+		return nil
+	}
+	return taskLogData
 }
 
 // ReloadTasks 重新加载所有任务（用于运行时刷新）
@@ -276,7 +544,7 @@ func (s *TaskScheduler) ReloadTasks() error {
 	s.mutex.Unlock()
 
 	// 重新加载任务
-	s.loadTasks()
+	s.loadTasks(false)
 
 	s.logger.Info("Tasks reloaded successfully")
 	return nil
@@ -329,32 +597,34 @@ func (s *TaskScheduler) StartTask(taskID int) error {
 func (s *TaskScheduler) StopTask(taskID int) error {
 	s.mutex.Lock()
 
-	job, exists := s.tasks[taskID]
+	_, exists := s.tasks[taskID]
 	if !exists {
 		s.mutex.Unlock()
 		s.logger.Warn("Task not found", zap.Int("task_id", taskID))
 		return fmt.Errorf("task not found")
 	}
 
-	// 检查是否有 WaitGroup
-	var wg *sync.WaitGroup
-	if s.taskWg != nil {
-		wg = s.taskWg[taskID]
+	// 取消正在进行的执行，避免长时间阻塞；等待期间不再登记新的执行
+	run, running := s.running[taskID]
+	if running {
+		run.cancelAll()
+		s.stopping[taskID] = true
 	}
 
 	// 先释放锁，避免死锁
 	s.mutex.Unlock()
 
-	// 如果任务正在运行且有 WaitGroup，等待完成
-	if job.IsRunning() && wg != nil {
+	// 如果任务正在运行，等待所有执行完成
+	if running {
 		s.logger.Info("Task is currently running, waiting for completion", zap.Int("task_id", taskID))
-		wg.Wait() // 等待任务完成
+		run.wg.Wait() // 等待任务完成
 		s.logger.Info("Task completed, now stopping", zap.Int("task_id", taskID))
 	}
 
 	// 重新获取锁来移除任务
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
+	delete(s.stopping, taskID)
 
 	// 再次检查任务是否存在（可能在等待期间已被删除）
 	if job, exists := s.tasks[taskID]; exists {
@@ -414,9 +684,9 @@ func (s *TaskScheduler) UpdateTask(task *domainScheduledTask.ScheduledTask) erro
 		delete(s.tasks, task.ID)
 	}
 
-	// 如果任务启用，则重新调度
-	if strconv.Itoa(task.Status) != scheduleTaskConstants.TaskStatusEnabled {
-		return s.addTaskToSchedule(task)
+	// 如果任务启用或暂停，则按新的 cron 表达式和时区重新调度
+	if isSchedulable(task) {
+		s.addTaskToScheduleInternal(task)
 	}
 
 	s.logger.Info("Task updated", zap.Int("task_id", task.ID))
@@ -432,14 +702,31 @@ func (s *TaskScheduler) GetTaskStatus(taskID int) (bool, error) {
 	return exists, nil
 }
 
-// ListAllTasks 列出所有任务及其状态
-func (s *TaskScheduler) ListAllTasks() map[int]bool {
+// ListAllTasks 列出已调度的任务及其实际状态：TaskStatusRunning、TaskStatusPaused 或 TaskStatusEnabled
+// 暂停标记从库中读取，其他节点上的暂停和恢复同样生效
+func (s *TaskScheduler) ListAllTasks() map[int]string {
+	paused := make(map[int]bool)
+	if tasks, err := s.repo.GetAll(); err != nil {
+		s.logger.Warn("Failed to load paused tasks", zap.Error(err))
+	} else {
+		for _, task := range *tasks {
+			paused[task.ID] = task.Paused
+		}
+	}
+
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
-	status := make(map[int]bool)
-	for id, job := range s.tasks {
-		status[id] = job.IsRunning()
+	status := make(map[int]string)
+	for id := range s.tasks {
+		switch {
+		case s.running[id] != nil:
+			status[id] = scheduleTaskConstants.TaskStatusRunning
+		case paused[id]:
+			status[id] = scheduleTaskConstants.TaskStatusPaused
+		default:
+			status[id] = scheduleTaskConstants.TaskStatusEnabled
+		}
 	}
 	return status
 }
@@ -55,6 +55,19 @@ func (sm *SessionManager) RemoveSession(userID int64, deviceID string) {
 }
 
 func (sm *SessionManager) NotifyOtherDevicesOffline(userID int64, currentDeviceID string) {
+	// 通知除当前设备外的其他设备下线
+	sm.notifyOffline(userID, "You have been logged in from another device", func(session *UserSession) bool {
+		return session.DeviceID == currentDeviceID
+	})
+}
+
+// ForceLogout 通知用户的所有设备下线，如刷新令牌被盗用时
+func (sm *SessionManager) ForceLogout(userID int64, reason string) {
+	sm.notifyOffline(userID, reason, nil)
+}
+
+// notifyOffline 向 skip 未排除的设备推送 FORCE_LOGOUT 并关闭连接
+func (sm *SessionManager) notifyOffline(userID int64, reason string, skip func(session *UserSession) bool) {
 	sm.mutex.RLock()
 	sessions, exists := sm.sessions[userID]
 	sm.mutex.RUnlock()
@@ -62,11 +75,10 @@ func (sm *SessionManager) NotifyOtherDevicesOffline(userID int64, currentDeviceI
 		return
 	}
 	for _, session := range sessions {
-		// 通知除当前设备外的其他设备下线
-		if session.DeviceID != currentDeviceID {
+		if skip == nil || !skip(session) {
 			message := map[string]interface{}{
 				"type":      "FORCE_LOGOUT",
-				"message":   "You have been logged in from another device",
+				"message":   reason,
 				"timestamp": time.Now().Unix(),
 			}
 
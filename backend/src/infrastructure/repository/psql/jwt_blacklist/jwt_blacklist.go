@@ -3,6 +3,7 @@ package jwt_blacklist
 import (
 	"time"
 
+	"github.com/golang-jwt/jwt/v4"
 	"gorm.io/gorm"
 )
 
@@ -12,7 +13,8 @@ type JwtBlacklist struct {
 	UpdatedAt *time.Time     `gorm:"column:updated_at" json:"updatedAt,omitempty"`
 	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at" json:"deletedAt,omitempty"`
 
-	Jwt string `gorm:"column:jwt;type:text;uniqueIndex" json:"jwt"`
+	Jwt       string     `gorm:"column:jwt;type:text;uniqueIndex" json:"jwt"`
+	ExpiresAt *time.Time `gorm:"column:expires_at;index" json:"expiresAt,omitempty"`
 }
 
 func (*JwtBlacklist) TableName() string {
@@ -22,6 +24,7 @@ func (*JwtBlacklist) TableName() string {
 type JwtBlacklistRepository interface {
 	AddToBlacklist(jwtToken string) error
 	IsJwtInBlacklist(token string) (bool, error)
+	PruneExpired(now time.Time, fallbackBefore time.Time) (int64, error)
 }
 
 type Repository struct {
@@ -35,7 +38,8 @@ func NewUJwtBlacklistRepository(db *gorm.DB) JwtBlacklistRepository {
 // AddToBlacklist implements JwtBlacklistRepository.
 func (r *Repository) AddToBlacklist(jwtToken string) error {
 	result := r.DB.Create(&JwtBlacklist{
-		Jwt: jwtToken,
+		Jwt:       jwtToken,
+		ExpiresAt: tokenExpiresAt(jwtToken),
 	})
 	return result.Error
 }
@@ -46,3 +50,39 @@ func (r *Repository) IsJwtInBlacklist(jwtToken string) (bool, error) {
 	r.DB.Model(&JwtBlacklist{}).Where("jwt = ?", jwtToken).Count(&count)
 	return count > 0, nil
 }
+
+// pruneBatchSize 每次 DELETE 的最大行数，避免长时间锁表
+const pruneBatchSize = 1000
+
+// PruneExpired 分批删除已过期 token 的黑名单记录；
+// 没有 expires_at（加入时无法解析 exp 或升级前写入）的记录按加入黑名单的时间是否早于 fallbackBefore 判断
+func (r *Repository) PruneExpired(now time.Time, fallbackBefore time.Time) (int64, error) {
+	var total int64
+	for {
+		expired := r.DB.Unscoped().Model(&JwtBlacklist{}).Select("id").
+			Where("expires_at < ? OR (expires_at IS NULL AND created_at < ?)", now, fallbackBefore).
+			Limit(pruneBatchSize)
+		tx := r.DB.Unscoped().Where("id IN (?)", expired).Delete(&JwtBlacklist{})
+		if tx.Error != nil {
+			return total, tx.Error
+		}
+		total += tx.RowsAffected
+		if tx.RowsAffected < pruneBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// tokenExpiresAt 读取 token 的 exp，不校验签名；无法解析或没有 exp 时返回 nil
+func tokenExpiresAt(token string) *time.Time {
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err != nil {
+		return nil
+	}
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return nil
+	}
+	expiresAt := time.Unix(int64(exp), 0)
+	return &expiresAt
+}
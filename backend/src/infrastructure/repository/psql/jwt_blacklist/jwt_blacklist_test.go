@@ -1 +1,24 @@
 package jwt_blacklist
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenExpiresAt(t *testing.T) {
+	exp := time.Now().Add(time.Hour).Truncate(time.Second)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"exp": exp.Unix()}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+	expiresAt := tokenExpiresAt(token)
+	require.NotNil(t, expiresAt)
+	assert.True(t, exp.Equal(*expiresAt))
+
+	noExp, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"id": 1}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+	assert.Nil(t, tokenExpiresAt(noExp))
+	assert.Nil(t, tokenExpiresAt("not-a-token"))
+}
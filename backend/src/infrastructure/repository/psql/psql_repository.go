@@ -6,7 +6,13 @@ import (
 	"strings"
 
 	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/jwt_blacklist"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/api"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/outbox"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/scheduled_task"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/task_dependency"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/task_execution_log"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/webhook"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/user"
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
@@ -23,6 +29,7 @@ type DatabaseConfig struct {
 	Password string
 	DBName   string
 	SSLMode  string
+	TimeZone string
 }
 
 // loadDatabaseConfig loads database configuration from environment variables
@@ -34,6 +41,11 @@ func loadDatabaseConfig() (DatabaseConfig, error) {
 	password := os.Getenv("POSTGRES_PASSWORD")
 	dbName := os.Getenv("POSTGRES_NAME")
 	sslMode := os.Getenv("POSTGRES_SSLMODE")
+	// 连接会话时区，只影响数据库侧的显示，可选
+	timeZone := os.Getenv("POSTGRES_TIMEZONE")
+	if timeZone == "" {
+		timeZone = "America/Mexico_City"
+	}
 
 	// Check for missing required environment variables
 	var missingVars []string
@@ -67,6 +79,7 @@ func loadDatabaseConfig() (DatabaseConfig, error) {
 		Password: password,
 		DBName:   dbName,
 		SSLMode:  sslMode,
+		TimeZone: timeZone,
 	}, nil
 }
 
@@ -106,7 +119,7 @@ func (c DatabaseConfig) GetDSN() string {
 		" password=" + c.Password +
 		" dbname=" + c.DBName +
 		" sslmode=" + c.SSLMode +
-		" TimeZone=America/Mexico_City"
+		" TimeZone=" + c.TimeZone
 }
 
 func (r *PSQLRepository) InitDatabase() error {
@@ -148,13 +161,26 @@ func (r *PSQLRepository) MigrateEntitiesGORM() error {
 	// Import the models to register them with GORM
 	userModel := &user.User{}
 	apiModal := &api.SysApi{}
+	scheduledTaskModel := &scheduled_task.ScheduledTask{}
+	taskExecutionLogModel := &task_execution_log.TaskExecutionLog{}
+	taskDependencyModel := &task_dependency.TaskDependency{}
+	outboxEventModel := &outbox.OutboxEvent{}
+	webhookModel := &webhook.Webhook{}
+	webhookDeliveryModel := &webhook.WebhookDelivery{}
+	jwtBlacklistModel := &jwt_blacklist.JwtBlacklist{}
 
 	// Auto migrate the models to create/update tables
-	err := r.DB.AutoMigrate(userModel, apiModal)
+	err := r.DB.AutoMigrate(userModel, apiModal, scheduledTaskModel, taskExecutionLogModel, taskDependencyModel, outboxEventModel,
+		webhookModel, webhookDeliveryModel, jwtBlacklistModel)
 	if err != nil {
 		r.Logger.Error("Error migrating database entities", zap.Error(err))
 		return err
 	}
+	// 新增 paused 列之前只用 status 表示暂停
+	if err := r.DB.Model(scheduledTaskModel).Where("status = ? AND paused = ?", 3, false).Update("paused", true).Error; err != nil {
+		r.Logger.Error("Error backfilling paused scheduled tasks", zap.Error(err))
+		return err
+	}
 
 	r.Logger.Info("Database entities migration completed successfully")
 	return nil
@@ -51,6 +51,7 @@ type ISysFilesRepository interface {
 	SearchPaginated(filters domain.DataFilters) (*domain.PaginatedResult[filesDomain.SysFiles], error)
 	SearchByProperty(property string, searchText string) (*[]string, error)
 	GetOneByMap(fileMap map[string]interface{}) (*filesDomain.SysFiles, error)
+	ExistingPaths(paths []string) (map[string]bool, error)
 }
 
 type Repository struct {
@@ -120,6 +121,23 @@ func (r *Repository) GetAll() (*[]filesDomain.SysFiles, error) {
 	return arrayToDomainMapper(&files), nil
 }
 
+// ExistingPaths 返回 paths 中有 sys_files 记录的路径
+func (r *Repository) ExistingPaths(paths []string) (map[string]bool, error) {
+	existing := make(map[string]bool, len(paths))
+	if len(paths) == 0 {
+		return existing, nil
+	}
+	var found []string
+	if err := r.DB.Model(&SysFiles{}).Where("file_path IN ?", paths).Distinct().Pluck("file_path", &found).Error; err != nil {
+		r.Logger.Error("Error checking file paths", zap.Error(err), zap.Int("count", len(paths)))
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	for _, path := range found {
+		existing[path] = true
+	}
+	return existing, nil
+}
+
 func (r *Repository) GetByID(id int) (*filesDomain.SysFiles, error) {
 	var file SysFiles
 	err := r.DB.Where("id = ?", id).First(&file).Error
@@ -54,6 +54,7 @@ type OperationRepositoryInterface interface {
 	SearchPaginated(filters domain.DataFilters) (*domain.PaginatedResult[domainOperation.SysOperationRecord], error)
 	SearchByProperty(property string, searchText string) (*[]string, error)
 	GetOneByMap(apiMap map[string]interface{}) (*domainOperation.SysOperationRecord, error)
+	PurgeBefore(before time.Time) (int64, error)
 }
 
 type Repository struct {
@@ -164,6 +165,17 @@ func (r *Repository) Delete(ids []int) error {
 	return nil
 }
 
+// PurgeBefore 物理删除指定时间之前的操作记录，返回删除条数
+func (r *Repository) PurgeBefore(before time.Time) (int64, error) {
+	tx := r.DB.Unscoped().Where("created_at < ?", before).Delete(&SysOperationRecord{})
+	if tx.Error != nil {
+		r.Logger.Error("Error purging operation records", zap.Error(tx.Error), zap.Time("before", before))
+		return 0, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	r.Logger.Info("Successfully purged operation records", zap.Int64("count", tx.RowsAffected), zap.Time("before", before))
+	return tx.RowsAffected, nil
+}
+
 func (r *Repository) SearchPaginated(filters domain.DataFilters) (*domain.PaginatedResult[domainOperation.SysOperationRecord], error) {
 	query := r.DB.Model(&SysOperationRecord{})
 
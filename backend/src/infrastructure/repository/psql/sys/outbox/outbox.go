@@ -0,0 +1,196 @@
+package outbox
+
+import (
+	"time"
+
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainOutbox "github.com/gbrayhan/microservices-go/src/domain/sys/outbox"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OutboxEvent struct {
+	ID            int64          `gorm:"primaryKey" json:"id"`
+	EventID       string         `gorm:"size:64;not null;uniqueIndex" json:"event_id"`
+	EventType     string         `gorm:"size:100;not null;index" json:"event_type"`
+	EventVersion  int            `gorm:"not null;default:1" json:"event_version"`
+	CorrelationID string         `gorm:"size:64" json:"correlation_id"`
+	Payload       datatypes.JSON `json:"payload"`
+	OccurredAt    time.Time      `gorm:"not null" json:"occurred_at"`
+	Status        string         `gorm:"size:20;not null;default:pending;index:idx_outbox_status_next" json:"status"`
+	Attempts      int            `gorm:"not null;default:0" json:"attempts"`                           // 已投递次数
+	NextAttemptAt time.Time      `gorm:"not null;index:idx_outbox_status_next" json:"next_attempt_at"` // 下次可投递时间
+	LastError     string         `gorm:"type:text" json:"last_error"`
+	PublishedAt   *time.Time     `json:"published_at"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+// TableName 指定表名
+func (OutboxEvent) TableName() string {
+	return "sys_outbox_events"
+}
+
+type IOutboxRepository interface {
+	Create(event *domainOutbox.OutboxEvent) error
+	ClaimPending(now time.Time, lease time.Duration, limit int) (*[]domainOutbox.OutboxEvent, error)
+	MarkDone(id int64) error
+	MarkRetry(id int64, nextAttemptAt time.Time, lastError string) error
+	MarkFailed(id int64, lastError string) error
+	PurgeDone(before time.Time) (int64, error)
+}
+
+type Repository struct {
+	DB     *gorm.DB
+	Logger *logger.Logger
+}
+
+func NewOutboxRepository(db *gorm.DB, loggerInstance *logger.Logger) IOutboxRepository {
+	return &Repository{DB: db, Logger: loggerInstance}
+}
+
+// Insert 在调用方的事务中写入发件箱，保证事件与业务数据同时提交或回滚
+func Insert(tx *gorm.DB, event *domainOutbox.OutboxEvent) error {
+	row := fromDomainMapper(event)
+	if err := tx.Create(row).Error; err != nil {
+		return err
+	}
+	event.ID = row.ID
+	event.Status = row.Status
+	event.NextAttemptAt = row.NextAttemptAt
+	event.CreatedAt = row.CreatedAt
+	return nil
+}
+
+func (r *Repository) Create(event *domainOutbox.OutboxEvent) error {
+	if err := Insert(r.DB, event); err != nil {
+		r.Logger.Error("Error creating outbox event", zap.Error(err),
+			zap.String("event_type", event.EventType), zap.String("event_id", event.EventID))
+		return domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return nil
+}
+
+// ClaimPending 领取到期的待投递事件，并把下次投递时间推迟 lease，
+// 多个节点同时运行 relay 时通过 SKIP LOCKED 避免重复领取；进程在投递中崩溃时租约到期后会被重新领取
+func (r *Repository) ClaimPending(now time.Time, lease time.Duration, limit int) (*[]domainOutbox.OutboxEvent, error) {
+	var rows []OutboxEvent
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", domainOutbox.StatusPending, now).
+			Order("id").Limit(limit).Find(&rows).Error
+		if err != nil || len(rows) == 0 {
+			return err
+		}
+		ids := make([]int64, len(rows))
+		for i := range rows {
+			ids[i] = rows[i].ID
+			rows[i].Attempts++
+			rows[i].NextAttemptAt = now.Add(lease)
+		}
+		return tx.Model(&OutboxEvent{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"attempts":        gorm.Expr("attempts + 1"),
+			"next_attempt_at": now.Add(lease),
+		}).Error
+	})
+	if err != nil {
+		r.Logger.Error("Error claiming outbox events", zap.Error(err))
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return arrayToDomainMapper(&rows), nil
+}
+
+func (r *Repository) MarkDone(id int64) error {
+	now := time.Now()
+	return r.update(id, map[string]interface{}{
+		"status":       domainOutbox.StatusDone,
+		"published_at": &now,
+		"last_error":   "",
+	})
+}
+
+func (r *Repository) MarkRetry(id int64, nextAttemptAt time.Time, lastError string) error {
+	return r.update(id, map[string]interface{}{
+		"next_attempt_at": nextAttemptAt,
+		"last_error":      lastError,
+	})
+}
+
+func (r *Repository) MarkFailed(id int64, lastError string) error {
+	return r.update(id, map[string]interface{}{
+		"status":     domainOutbox.StatusFailed,
+		"last_error": lastError,
+	})
+}
+
+// PurgeDone 删除指定时间之前已投递的事件，返回删除条数；failed 事件保留用于排查
+func (r *Repository) PurgeDone(before time.Time) (int64, error) {
+	tx := r.DB.Where("status = ? AND published_at < ?", domainOutbox.StatusDone, before).Delete(&OutboxEvent{})
+	if tx.Error != nil {
+		r.Logger.Error("Error purging outbox events", zap.Error(tx.Error), zap.Time("before", before))
+		return 0, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	r.Logger.Info("Successfully purged outbox events", zap.Int64("count", tx.RowsAffected), zap.Time("before", before))
+	return tx.RowsAffected, nil
+}
+
+func (r *Repository) update(id int64, values map[string]interface{}) error {
+	if err := r.DB.Model(&OutboxEvent{}).Where("id = ?", id).Updates(values).Error; err != nil {
+		r.Logger.Error("Error updating outbox event", zap.Error(err), zap.Int64("id", id))
+		return domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return nil
+}
+
+func (o *OutboxEvent) toDomainMapper() *domainOutbox.OutboxEvent {
+	return &domainOutbox.OutboxEvent{
+		ID:            o.ID,
+		EventID:       o.EventID,
+		EventType:     o.EventType,
+		EventVersion:  o.EventVersion,
+		CorrelationID: o.CorrelationID,
+		Payload:       o.Payload,
+		OccurredAt:    o.OccurredAt,
+		Status:        o.Status,
+		Attempts:      o.Attempts,
+		NextAttemptAt: o.NextAttemptAt,
+		LastError:     o.LastError,
+		PublishedAt:   o.PublishedAt,
+		CreatedAt:     o.CreatedAt,
+	}
+}
+
+func fromDomainMapper(o *domainOutbox.OutboxEvent) *OutboxEvent {
+	status := o.Status
+	if status == "" {
+		status = domainOutbox.StatusPending
+	}
+	nextAttemptAt := o.NextAttemptAt
+	if nextAttemptAt.IsZero() {
+		nextAttemptAt = time.Now()
+	}
+	return &OutboxEvent{
+		ID:            o.ID,
+		EventID:       o.EventID,
+		EventType:     o.EventType,
+		EventVersion:  o.EventVersion,
+		CorrelationID: o.CorrelationID,
+		Payload:       o.Payload,
+		OccurredAt:    o.OccurredAt,
+		Status:        status,
+		Attempts:      o.Attempts,
+		NextAttemptAt: nextAttemptAt,
+		LastError:     o.LastError,
+		PublishedAt:   o.PublishedAt,
+	}
+}
+
+func arrayToDomainMapper(rows *[]OutboxEvent) *[]domainOutbox.OutboxEvent {
+	events := make([]domainOutbox.OutboxEvent, len(*rows))
+	for i, row := range *rows {
+		events[i] = *row.toDomainMapper()
+	}
+	return &events
+}
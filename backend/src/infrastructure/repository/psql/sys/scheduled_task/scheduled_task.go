@@ -9,6 +9,7 @@ import (
 	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
 	domainScheduledTask "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
 	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	dependencyRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/task_dependency"
 	"go.uber.org/zap"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
@@ -23,10 +24,26 @@ type ScheduledTask struct {
 	TaskParams      datatypes.JSON `json:"task_params"`
 	ExecType        string         `json:"exec_type"`
 	Status          int            `gorm:"default:1" json:"status"`
-	LastExecuteTime time.Time      `json:"last_execute_time"`
-	NextExecuteTime time.Time      `json:"next_execute_time"`
-	CreatedAt       time.Time      `json:"created_at"`
-	UpdatedAt       time.Time      `json:"updated_at"`
+	// 重试策略：最大尝试次数（含首次）、退避方式、基础间隔、可重试错误关键字（逗号分隔，为空表示全部可重试）
+	RetryMaxAttempts     int    `gorm:"default:1" json:"retry_max_attempts"`
+	RetryBackoff         string `gorm:"size:20;default:fixed" json:"retry_backoff"`
+	RetryIntervalSeconds int    `gorm:"default:10" json:"retry_interval_seconds"`
+	RetryOnErrors        string `gorm:"size:500" json:"retry_on_errors"`
+	TimeoutSeconds       int    `gorm:"default:0" json:"timeout_seconds"`                // 单次执行超时，0 表示使用默认值
+	MisfirePolicy        string `gorm:"size:20;default:skip" json:"misfire_policy"`      // 服务停机期间错过触发的处理策略
+	ConcurrencyPolicy    string `gorm:"size:20;default:allow" json:"concurrency_policy"` // 上一次执行未结束时的处理策略
+	Timezone             string `gorm:"size:64" json:"timezone"`                         // IANA 时区，为空时使用 SCHEDULER_DEFAULT_TIMEZONE
+	// 告警规则：连续失败次数、单次触发最长耗时（秒），0 表示不启用；告警邮箱逗号分隔
+	AlertConsecutiveFailures int            `gorm:"default:0" json:"alert_consecutive_failures"`
+	AlertMaxDurationSeconds  int            `gorm:"default:0" json:"alert_max_duration_seconds"`
+	AlertEmails              string         `gorm:"size:500" json:"alert_emails"`
+	Paused                   bool           `gorm:"default:false" json:"paused"` // 是否已暂停，与运行状态分开保存
+	PausedUntil              *time.Time     `json:"paused_until"`                // 暂停到期时间，为空表示暂停到手动恢复
+	BlackoutWindows          datatypes.JSON `json:"blackout_windows"`            // 周期性停止窗口
+	LastExecuteTime          time.Time      `json:"last_execute_time"`
+	NextExecuteTime          time.Time      `json:"next_execute_time"`
+	CreatedAt                time.Time      `json:"created_at"`
+	UpdatedAt                time.Time      `json:"updated_at"`
 }
 
 func (ScheduledTask) TableName() string {
@@ -45,7 +62,10 @@ type IScheduledTaskRepository interface {
 	Create(domainScheduledTask *domainScheduledTask.ScheduledTask) (*domainScheduledTask.ScheduledTask, error)
 	GetByID(id int) (*domainScheduledTask.ScheduledTask, error)
 	Update(id int, taskMap map[string]interface{}) (*domainScheduledTask.ScheduledTask, error)
+	CreateWithDependencies(task *domainScheduledTask.ScheduledTask, dependencies []domainScheduledTask.TaskDependency) (*domainScheduledTask.ScheduledTask, error)
+	UpdateWithDependencies(id int, taskMap map[string]interface{}, dependencies []domainScheduledTask.TaskDependency, checkGraph DependencyGraphCheck) (*domainScheduledTask.ScheduledTask, error)
 	Delete(ids []int) error
+	DeleteWithDependencies(ids []int) error
 	SearchPaginated(filters domain.DataFilters) (*domain.PaginatedResult[domainScheduledTask.ScheduledTask], error)
 	SearchByProperty(property string, searchText string) (*[]string, error)
 }
@@ -138,6 +158,68 @@ func (r *Repository) Update(id int, dataMap map[string]interface{}) (*domainSche
 	return dataObj.toDomainMapper(), nil
 }
 
+// CreateWithDependencies 在同一事务中创建任务并写入上游依赖
+func (r *Repository) CreateWithDependencies(task *domainScheduledTask.ScheduledTask, dependencies []domainScheduledTask.TaskDependency) (*domainScheduledTask.ScheduledTask, error) {
+	r.Logger.Info("Creating new task with dependencies", zap.String("TaskName", task.TaskName), zap.Int("dependencies", len(dependencies)))
+	taskRepository := fromDomainMapper(task)
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(taskRepository).Error; err != nil {
+			return err
+		}
+		return dependencyRepo.Replace(tx, taskRepository.ID, dependencies)
+	})
+	if err != nil {
+		r.Logger.Error("Error creating task with dependencies", zap.Error(err), zap.String("TaskName", task.TaskName))
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	r.Logger.Info("Successfully created task", zap.String("TaskName", task.TaskName), zap.Int("id", taskRepository.ID))
+	return taskRepository.toDomainMapper(), nil
+}
+
+// DependencyGraphCheck 在锁定依赖表后用当前所有依赖校验新的依赖，返回错误时回滚
+type DependencyGraphCheck func(existing []domainScheduledTask.TaskDependency) error
+
+// UpdateWithDependencies 在同一事务中更新任务并整体替换上游依赖；
+// 先锁定依赖表再调用 checkGraph，并发保存互相依赖的任务时不会都通过环检测
+func (r *Repository) UpdateWithDependencies(id int, dataMap map[string]interface{}, dependencies []domainScheduledTask.TaskDependency, checkGraph DependencyGraphCheck) (*domainScheduledTask.ScheduledTask, error) {
+	var dataObj ScheduledTask
+	dataObj.ID = id
+	delete(dataMap, "updated_at")
+	var checkErr error
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if checkGraph != nil {
+			existing, err := dependencyRepo.LockAll(tx)
+			if err != nil {
+				return err
+			}
+			if checkErr = checkGraph(existing); checkErr != nil {
+				return checkErr
+			}
+		}
+		if len(dataMap) > 0 {
+			if err := tx.Model(&dataObj).Updates(dataMap).Error; err != nil {
+				return err
+			}
+		}
+		if err := dependencyRepo.Replace(tx, id, dependencies); err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).First(&dataObj).Error
+	})
+	if checkErr != nil {
+		return nil, checkErr
+	}
+	if err != nil {
+		r.Logger.Error("Error updating task with dependencies", zap.Error(err), zap.Int("id", id))
+		if err == gorm.ErrRecordNotFound {
+			return &domainScheduledTask.ScheduledTask{}, domainErrors.NewAppErrorWithType(domainErrors.NotFound)
+		}
+		return &domainScheduledTask.ScheduledTask{}, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	r.Logger.Info("Successfully updated task with dependencies", zap.Int("id", id), zap.Int("dependencies", len(dependencies)))
+	return dataObj.toDomainMapper(), nil
+}
+
 func (r *Repository) Delete(ids []int) error {
 	tx := r.DB.Where("id IN ?", ids).Delete(&ScheduledTask{})
 
@@ -153,6 +235,30 @@ func (r *Repository) Delete(ids []int) error {
 	return nil
 }
 
+// DeleteWithDependencies 在同一事务中删除任务及其作为上游或下游的依赖，不会留下指向已删除任务的依赖
+func (r *Repository) DeleteWithDependencies(ids []int) error {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id IN ?", ids).Delete(&ScheduledTask{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return dependencyRepo.DeleteForTasks(tx, ids)
+	})
+	if err == gorm.ErrRecordNotFound {
+		r.Logger.Warn("data not found for deletion", zap.String("ids", fmt.Sprintf("%v", ids)))
+		return domainErrors.NewAppErrorWithType(domainErrors.NotFound)
+	}
+	if err != nil {
+		r.Logger.Error("Error deleting task with dependencies", zap.Error(err), zap.String("ids", fmt.Sprintf("%v", ids)))
+		return domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	r.Logger.Info("Successfully deleted task", zap.String("ids", fmt.Sprintf("%v", ids)))
+	return nil
+}
+
 func (r *Repository) SearchPaginated(filters domain.DataFilters) (*domain.PaginatedResult[domainScheduledTask.ScheduledTask], error) {
 	query := r.DB.Model(&ScheduledTask{})
 
@@ -267,16 +373,30 @@ func (r *Repository) SearchByProperty(property string, searchText string) (*[]st
 
 func (u *ScheduledTask) toDomainMapper() *domainScheduledTask.ScheduledTask {
 	return &domainScheduledTask.ScheduledTask{
-		ID:              u.ID,
-		TaskName:        u.TaskName,
-		TaskType:        u.TaskType,
-		TaskDescription: u.TaskDescription,
-		TaskParams:      u.TaskParams,
-		CronExpression:  u.CronExpression,
-		Status:          u.Status,
-		ExecType:        u.ExecType,
-		LastExecuteTime: u.LastExecuteTime,
-		NextExecuteTime: u.NextExecuteTime,
+		ID:                       u.ID,
+		TaskName:                 u.TaskName,
+		TaskType:                 u.TaskType,
+		TaskDescription:          u.TaskDescription,
+		TaskParams:               u.TaskParams,
+		CronExpression:           u.CronExpression,
+		Status:                   u.Status,
+		ExecType:                 u.ExecType,
+		RetryMaxAttempts:         u.RetryMaxAttempts,
+		RetryBackoff:             u.RetryBackoff,
+		RetryIntervalSeconds:     u.RetryIntervalSeconds,
+		RetryOnErrors:            u.RetryOnErrors,
+		TimeoutSeconds:           u.TimeoutSeconds,
+		MisfirePolicy:            u.MisfirePolicy,
+		ConcurrencyPolicy:        u.ConcurrencyPolicy,
+		Timezone:                 u.Timezone,
+		AlertConsecutiveFailures: u.AlertConsecutiveFailures,
+		AlertMaxDurationSeconds:  u.AlertMaxDurationSeconds,
+		AlertEmails:              u.AlertEmails,
+		Paused:                   u.Paused,
+		PausedUntil:              u.PausedUntil,
+		BlackoutWindows:          u.BlackoutWindows,
+		LastExecuteTime:          u.LastExecuteTime,
+		NextExecuteTime:          u.NextExecuteTime,
 
 		CreatedAt: u.CreatedAt,
 		UpdatedAt: u.UpdatedAt,
@@ -285,18 +405,32 @@ func (u *ScheduledTask) toDomainMapper() *domainScheduledTask.ScheduledTask {
 
 func fromDomainMapper(u *domainScheduledTask.ScheduledTask) *ScheduledTask {
 	return &ScheduledTask{
-		ID:              u.ID,
-		TaskName:        u.TaskName,
-		TaskType:        u.TaskType,
-		TaskDescription: u.TaskDescription,
-		TaskParams:      u.TaskParams,
-		CronExpression:  u.CronExpression,
-		Status:          u.Status,
-		ExecType:        u.ExecType,
-		LastExecuteTime: u.LastExecuteTime,
-		NextExecuteTime: u.NextExecuteTime,
-		CreatedAt:       u.CreatedAt,
-		UpdatedAt:       u.UpdatedAt,
+		ID:                       u.ID,
+		TaskName:                 u.TaskName,
+		TaskType:                 u.TaskType,
+		TaskDescription:          u.TaskDescription,
+		TaskParams:               u.TaskParams,
+		CronExpression:           u.CronExpression,
+		Status:                   u.Status,
+		ExecType:                 u.ExecType,
+		RetryMaxAttempts:         u.RetryMaxAttempts,
+		RetryBackoff:             u.RetryBackoff,
+		RetryIntervalSeconds:     u.RetryIntervalSeconds,
+		RetryOnErrors:            u.RetryOnErrors,
+		TimeoutSeconds:           u.TimeoutSeconds,
+		MisfirePolicy:            u.MisfirePolicy,
+		ConcurrencyPolicy:        u.ConcurrencyPolicy,
+		Timezone:                 u.Timezone,
+		AlertConsecutiveFailures: u.AlertConsecutiveFailures,
+		AlertMaxDurationSeconds:  u.AlertMaxDurationSeconds,
+		AlertEmails:              u.AlertEmails,
+		Paused:                   u.Paused,
+		PausedUntil:              u.PausedUntil,
+		BlackoutWindows:          u.BlackoutWindows,
+		LastExecuteTime:          u.LastExecuteTime,
+		NextExecuteTime:          u.NextExecuteTime,
+		CreatedAt:                u.CreatedAt,
+		UpdatedAt:                u.UpdatedAt,
 	}
 }
 
@@ -0,0 +1,157 @@
+package task_dependency
+
+import (
+	"fmt"
+	"time"
+
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainScheduledTask "github.com/gbrayhan/microservices-go/src/domain/sys/scheduled_task"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type TaskDependency struct {
+	ID               int       `gorm:"primaryKey" json:"id"`
+	TaskID           int       `gorm:"not null;uniqueIndex:idx_task_upstream" json:"task_id"`                // 下游任务
+	UpstreamTaskID   int       `gorm:"not null;uniqueIndex:idx_task_upstream;index" json:"upstream_task_id"` // 上游任务
+	TriggerCondition string    `gorm:"size:20;not null;default:success" json:"trigger_condition"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+func (TaskDependency) TableName() string {
+	return "sys_scheduled_task_dependencies"
+}
+
+type ITaskDependencyRepository interface {
+	GetAll() (*[]domainScheduledTask.TaskDependency, error)
+	GetByTaskID(taskID int) (*[]domainScheduledTask.TaskDependency, error)
+	GetByUpstreamTaskID(upstreamTaskID int) (*[]domainScheduledTask.TaskDependency, error)
+	ReplaceByTaskID(taskID int, dependencies []domainScheduledTask.TaskDependency) error
+	DeleteByTaskIDs(taskIDs []int) error
+}
+
+type Repository struct {
+	DB     *gorm.DB
+	Logger *logger.Logger
+}
+
+func NewTaskDependencyRepository(db *gorm.DB, loggerInstance *logger.Logger) ITaskDependencyRepository {
+	return &Repository{DB: db, Logger: loggerInstance}
+}
+
+func (r *Repository) GetAll() (*[]domainScheduledTask.TaskDependency, error) {
+	var dependencies []TaskDependency
+	if err := r.DB.Order("id").Find(&dependencies).Error; err != nil {
+		r.Logger.Error("Error getting all task dependencies", zap.Error(err))
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return arrayToDomainMapper(&dependencies), nil
+}
+
+func (r *Repository) GetByTaskID(taskID int) (*[]domainScheduledTask.TaskDependency, error) {
+	var dependencies []TaskDependency
+	if err := r.DB.Where("task_id = ?", taskID).Order("id").Find(&dependencies).Error; err != nil {
+		r.Logger.Error("Error getting task dependencies", zap.Error(err), zap.Int("task_id", taskID))
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return arrayToDomainMapper(&dependencies), nil
+}
+
+func (r *Repository) GetByUpstreamTaskID(upstreamTaskID int) (*[]domainScheduledTask.TaskDependency, error) {
+	var dependencies []TaskDependency
+	if err := r.DB.Where("upstream_task_id = ?", upstreamTaskID).Order("id").Find(&dependencies).Error; err != nil {
+		r.Logger.Error("Error getting downstream tasks", zap.Error(err), zap.Int("upstream_task_id", upstreamTaskID))
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return arrayToDomainMapper(&dependencies), nil
+}
+
+// ReplaceByTaskID 用新的依赖列表整体替换任务的上游依赖
+func (r *Repository) ReplaceByTaskID(taskID int, dependencies []domainScheduledTask.TaskDependency) error {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		return Replace(tx, taskID, dependencies)
+	})
+	if err != nil {
+		r.Logger.Error("Error replacing task dependencies", zap.Error(err), zap.Int("task_id", taskID))
+		return domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	r.Logger.Info("Successfully replaced task dependencies", zap.Int("task_id", taskID), zap.Int("count", len(dependencies)))
+	return nil
+}
+
+// Replace 在调用方的事务中整体替换任务的上游依赖，用于和任务本身的写入放在同一事务中
+func Replace(tx *gorm.DB, taskID int, dependencies []domainScheduledTask.TaskDependency) error {
+	if err := tx.Where("task_id = ?", taskID).Delete(&TaskDependency{}).Error; err != nil {
+		return err
+	}
+	if len(dependencies) == 0 {
+		return nil
+	}
+	rows := make([]TaskDependency, 0, len(dependencies))
+	for _, dependency := range dependencies {
+		row := fromDomainMapper(&dependency)
+		row.ID = 0
+		row.TaskID = taskID
+		rows = append(rows, *row)
+	}
+	return tx.Create(&rows).Error
+}
+
+// LockAll 在调用方的事务中锁定依赖表并读取所有依赖，事务结束前其他保存依赖的请求会等待，
+// 用于在写入前检查依赖是否成环；SHARE ROW EXCLUSIVE 与自身互斥但不阻塞读取
+func LockAll(tx *gorm.DB) ([]domainScheduledTask.TaskDependency, error) {
+	if err := tx.Exec("LOCK TABLE " + TaskDependency{}.TableName() + " IN SHARE ROW EXCLUSIVE MODE").Error; err != nil {
+		return nil, err
+	}
+	var dependencies []TaskDependency
+	if err := tx.Order("id").Find(&dependencies).Error; err != nil {
+		return nil, err
+	}
+	return *arrayToDomainMapper(&dependencies), nil
+}
+
+// DeleteByTaskIDs 删除任务时清理其作为上游或下游的依赖
+func (r *Repository) DeleteByTaskIDs(taskIDs []int) error {
+	if err := DeleteForTasks(r.DB, taskIDs); err != nil {
+		r.Logger.Error("Error deleting task dependencies", zap.Error(err), zap.String("ids", fmt.Sprintf("%v", taskIDs)))
+		return domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return nil
+}
+
+// DeleteForTasks 在调用方的事务中删除任务作为上游或下游的依赖
+func DeleteForTasks(tx *gorm.DB, taskIDs []int) error {
+	if len(taskIDs) == 0 {
+		return nil
+	}
+	return tx.Where("task_id IN ? OR upstream_task_id IN ?", taskIDs, taskIDs).Delete(&TaskDependency{}).Error
+}
+
+func (d *TaskDependency) toDomainMapper() *domainScheduledTask.TaskDependency {
+	return &domainScheduledTask.TaskDependency{
+		ID:               d.ID,
+		TaskID:           d.TaskID,
+		UpstreamTaskID:   d.UpstreamTaskID,
+		TriggerCondition: d.TriggerCondition,
+		CreatedAt:        d.CreatedAt,
+	}
+}
+
+func fromDomainMapper(d *domainScheduledTask.TaskDependency) *TaskDependency {
+	return &TaskDependency{
+		ID:               d.ID,
+		TaskID:           d.TaskID,
+		UpstreamTaskID:   d.UpstreamTaskID,
+		TriggerCondition: d.TriggerCondition,
+		CreatedAt:        d.CreatedAt,
+	}
+}
+
+func arrayToDomainMapper(dependencies *[]TaskDependency) *[]domainScheduledTask.TaskDependency {
+	dependenciesDomain := make([]domainScheduledTask.TaskDependency, len(*dependencies))
+	for i, dependency := range *dependencies {
+		dependenciesDomain[i] = *dependency.toDomainMapper()
+	}
+	return &dependenciesDomain
+}
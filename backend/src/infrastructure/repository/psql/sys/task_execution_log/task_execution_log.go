@@ -17,9 +17,14 @@ type TaskExecutionLog struct {
 	ID              int       `gorm:"primaryKey" json:"id"`
 	TaskID          uint      `gorm:"not null;index" json:"task_id"`
 	ExecuteTime     time.Time `gorm:"not null;index" json:"execute_time"`
-	ExecuteResult   int       `gorm:"not null" json:"execute_result"` // 1-成功, 0-失败
+	ExecuteResult   int       `gorm:"not null" json:"execute_result"` // 1-成功, 0-失败, 2-跳过
 	ExecuteDuration *int      `json:"execute_duration"`               // 执行耗时(毫秒)
 	ErrorMessage    string    `json:"error_message"`
+	ExecuteOutput   string    `gorm:"type:text" json:"execute_output"`    // 执行输出
+	ExecuteNode     string    `gorm:"size:100;index" json:"execute_node"` // 执行节点
+	ExecutionID     string    `gorm:"size:64;index" json:"execution_id"`  // 同一次触发（含重试）共用的执行ID
+	Attempt         int       `gorm:"default:1" json:"attempt"`           // 第几次尝试
+	ParentLogID     *int      `gorm:"index" json:"parent_log_id"`         // 重试时指向首次执行的日志
 	CreatedAt       time.Time `json:"created_at"`
 }
 
@@ -37,10 +42,15 @@ type ITaskExecutionLogRepository interface {
 	SearchPaginated(filters domain.DataFilters) (*domain.PaginatedResult[domainTaskExecution.TaskExecutionLog], error)
 	SearchByProperty(property string, searchText string) (*[]string, error)
 	GetByTaskID(taskID uint, limit int) (*[]domainTaskExecution.TaskExecutionLog, error)
+	GetSince(taskID uint, since time.Time, limit int) (*[]domainTaskExecution.TaskExecutionLog, error)
+	PurgeBefore(before time.Time) (int64, error)
 }
 
 var ColumnsTaskExecutionLogMapping = map[string]string{
-	"taskId": "task_id",
+	"taskId":      "task_id",
+	"executeNode": "execute_node",
+	"executionId": "execution_id",
+	"parentLogId": "parent_log_id",
 }
 
 type Repository struct {
@@ -104,6 +114,17 @@ func (r *Repository) GetByID(id int) (*domainTaskExecution.TaskExecutionLog, err
 	return api.toDomainMapper(), nil
 }
 
+// PurgeBefore 删除指定时间之前的执行日志，返回删除条数
+func (r *Repository) PurgeBefore(before time.Time) (int64, error) {
+	tx := r.DB.Where("execute_time < ?", before).Delete(&TaskExecutionLog{})
+	if tx.Error != nil {
+		r.Logger.Error("Error purging task execution logs", zap.Error(tx.Error), zap.Time("before", before))
+		return 0, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	r.Logger.Info("Successfully purged task execution logs", zap.Int64("count", tx.RowsAffected), zap.Time("before", before))
+	return tx.RowsAffected, nil
+}
+
 func (r *Repository) Update(id int, dataMap map[string]interface{}) (*domainTaskExecution.TaskExecutionLog, error) {
 	var dataObj TaskExecutionLog
 	dataObj.ID = id
@@ -269,6 +290,20 @@ func (r *Repository) GetByTaskID(taskID uint, limit int) (*[]domainTaskExecution
 	return arrayToDomainMapper(&tasks), nil
 }
 
+// GetSince 查询 since 之后的执行日志，按 ID 倒序，taskID 为 0 时查询所有任务
+func (r *Repository) GetSince(taskID uint, since time.Time, limit int) (*[]domainTaskExecution.TaskExecutionLog, error) {
+	var tasks []TaskExecutionLog
+	query := r.DB.Where("execute_time >= ?", since)
+	if taskID != 0 {
+		query = query.Where("task_id = ?", taskID)
+	}
+	if err := query.Order("ID desc").Limit(limit).Find(&tasks).Error; err != nil {
+		r.Logger.Error("Error retrieving task execution logs", zap.Error(err), zap.Uint("taskID", taskID), zap.Time("since", since))
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return arrayToDomainMapper(&tasks), nil
+}
+
 func (u *TaskExecutionLog) toDomainMapper() *domainTaskExecution.TaskExecutionLog {
 	return &domainTaskExecution.TaskExecutionLog{
 		ID:              u.ID,
@@ -276,7 +311,12 @@ func (u *TaskExecutionLog) toDomainMapper() *domainTaskExecution.TaskExecutionLo
 		ExecuteResult:   u.ExecuteResult,
 		ExecuteTime:     u.ExecuteTime,
 		ErrorMessage:    u.ErrorMessage,
+		ExecuteOutput:   u.ExecuteOutput,
 		ExecuteDuration: u.ExecuteDuration,
+		ExecuteNode:     u.ExecuteNode,
+		ExecutionID:     u.ExecutionID,
+		Attempt:         u.Attempt,
+		ParentLogID:     u.ParentLogID,
 		CreatedAt:       u.CreatedAt,
 	}
 }
@@ -288,7 +328,12 @@ func fromDomainMapper(u *domainTaskExecution.TaskExecutionLog) *TaskExecutionLog
 		ExecuteResult:   u.ExecuteResult,
 		ExecuteTime:     u.ExecuteTime,
 		ErrorMessage:    u.ErrorMessage,
+		ExecuteOutput:   u.ExecuteOutput,
 		ExecuteDuration: u.ExecuteDuration,
+		ExecuteNode:     u.ExecuteNode,
+		ExecutionID:     u.ExecutionID,
+		Attempt:         u.Attempt,
+		ParentLogID:     u.ParentLogID,
 		CreatedAt:       u.CreatedAt,
 	}
 }
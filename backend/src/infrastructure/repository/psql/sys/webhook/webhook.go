@@ -0,0 +1,224 @@
+package webhook
+
+import (
+	"strings"
+	"time"
+
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainWebhook "github.com/gbrayhan/microservices-go/src/domain/sys/webhook"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type Webhook struct {
+	ID          int            `gorm:"primaryKey" json:"id"`
+	Name        string         `gorm:"size:100;not null" json:"name"`
+	URL         string         `gorm:"size:500;not null" json:"url"`
+	Secret      string         `gorm:"size:200;not null" json:"-"`
+	EventTypes  string         `gorm:"size:1000;not null" json:"event_types"` // 逗号分隔，* 表示全部
+	Active      bool           `gorm:"not null;index" json:"active"`
+	Description string         `gorm:"size:500" json:"description"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName 指定表名
+func (Webhook) TableName() string {
+	return "sys_webhooks"
+}
+
+type WebhookDelivery struct {
+	ID           int64     `gorm:"primaryKey" json:"id"`
+	WebhookID    int       `gorm:"not null;index:idx_webhook_delivery_webhook" json:"webhook_id"`
+	EventID      string    `gorm:"size:64;not null;index" json:"event_id"`
+	EventType    string    `gorm:"size:100;not null" json:"event_type"`
+	Attempt      int       `gorm:"not null" json:"attempt"`
+	Success      bool      `gorm:"not null" json:"success"`
+	StatusCode   int       `json:"status_code"`
+	DurationMs   int64     `json:"duration_ms"`
+	Error        string    `gorm:"type:text" json:"error"`
+	ResponseBody string    `gorm:"type:text" json:"response_body"`
+	CreatedAt    time.Time `gorm:"index:idx_webhook_delivery_webhook" json:"created_at"`
+}
+
+// TableName 指定表名
+func (WebhookDelivery) TableName() string {
+	return "sys_webhook_deliveries"
+}
+
+type IWebhookRepository interface {
+	GetAll() (*[]domainWebhook.Webhook, error)
+	GetActive() (*[]domainWebhook.Webhook, error)
+	GetByID(id int) (*domainWebhook.Webhook, error)
+	Create(webhook *domainWebhook.Webhook) (*domainWebhook.Webhook, error)
+	Update(id int, webhookMap map[string]interface{}) (*domainWebhook.Webhook, error)
+	Delete(id int) error
+	CreateDelivery(delivery *domainWebhook.WebhookDelivery) error
+	GetDeliveries(webhookID int, limit int) (*[]domainWebhook.WebhookDelivery, error)
+}
+
+type Repository struct {
+	DB     *gorm.DB
+	Logger *logger.Logger
+}
+
+func NewWebhookRepository(db *gorm.DB, loggerInstance *logger.Logger) IWebhookRepository {
+	return &Repository{DB: db, Logger: loggerInstance}
+}
+
+func (r *Repository) GetAll() (*[]domainWebhook.Webhook, error) {
+	var webhooks []Webhook
+	if err := r.DB.Order("id").Find(&webhooks).Error; err != nil {
+		r.Logger.Error("Error getting all webhooks", zap.Error(err))
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return arrayToDomainMapper(&webhooks), nil
+}
+
+func (r *Repository) GetActive() (*[]domainWebhook.Webhook, error) {
+	var webhooks []Webhook
+	if err := r.DB.Where("active = ?", true).Order("id").Find(&webhooks).Error; err != nil {
+		r.Logger.Error("Error getting active webhooks", zap.Error(err))
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return arrayToDomainMapper(&webhooks), nil
+}
+
+func (r *Repository) GetByID(id int) (*domainWebhook.Webhook, error) {
+	var webhook Webhook
+	if err := r.DB.Where("id = ?", id).First(&webhook).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domainErrors.NewAppErrorWithType(domainErrors.NotFound)
+		}
+		r.Logger.Error("Error getting webhook by ID", zap.Error(err), zap.Int("id", id))
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return webhook.toDomainMapper(), nil
+}
+
+func (r *Repository) Create(webhook *domainWebhook.Webhook) (*domainWebhook.Webhook, error) {
+	row := fromDomainMapper(webhook)
+	if err := r.DB.Create(row).Error; err != nil {
+		r.Logger.Error("Error creating webhook", zap.Error(err), zap.String("url", webhook.URL))
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return row.toDomainMapper(), nil
+}
+
+// Update event_types 可以是 []string，写入前转换为逗号分隔
+func (r *Repository) Update(id int, webhookMap map[string]interface{}) (*domainWebhook.Webhook, error) {
+	delete(webhookMap, "id")
+	delete(webhookMap, "created_at")
+	delete(webhookMap, "updated_at")
+	if eventTypes, ok := webhookMap["event_types"].([]string); ok {
+		webhookMap["event_types"] = strings.Join(eventTypes, ",")
+	}
+	tx := r.DB.Model(&Webhook{ID: id}).Updates(webhookMap)
+	if tx.Error != nil {
+		r.Logger.Error("Error updating webhook", zap.Error(tx.Error), zap.Int("id", id))
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	return r.GetByID(id)
+}
+
+func (r *Repository) Delete(id int) error {
+	tx := r.DB.Delete(&Webhook{}, id)
+	if tx.Error != nil {
+		r.Logger.Error("Error deleting webhook", zap.Error(tx.Error), zap.Int("id", id))
+		return domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	if tx.RowsAffected == 0 {
+		return domainErrors.NewAppErrorWithType(domainErrors.NotFound)
+	}
+	return nil
+}
+
+func (r *Repository) CreateDelivery(delivery *domainWebhook.WebhookDelivery) error {
+	row := &WebhookDelivery{
+		WebhookID:    delivery.WebhookID,
+		EventID:      delivery.EventID,
+		EventType:    delivery.EventType,
+		Attempt:      delivery.Attempt,
+		Success:      delivery.Success,
+		StatusCode:   delivery.StatusCode,
+		DurationMs:   delivery.DurationMs,
+		Error:        delivery.Error,
+		ResponseBody: delivery.ResponseBody,
+	}
+	if err := r.DB.Create(row).Error; err != nil {
+		r.Logger.Error("Error creating webhook delivery", zap.Error(err),
+			zap.Int("webhook_id", delivery.WebhookID), zap.String("event_id", delivery.EventID))
+		return domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	delivery.ID = row.ID
+	delivery.CreatedAt = row.CreatedAt
+	return nil
+}
+
+// GetDeliveries 最近的投递记录，按时间倒序
+func (r *Repository) GetDeliveries(webhookID int, limit int) (*[]domainWebhook.WebhookDelivery, error) {
+	var rows []WebhookDelivery
+	if err := r.DB.Where("webhook_id = ?", webhookID).Order("id DESC").Limit(limit).Find(&rows).Error; err != nil {
+		r.Logger.Error("Error getting webhook deliveries", zap.Error(err), zap.Int("webhook_id", webhookID))
+		return nil, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	deliveries := make([]domainWebhook.WebhookDelivery, len(rows))
+	for i, row := range rows {
+		deliveries[i] = domainWebhook.WebhookDelivery{
+			ID:           row.ID,
+			WebhookID:    row.WebhookID,
+			EventID:      row.EventID,
+			EventType:    row.EventType,
+			Attempt:      row.Attempt,
+			Success:      row.Success,
+			StatusCode:   row.StatusCode,
+			DurationMs:   row.DurationMs,
+			Error:        row.Error,
+			ResponseBody: row.ResponseBody,
+			CreatedAt:    row.CreatedAt,
+		}
+	}
+	return &deliveries, nil
+}
+
+func (w *Webhook) toDomainMapper() *domainWebhook.Webhook {
+	eventTypes := make([]string, 0)
+	for _, eventType := range strings.Split(w.EventTypes, ",") {
+		if eventType = strings.TrimSpace(eventType); eventType != "" {
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+	return &domainWebhook.Webhook{
+		ID:          w.ID,
+		Name:        w.Name,
+		URL:         w.URL,
+		Secret:      w.Secret,
+		EventTypes:  eventTypes,
+		Active:      w.Active,
+		Description: w.Description,
+		CreatedAt:   w.CreatedAt,
+		UpdatedAt:   w.UpdatedAt,
+	}
+}
+
+func fromDomainMapper(w *domainWebhook.Webhook) *Webhook {
+	return &Webhook{
+		ID:          w.ID,
+		Name:        w.Name,
+		URL:         w.URL,
+		Secret:      w.Secret,
+		EventTypes:  strings.Join(w.EventTypes, ","),
+		Active:      w.Active,
+		Description: w.Description,
+	}
+}
+
+func arrayToDomainMapper(rows *[]Webhook) *[]domainWebhook.Webhook {
+	webhooks := make([]domainWebhook.Webhook, len(*rows))
+	for i, row := range *rows {
+		webhooks[i] = *row.toDomainMapper()
+	}
+	return &webhooks
+}
@@ -8,8 +8,10 @@ import (
 	"github.com/gbrayhan/microservices-go/src/domain"
 	"github.com/gbrayhan/microservices-go/src/domain/constants"
 	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainOutbox "github.com/gbrayhan/microservices-go/src/domain/sys/outbox"
 	domainUser "github.com/gbrayhan/microservices-go/src/domain/user"
 	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	outboxRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/outbox"
 	roleRepo "github.com/gbrayhan/microservices-go/src/infrastructure/repository/psql/sys/role"
 	"github.com/gbrayhan/microservices-go/src/infrastructure/repository/utils"
 	"go.uber.org/zap"
@@ -57,6 +59,7 @@ var ColumnsUserMapping = map[string]string{
 type UserRepositoryInterface interface {
 	GetAll() (*[]domainUser.User, error)
 	Create(userDomain *domainUser.User) (*domainUser.User, error)
+	CreateWithOutbox(userDomain *domainUser.User, newEvent func(*domainUser.User) (*domainOutbox.OutboxEvent, error)) (*domainUser.User, error)
 	GetByID(id int) (*domainUser.User, error)
 	GetByEmail(email string) (*domainUser.User, error)
 	GetByUsername(username string) (*domainUser.User, error)
@@ -111,6 +114,28 @@ func (r *Repository) Create(userDomain *domainUser.User) (*domainUser.User, erro
 	return userRepository.toDomainMapper(), err
 }
 
+// CreateWithOutbox 创建用户并在同一事务中写入发件箱事件，newEvent 可以使用新用户的 ID
+func (r *Repository) CreateWithOutbox(userDomain *domainUser.User, newEvent func(*domainUser.User) (*domainOutbox.OutboxEvent, error)) (*domainUser.User, error) {
+	r.Logger.Info("Creating new user with outbox event", zap.String("email", userDomain.Email))
+	userRepository := fromDomainMapper(userDomain)
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(userRepository).Error; err != nil {
+			return err
+		}
+		event, err := newEvent(userRepository.toDomainMapper())
+		if err != nil {
+			return err
+		}
+		return outboxRepo.Insert(tx, event)
+	})
+	if err != nil {
+		r.Logger.Error("Error creating user", zap.Error(err), zap.String("email", userDomain.Email))
+		return &domainUser.User{}, domainErrors.NewAppErrorWithType(domainErrors.UnknownError)
+	}
+	r.Logger.Info("Successfully created user", zap.String("email", userDomain.Email), zap.Int64("id", userRepository.ID))
+	return userRepository.toDomainMapper(), nil
+}
+
 func (r *Repository) GetByID(id int) (*domainUser.User, error) {
 	var user User
 	err := r.DB.Where("id = ?", id).Preload("Roles", func(db *gorm.DB) *gorm.DB {
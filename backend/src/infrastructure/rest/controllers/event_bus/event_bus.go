@@ -0,0 +1,95 @@
+package event_bus
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainEventBus "github.com/gbrayhan/microservices-go/src/domain/sys/event_bus"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type IEventBusController interface {
+	ListDeadLetters(ctx *gin.Context)
+	ReplayDeadLetters(ctx *gin.Context)
+}
+
+type EventBusController struct {
+	eventBusService domainEventBus.IEventBusService
+	Logger          *logger.Logger
+}
+
+// Structures
+type ReplayDeadLettersRequest struct {
+	MessageIDs []string `json:"message_ids"`
+	Limit      int      `json:"limit"`
+}
+
+func NewEventBusController(eventBusService domainEventBus.IEventBusService, loggerInstance *logger.Logger) IEventBusController {
+	return &EventBusController{eventBusService: eventBusService, Logger: loggerInstance}
+}
+
+// ListDeadLetters
+// @Summary list dead letters
+// @Description peek the oldest messages in the dead letter queue without removing them
+// @Tags event bus
+// @Accept json
+// @Produce json
+// @Param limit query int false "max messages to return, default 50, max 500"
+// @Success 200 {object} domain.CommonResponse[[]domainEventBus.DeadLetter]
+// @Router /v1/event_bus/dead-letters [get]
+func (c *EventBusController) ListDeadLetters(ctx *gin.Context) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "0"))
+	if err != nil {
+		_ = ctx.Error(domainErrors.NewAppError(errors.New("limit is invalid"), domainErrors.ValidationError))
+		return
+	}
+	deadLetters, err := c.eventBusService.ListDeadLetters(limit)
+	if err != nil {
+		c.Logger.Error("Error listing dead letters", zap.Error(err))
+		_ = ctx.Error(err)
+		return
+	}
+	response := controllers.NewCommonResponseBuilder[*[]domainEventBus.DeadLetter]().
+		Data(deadLetters).
+		Message("success").
+		Status(0).
+		Build()
+	ctx.JSON(http.StatusOK, response)
+}
+
+// ReplayDeadLetters
+// @Summary replay dead letters
+// @Description move dead letters back to the event queue, by message id or the oldest ones when no id is given
+// @Tags event bus
+// @Accept json
+// @Produce json
+// @Param request body ReplayDeadLettersRequest false "message ids or limit"
+// @Success 200 {object} domain.CommonResponse[domainEventBus.ReplayResult]
+// @Router /v1/event_bus/dead-letters/replay [post]
+func (c *EventBusController) ReplayDeadLetters(ctx *gin.Context) {
+	var request ReplayDeadLettersRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := controllers.BindJSON(ctx, &request); err != nil {
+			c.Logger.Error("Error binding JSON for dead letter replay", zap.Error(err))
+			_ = ctx.Error(domainErrors.NewAppError(err, domainErrors.ValidationError))
+			return
+		}
+	}
+	result, err := c.eventBusService.ReplayDeadLetters(request.MessageIDs, request.Limit)
+	if err != nil {
+		c.Logger.Error("Error replaying dead letters", zap.Error(err))
+		_ = ctx.Error(err)
+		return
+	}
+	response := controllers.NewCommonResponseBuilder[*domainEventBus.ReplayResult]().
+		Data(result).
+		Message("success").
+		Status(0).
+		Build()
+	ctx.JSON(http.StatusOK, response)
+}
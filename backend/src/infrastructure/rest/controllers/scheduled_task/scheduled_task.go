@@ -3,6 +3,7 @@ package scheduled_task
 import (
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"time"
@@ -25,29 +26,81 @@ type DeleteBatchScheduledTaskRequest struct {
 
 // Structures
 type NewScheduledTaskRequest struct {
-	ID              int            `json:"id"`
-	TaskName        string         `json:"task_name"  binding:"required"`
-	TaskDescription string         `json:"task_description"  binding:"required"`
-	CronExpression  string         `json:"cron_expression"  binding:"required"`
-	TaskParams      datatypes.JSON `json:"task_params"`
-	TaskType        string         `json:"task_type"  binding:"required"`
-	ExecType        string         `json:"exec_type"  binding:"required"`
-	Status          int            `json:"status"  binding:"required"`
+	ID                       int                     `json:"id"`
+	TaskName                 string                  `json:"task_name"  binding:"required"`
+	TaskDescription          string                  `json:"task_description"  binding:"required"`
+	CronExpression           string                  `json:"cron_expression"  binding:"required"`
+	TaskParams               datatypes.JSON          `json:"task_params"`
+	TaskType                 string                  `json:"task_type"  binding:"required"`
+	ExecType                 string                  `json:"exec_type"  binding:"required"`
+	Status                   int                     `json:"status"  binding:"required"`
+	RetryMaxAttempts         int                     `json:"retry_max_attempts" binding:"omitempty,min=1,max=20"`
+	RetryBackoff             string                  `json:"retry_backoff" binding:"omitempty,oneof=fixed exponential"`
+	RetryIntervalSeconds     int                     `json:"retry_interval_seconds" binding:"omitempty,min=1"`
+	RetryOnErrors            string                  `json:"retry_on_errors" binding:"omitempty,lt=500"`
+	TimeoutSeconds           int                     `json:"timeout_seconds" binding:"omitempty,min=1"`
+	MisfirePolicy            string                  `json:"misfire_policy" binding:"omitempty,oneof=skip fire_once"`
+	ConcurrencyPolicy        string                  `json:"concurrency_policy" binding:"omitempty,oneof=allow forbid replace"`
+	Timezone                 string                  `json:"timezone" binding:"omitempty,lt=64"`
+	AlertConsecutiveFailures int                     `json:"alert_consecutive_failures" binding:"omitempty,min=0"`
+	AlertMaxDurationSeconds  int                     `json:"alert_max_duration_seconds" binding:"omitempty,min=0"`
+	AlertEmails              string                  `json:"alert_emails" binding:"omitempty,lt=500"`
+	BlackoutWindows          datatypes.JSON          `json:"blackout_windows"`
+	Dependencies             []TaskDependencyRequest `json:"dependencies" binding:"omitempty,dive"`
+}
+
+type TaskDependencyRequest struct {
+	UpstreamTaskID   int    `json:"upstream_task_id" binding:"required,min=1"`
+	TriggerCondition string `json:"trigger_condition" binding:"omitempty,oneof=success failure always"`
+}
+
+type RunScheduledTaskRequest struct {
+	TaskParams datatypes.JSON `json:"task_params"`
+}
+
+type CronPreviewRequest struct {
+	CronExpression string `json:"cron_expression" binding:"required"`
+	Timezone       string `json:"timezone"`                                // IANA 时区，如 Asia/Shanghai，默认使用调度器时区
+	Count          int    `json:"count" binding:"omitempty,min=1,max=100"` // 返回的触发次数，默认 5
+}
+
+type PauseScheduledTaskRequest struct {
+	Until *time.Time `json:"until"` // 暂停到期时间（RFC3339），为空表示暂停到手动恢复
+}
+
+type ResponseRunScheduledTask struct {
+	TaskID      int    `json:"task_id"`
+	ExecutionID string `json:"execution_id"`
 }
 
 type ResponseScheduledTask struct {
-	ID              int               `json:"id"`
-	TaskName        string            `json:"task_name"`
-	TaskDescription string            `json:"task_description"`
-	CronExpression  string            `json:"cron_expression"`
-	TaskParams      datatypes.JSON    `json:"task_params"`
-	Status          int               `json:"status"`
-	TaskType        string            `json:"task_type"`
-	ExecType        string            `json:"exec_type"`
-	CreatedAt       domain.CustomTime `json:"created_at,omitempty"`
-	UpdatedAt       domain.CustomTime `json:"updated_at,omitempty"`
-	LastExecuteTime domain.CustomTime `json:"last_execute_time"`
-	NextExecuteTime domain.CustomTime `json:"next_execute_time"`
+	ID                       int                                  `json:"id"`
+	TaskName                 string                               `json:"task_name"`
+	TaskDescription          string                               `json:"task_description"`
+	CronExpression           string                               `json:"cron_expression"`
+	TaskParams               datatypes.JSON                       `json:"task_params"`
+	Status                   int                                  `json:"status"`
+	TaskType                 string                               `json:"task_type"`
+	ExecType                 string                               `json:"exec_type"`
+	RetryMaxAttempts         int                                  `json:"retry_max_attempts"`
+	RetryBackoff             string                               `json:"retry_backoff"`
+	RetryIntervalSeconds     int                                  `json:"retry_interval_seconds"`
+	RetryOnErrors            string                               `json:"retry_on_errors"`
+	TimeoutSeconds           int                                  `json:"timeout_seconds"`
+	MisfirePolicy            string                               `json:"misfire_policy"`
+	ConcurrencyPolicy        string                               `json:"concurrency_policy"`
+	Timezone                 string                               `json:"timezone"`
+	AlertConsecutiveFailures int                                  `json:"alert_consecutive_failures"`
+	AlertMaxDurationSeconds  int                                  `json:"alert_max_duration_seconds"`
+	AlertEmails              string                               `json:"alert_emails"`
+	Paused                   bool                                 `json:"paused"`
+	PausedUntil              *time.Time                           `json:"paused_until"`
+	BlackoutWindows          datatypes.JSON                       `json:"blackout_windows"`
+	CreatedAt                domain.CustomTime                    `json:"created_at,omitempty"`
+	UpdatedAt                domain.CustomTime                    `json:"updated_at,omitempty"`
+	LastExecuteTime          domain.CustomTime                    `json:"last_execute_time"`
+	NextExecuteTime          domain.CustomTime                    `json:"next_execute_time"`
+	Dependencies             []domainScheduledTask.TaskDependency `json:"dependencies"`
 }
 type IScheduledTaskController interface {
 	NewScheduledTask(ctx *gin.Context)
@@ -63,6 +116,16 @@ type IScheduledTaskController interface {
 	EnableTaskById(ctx *gin.Context)
 	DisableTaskById(ctx *gin.Context)
 	ReloadAllTasks(ctx *gin.Context)
+	CancelTaskById(ctx *gin.Context)
+	RunTaskById(ctx *gin.Context)
+	GetTaskDAG(ctx *gin.Context)
+	GetFunctions(ctx *gin.Context)
+	GetTaskTypes(ctx *gin.Context)
+	PreviewCron(ctx *gin.Context)
+	GetTaskStats(ctx *gin.Context)
+	PauseTaskById(ctx *gin.Context)
+	ResumeTaskById(ctx *gin.Context)
+	GetDashboard(ctx *gin.Context)
 }
 type ScheduledTasController struct {
 	scheduledTaskService domainScheduledTask.IScheduledTaskService
@@ -435,18 +498,33 @@ func (c *ScheduledTasController) DeleteScheduledTasks(ctx *gin.Context) {
 func domainToResponseMapper(domainScheduledTask *domainScheduledTask.ScheduledTask) *ResponseScheduledTask {
 
 	return &ResponseScheduledTask{
-		ID:              domainScheduledTask.ID,
-		TaskName:        domainScheduledTask.TaskName,
-		TaskType:        domainScheduledTask.TaskType,
-		TaskParams:      domainScheduledTask.TaskParams,
-		TaskDescription: domainScheduledTask.TaskDescription,
-		CronExpression:  domainScheduledTask.CronExpression,
-		Status:          domainScheduledTask.Status,
-		LastExecuteTime: domain.CustomTime{Time: domainScheduledTask.LastExecuteTime},
-		NextExecuteTime: domain.CustomTime{Time: domainScheduledTask.NextExecuteTime},
-		ExecType:        domainScheduledTask.ExecType,
-		CreatedAt:       domain.CustomTime{Time: domainScheduledTask.CreatedAt},
-		UpdatedAt:       domain.CustomTime{Time: domainScheduledTask.UpdatedAt},
+		ID:                       domainScheduledTask.ID,
+		TaskName:                 domainScheduledTask.TaskName,
+		TaskType:                 domainScheduledTask.TaskType,
+		TaskParams:               domainScheduledTask.TaskParams,
+		TaskDescription:          domainScheduledTask.TaskDescription,
+		CronExpression:           domainScheduledTask.CronExpression,
+		Status:                   domainScheduledTask.Status,
+		LastExecuteTime:          domain.CustomTime{Time: domainScheduledTask.LastExecuteTime},
+		NextExecuteTime:          domain.CustomTime{Time: domainScheduledTask.NextExecuteTime},
+		ExecType:                 domainScheduledTask.ExecType,
+		RetryMaxAttempts:         domainScheduledTask.RetryMaxAttempts,
+		RetryBackoff:             domainScheduledTask.RetryBackoff,
+		RetryIntervalSeconds:     domainScheduledTask.RetryIntervalSeconds,
+		RetryOnErrors:            domainScheduledTask.RetryOnErrors,
+		TimeoutSeconds:           domainScheduledTask.TimeoutSeconds,
+		MisfirePolicy:            domainScheduledTask.MisfirePolicy,
+		ConcurrencyPolicy:        domainScheduledTask.ConcurrencyPolicy,
+		Timezone:                 domainScheduledTask.Timezone,
+		AlertConsecutiveFailures: domainScheduledTask.AlertConsecutiveFailures,
+		AlertMaxDurationSeconds:  domainScheduledTask.AlertMaxDurationSeconds,
+		AlertEmails:              domainScheduledTask.AlertEmails,
+		Paused:                   domainScheduledTask.Paused,
+		PausedUntil:              domainScheduledTask.PausedUntil,
+		BlackoutWindows:          domainScheduledTask.BlackoutWindows,
+		CreatedAt:                domain.CustomTime{Time: domainScheduledTask.CreatedAt},
+		UpdatedAt:                domain.CustomTime{Time: domainScheduledTask.UpdatedAt},
+		Dependencies:             domainScheduledTask.Dependencies,
 	}
 }
 
@@ -460,14 +538,38 @@ func arrayDomainToResponseMapper(scheduled_task *[]domainScheduledTask.Scheduled
 
 func toUsecaseMapper(req *NewScheduledTaskRequest) *domainScheduledTask.ScheduledTask {
 	return &domainScheduledTask.ScheduledTask{
-		CronExpression:  req.CronExpression,
-		Status:          req.Status,
-		TaskDescription: req.TaskDescription,
-		TaskName:        req.TaskName,
-		TaskParams:      req.TaskParams,
-		TaskType:        req.TaskType,
-		ExecType:        req.ExecType,
+		CronExpression:           req.CronExpression,
+		Status:                   req.Status,
+		TaskDescription:          req.TaskDescription,
+		TaskName:                 req.TaskName,
+		TaskParams:               req.TaskParams,
+		TaskType:                 req.TaskType,
+		ExecType:                 req.ExecType,
+		RetryMaxAttempts:         req.RetryMaxAttempts,
+		RetryBackoff:             req.RetryBackoff,
+		RetryIntervalSeconds:     req.RetryIntervalSeconds,
+		RetryOnErrors:            req.RetryOnErrors,
+		TimeoutSeconds:           req.TimeoutSeconds,
+		MisfirePolicy:            req.MisfirePolicy,
+		ConcurrencyPolicy:        req.ConcurrencyPolicy,
+		Timezone:                 req.Timezone,
+		AlertConsecutiveFailures: req.AlertConsecutiveFailures,
+		AlertMaxDurationSeconds:  req.AlertMaxDurationSeconds,
+		AlertEmails:              req.AlertEmails,
+		BlackoutWindows:          req.BlackoutWindows,
+		Dependencies:             dependencyRequestToDomainMapper(req.Dependencies),
+	}
+}
+
+func dependencyRequestToDomainMapper(requests []TaskDependencyRequest) []domainScheduledTask.TaskDependency {
+	dependencies := make([]domainScheduledTask.TaskDependency, len(requests))
+	for i, req := range requests {
+		dependencies[i] = domainScheduledTask.TaskDependency{
+			UpstreamTaskID:   req.UpstreamTaskID,
+			TriggerCondition: req.TriggerCondition,
+		}
 	}
+	return dependencies
 }
 
 // ReloadAllTasks implements IScheduledTaskController.
@@ -560,3 +662,318 @@ func (c *ScheduledTasController) DisableTaskById(ctx *gin.Context) {
 		Status:  0,
 	})
 }
+
+// CancelTaskById implements IScheduledTaskController.
+// @Summary cancel running task
+// @Description cancel the running execution of a task, the schedule is kept
+// @Tags task
+// @Accept json
+// @Produce json
+// @Success 200 {object} domain.CommonResponse[int]
+// @Router /v1/scheduled_task/{id}/cancel [post]
+func (c *ScheduledTasController) CancelTaskById(ctx *gin.Context) {
+	scheduledTaskID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		c.Logger.Error("Invalid ScheduledTask ID parameter", zap.Error(err), zap.String("id", ctx.Param("id")))
+		appError := domainErrors.NewAppError(errors.New("ScheduledTask id is invalid"), domainErrors.ValidationError)
+		_ = ctx.Error(appError)
+		return
+	}
+	c.Logger.Info("Cancelling ScheduledTask execution by ID", zap.Int("id", scheduledTaskID))
+	err = c.scheduledTaskService.CancelTask(scheduledTaskID)
+	if err != nil {
+		c.Logger.Error("Error cancelling ScheduledTask execution by ID", zap.Error(err), zap.Int("id", scheduledTaskID))
+		_ = ctx.Error(err)
+		return
+	}
+	c.Logger.Info("Successfully cancelled ScheduledTask execution by ID", zap.Int("id", scheduledTaskID))
+	ctx.JSON(http.StatusOK, domain.CommonResponse[int]{
+		Data:    scheduledTaskID,
+		Message: "resource cancelled successfully",
+		Status:  0,
+	})
+}
+
+// GetTaskDAG implements IScheduledTaskController.
+// @Summary task dependency graph
+// @Description get all tasks and their dependencies as a DAG
+// @Tags task
+// @Accept json
+// @Produce json
+// @Success 200 {object} domain.CommonResponse[domainScheduledTask.TaskDAG]
+// @Router /v1/scheduled_task/dag [get]
+func (c *ScheduledTasController) GetTaskDAG(ctx *gin.Context) {
+	c.Logger.Info("Getting ScheduledTask DAG")
+	dag, err := c.scheduledTaskService.GetDAG()
+	if err != nil {
+		c.Logger.Error("Error getting ScheduledTask DAG", zap.Error(err))
+		_ = ctx.Error(err)
+		return
+	}
+	c.Logger.Info("Successfully retrieved ScheduledTask DAG",
+		zap.Int("nodes", len(dag.Nodes)),
+		zap.Int("edges", len(dag.Edges)))
+	response := controllers.NewCommonResponseBuilder[*domainScheduledTask.TaskDAG]().
+		Data(dag).
+		Message("success").
+		Status(0).
+		Build()
+	ctx.JSON(http.StatusOK, response)
+}
+
+// RunTaskById implements IScheduledTaskController.
+// @Summary run task now
+// @Description execute a task immediately, task_params in body overrides the saved params for this run only
+// @Tags task
+// @Accept json
+// @Produce json
+// @Param book body RunScheduledTaskRequest false  "JSON Data"
+// @Success 200 {object} domain.CommonResponse[ResponseRunScheduledTask]
+// @Router /v1/scheduled_task/{id}/run [post]
+func (c *ScheduledTasController) RunTaskById(ctx *gin.Context) {
+	scheduledTaskID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		c.Logger.Error("Invalid ScheduledTask ID parameter", zap.Error(err), zap.String("id", ctx.Param("id")))
+		appError := domainErrors.NewAppError(errors.New("ScheduledTask id is invalid"), domainErrors.ValidationError)
+		_ = ctx.Error(appError)
+		return
+	}
+	// 请求体可选
+	var request RunScheduledTaskRequest
+	if err := controllers.BindJSON(ctx, &request); err != nil && !errors.Is(err, io.EOF) {
+		c.Logger.Error("Error binding JSON for running ScheduledTask", zap.Error(err))
+		appError := domainErrors.NewAppError(err, domainErrors.ValidationError)
+		_ = ctx.Error(appError)
+		return
+	}
+	c.Logger.Info("Running ScheduledTask by ID", zap.Int("id", scheduledTaskID))
+	executionID, err := c.scheduledTaskService.RunTask(scheduledTaskID, request.TaskParams)
+	if err != nil {
+		c.Logger.Error("Error running ScheduledTask by ID", zap.Error(err), zap.Int("id", scheduledTaskID))
+		_ = ctx.Error(err)
+		return
+	}
+	c.Logger.Info("Successfully triggered ScheduledTask by ID",
+		zap.Int("id", scheduledTaskID),
+		zap.String("execution_id", executionID))
+	ctx.JSON(http.StatusOK, domain.CommonResponse[ResponseRunScheduledTask]{
+		Data: ResponseRunScheduledTask{
+			TaskID:      scheduledTaskID,
+			ExecutionID: executionID,
+		},
+		Message: "resource triggered successfully",
+		Status:  0,
+	})
+}
+
+// GetFunctions implements IScheduledTaskController.
+// @Summary registered functions
+// @Description list function_name values for function tasks with description, params JSON Schema and default timeout
+// @Tags task
+// @Accept json
+// @Produce json
+// @Success 200 {object} domain.CommonResponse[[]domainScheduledTask.TaskFunctionInfo]
+// @Router /v1/scheduled_task/functions [get]
+func (c *ScheduledTasController) GetFunctions(ctx *gin.Context) {
+	functions := c.scheduledTaskService.GetFunctions()
+	c.Logger.Info("Successfully retrieved registered functions", zap.Int("count", len(functions)))
+	response := controllers.NewCommonResponseBuilder[[]domainScheduledTask.TaskFunctionInfo]().
+		Data(functions).
+		Message("success").
+		Status(0).
+		Build()
+	ctx.JSON(http.StatusOK, response)
+}
+
+// GetTaskTypes implements IScheduledTaskController.
+// @Summary registered task types
+// @Description list task types with description and task_params JSON Schema
+// @Tags task
+// @Accept json
+// @Produce json
+// @Success 200 {object} domain.CommonResponse[[]domainScheduledTask.TaskTypeInfo]
+// @Router /v1/scheduled_task/types [get]
+func (c *ScheduledTasController) GetTaskTypes(ctx *gin.Context) {
+	types := c.scheduledTaskService.GetTaskTypes()
+	c.Logger.Info("Successfully retrieved task types", zap.Int("count", len(types)))
+	response := controllers.NewCommonResponseBuilder[[]domainScheduledTask.TaskTypeInfo]().
+		Data(types).
+		Message("success").
+		Status(0).
+		Build()
+	ctx.JSON(http.StatusOK, response)
+}
+
+// PreviewCron implements IScheduledTaskController.
+// @Summary preview cron expression
+// @Description validate a cron expression, describe it and list the next fire times in the given timezone
+// @Tags task
+// @Accept json
+// @Produce json
+// @Param book body CronPreviewRequest true  "JSON Data"
+// @Success 200 {object} domain.CommonResponse[domainScheduledTask.CronPreview]
+// @Router /v1/scheduled_task/cron/preview [post]
+func (c *ScheduledTasController) PreviewCron(ctx *gin.Context) {
+	var request CronPreviewRequest
+	if err := controllers.BindJSON(ctx, &request); err != nil {
+		c.Logger.Error("Error binding JSON for cron preview", zap.Error(err))
+		appError := domainErrors.NewAppError(err, domainErrors.ValidationError)
+		_ = ctx.Error(appError)
+		return
+	}
+	preview, err := c.scheduledTaskService.PreviewCron(request.CronExpression, request.Timezone, request.Count)
+	if err != nil {
+		c.Logger.Error("Error previewing cron expression", zap.Error(err), zap.String("cron", request.CronExpression))
+		_ = ctx.Error(err)
+		return
+	}
+	response := controllers.NewCommonResponseBuilder[*domainScheduledTask.CronPreview]().
+		Data(preview).
+		Message("success").
+		Status(0).
+		Build()
+	ctx.JSON(http.StatusOK, response)
+}
+
+// GetTaskStats implements IScheduledTaskController.
+// @Summary task execution stats
+// @Description success rate, p50/p95 duration, consecutive failures and the last N runs computed from execution logs
+// @Tags task
+// @Accept json
+// @Produce json
+// @Param id path int true "ScheduledTask ID"
+// @Param days query int false "stats window in days, default 7, max 90"
+// @Param last query int false "number of recent runs to return, default 20, max 100"
+// @Success 200 {object} domain.CommonResponse[domainScheduledTask.TaskStats]
+// @Router /v1/scheduled_task/{id}/stats [get]
+func (c *ScheduledTasController) GetTaskStats(ctx *gin.Context) {
+	scheduledTaskID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		c.Logger.Error("Invalid ScheduledTask ID parameter", zap.Error(err), zap.String("id", ctx.Param("id")))
+		appError := domainErrors.NewAppError(errors.New("ScheduledTask id is invalid"), domainErrors.ValidationError)
+		_ = ctx.Error(appError)
+		return
+	}
+	days, err := strconv.Atoi(ctx.DefaultQuery("days", "0"))
+	if err != nil {
+		_ = ctx.Error(domainErrors.NewAppError(errors.New("days is invalid"), domainErrors.ValidationError))
+		return
+	}
+	last, err := strconv.Atoi(ctx.DefaultQuery("last", "0"))
+	if err != nil {
+		_ = ctx.Error(domainErrors.NewAppError(errors.New("last is invalid"), domainErrors.ValidationError))
+		return
+	}
+	stats, err := c.scheduledTaskService.GetStats(scheduledTaskID, days, last)
+	if err != nil {
+		c.Logger.Error("Error getting ScheduledTask stats", zap.Error(err), zap.Int("id", scheduledTaskID))
+		_ = ctx.Error(err)
+		return
+	}
+	response := controllers.NewCommonResponseBuilder[*domainScheduledTask.TaskStats]().
+		Data(stats).
+		Message("success").
+		Status(0).
+		Build()
+	ctx.JSON(http.StatusOK, response)
+}
+
+// GetDashboard implements IScheduledTaskController.
+// @Summary task dashboard
+// @Description execution summary of all tasks and the tasks that are currently failing
+// @Tags task
+// @Accept json
+// @Produce json
+// @Param hours query int false "stats window in hours, default 24, max 720"
+// @Success 200 {object} domain.CommonResponse[domainScheduledTask.TaskDashboard]
+// @Router /v1/scheduled_task/dashboard [get]
+func (c *ScheduledTasController) GetDashboard(ctx *gin.Context) {
+	hours, err := strconv.Atoi(ctx.DefaultQuery("hours", "0"))
+	if err != nil {
+		_ = ctx.Error(domainErrors.NewAppError(errors.New("hours is invalid"), domainErrors.ValidationError))
+		return
+	}
+	dashboard, err := c.scheduledTaskService.GetDashboard(hours)
+	if err != nil {
+		c.Logger.Error("Error getting ScheduledTask dashboard", zap.Error(err))
+		_ = ctx.Error(err)
+		return
+	}
+	response := controllers.NewCommonResponseBuilder[*domainScheduledTask.TaskDashboard]().
+		Data(dashboard).
+		Message("success").
+		Status(0).
+		Build()
+	ctx.JSON(http.StatusOK, response)
+}
+
+// PauseTaskById implements IScheduledTaskController.
+// @Summary pause task
+// @Description pause a task until the given time or until resumed, fires while paused are logged as skipped
+// @Tags task
+// @Accept json
+// @Produce json
+// @Param id path int true "ScheduledTask ID"
+// @Param body body PauseScheduledTaskRequest false "pause until"
+// @Success 200 {object} domain.CommonResponse[int]
+// @Router /v1/scheduled_task/{id}/pause [post]
+func (c *ScheduledTasController) PauseTaskById(ctx *gin.Context) {
+	scheduledTaskID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		c.Logger.Error("Invalid ScheduledTask ID parameter", zap.Error(err), zap.String("id", ctx.Param("id")))
+		appError := domainErrors.NewAppError(errors.New("ScheduledTask id is invalid"), domainErrors.ValidationError)
+		_ = ctx.Error(appError)
+		return
+	}
+	// 请求体可选
+	var request PauseScheduledTaskRequest
+	if err := controllers.BindJSON(ctx, &request); err != nil && !errors.Is(err, io.EOF) {
+		c.Logger.Error("Error binding JSON for pausing ScheduledTask", zap.Error(err))
+		appError := domainErrors.NewAppError(err, domainErrors.ValidationError)
+		_ = ctx.Error(appError)
+		return
+	}
+	c.Logger.Info("Pausing ScheduledTask by ID", zap.Int("id", scheduledTaskID))
+	if err := c.scheduledTaskService.PauseTask(scheduledTaskID, request.Until); err != nil {
+		c.Logger.Error("Error pausing ScheduledTask by ID", zap.Error(err), zap.Int("id", scheduledTaskID))
+		_ = ctx.Error(err)
+		return
+	}
+	c.Logger.Info("Successfully paused ScheduledTask by ID", zap.Int("id", scheduledTaskID))
+	ctx.JSON(http.StatusOK, domain.CommonResponse[int]{
+		Data:    scheduledTaskID,
+		Message: "resource paused successfully",
+		Status:  0,
+	})
+}
+
+// ResumeTaskById implements IScheduledTaskController.
+// @Summary resume task
+// @Description resume a paused task
+// @Tags task
+// @Accept json
+// @Produce json
+// @Param id path int true "ScheduledTask ID"
+// @Success 200 {object} domain.CommonResponse[int]
+// @Router /v1/scheduled_task/{id}/resume [post]
+func (c *ScheduledTasController) ResumeTaskById(ctx *gin.Context) {
+	scheduledTaskID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		c.Logger.Error("Invalid ScheduledTask ID parameter", zap.Error(err), zap.String("id", ctx.Param("id")))
+		appError := domainErrors.NewAppError(errors.New("ScheduledTask id is invalid"), domainErrors.ValidationError)
+		_ = ctx.Error(appError)
+		return
+	}
+	c.Logger.Info("Resuming ScheduledTask by ID", zap.Int("id", scheduledTaskID))
+	if err := c.scheduledTaskService.ResumeTask(scheduledTaskID); err != nil {
+		c.Logger.Error("Error resuming ScheduledTask by ID", zap.Error(err), zap.Int("id", scheduledTaskID))
+		_ = ctx.Error(err)
+		return
+	}
+	c.Logger.Info("Successfully resumed ScheduledTask by ID", zap.Int("id", scheduledTaskID))
+	ctx.JSON(http.StatusOK, domain.CommonResponse[int]{
+		Data:    scheduledTaskID,
+		Message: "resource resumed successfully",
+		Status:  0,
+	})
+}
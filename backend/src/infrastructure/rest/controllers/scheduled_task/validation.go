@@ -3,12 +3,23 @@ package scheduled_task
 import "github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers"
 
 var customRules = map[string]string{
-	"task_name":        "required,lt=255",
-	"task_description": "required",
-	"cron_expression":  "required,lt=255",
-	"exec_type":        "required,lt=50",
-	"task_type":        "required,lt=100",
-	"task_params":      "required",
+	"task_name":                  "required,lt=255",
+	"task_description":           "required",
+	"cron_expression":            "required,lt=255",
+	"exec_type":                  "required,lt=50",
+	"task_type":                  "required,lt=100",
+	"task_params":                "required",
+	"retry_max_attempts":         "min=1,max=20",
+	"retry_backoff":              "oneof=fixed exponential",
+	"retry_interval_seconds":     "min=1",
+	"retry_on_errors":            "lt=500",
+	"timeout_seconds":            "min=1",
+	"misfire_policy":             "oneof=skip fire_once",
+	"concurrency_policy":         "oneof=allow forbid replace",
+	"timezone":                   "lt=64",
+	"alert_consecutive_failures": "min=0",
+	"alert_max_duration_seconds": "min=0",
+	"alert_emails":               "lt=500",
 }
 
 func updateValidation(request map[string]any) error {
@@ -21,6 +21,11 @@ type ResponseTaskExecutionLog struct {
 	ExecuteResult   int               `json:"execute_result"`
 	ExecuteDuration *int              `json:"execute_duration"`
 	ErrorMessage    string            `json:"error_message"`
+	ExecuteOutput   string            `json:"execute_output"`
+	ExecuteNode     string            `json:"execute_node"`
+	ExecutionID     string            `json:"execution_id"`
+	Attempt         int               `json:"attempt"`
+	ParentLogID     *int              `json:"parent_log_id"`
 	CreatedAt       domain.CustomTime `json:"created_at,omitempty"`
 	UpdatedAt       domain.CustomTime `json:"updated_at,omitempty"`
 }
@@ -163,6 +168,11 @@ func domainToResponseMapper(domainTaskExecutionLog *domainTaskExecutionLog.TaskE
 		ExecuteResult:   domainTaskExecutionLog.ExecuteResult,
 		ExecuteDuration: domainTaskExecutionLog.ExecuteDuration,
 		ErrorMessage:    domainTaskExecutionLog.ErrorMessage,
+		ExecuteOutput:   domainTaskExecutionLog.ExecuteOutput,
+		ExecuteNode:     domainTaskExecutionLog.ExecuteNode,
+		ExecutionID:     domainTaskExecutionLog.ExecutionID,
+		Attempt:         domainTaskExecutionLog.Attempt,
+		ParentLogID:     domainTaskExecutionLog.ParentLogID,
 		CreatedAt:       domain.CustomTime{Time: domainTaskExecutionLog.CreatedAt},
 		UpdatedAt:       domain.CustomTime{Time: domainTaskExecutionLog.UpdatedAt},
 	}
@@ -0,0 +1,305 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gbrayhan/microservices-go/src/domain"
+	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
+	domainWebhook "github.com/gbrayhan/microservices-go/src/domain/sys/webhook"
+	logger "github.com/gbrayhan/microservices-go/src/infrastructure/lib/logger"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/rest/controllers"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Structures
+type NewWebhookRequest struct {
+	Name        string   `json:"name" binding:"required"`
+	URL         string   `json:"url" binding:"required"`
+	Secret      string   `json:"secret"` // 为空时自动生成
+	EventTypes  []string `json:"event_types" binding:"required"`
+	Active      *bool    `json:"active"` // 默认启用
+	Description string   `json:"description"`
+}
+
+type ResponseWebhook struct {
+	ID          int               `json:"id"`
+	Name        string            `json:"name"`
+	URL         string            `json:"url"`
+	Secret      string            `json:"secret,omitempty"` // 只在创建时返回
+	EventTypes  []string          `json:"event_types"`
+	Active      bool              `json:"active"`
+	Description string            `json:"description"`
+	CreatedAt   domain.CustomTime `json:"created_at"`
+	UpdatedAt   domain.CustomTime `json:"updated_at"`
+}
+
+type IWebhookController interface {
+	NewWebhook(ctx *gin.Context)
+	GetAllWebhooks(ctx *gin.Context)
+	GetWebhookByID(ctx *gin.Context)
+	UpdateWebhook(ctx *gin.Context)
+	DeleteWebhook(ctx *gin.Context)
+	GetDeliveries(ctx *gin.Context)
+	SendTestEvent(ctx *gin.Context)
+}
+
+type WebhookController struct {
+	webhookService domainWebhook.IWebhookService
+	Logger         *logger.Logger
+}
+
+func NewWebhookController(webhookService domainWebhook.IWebhookService, loggerInstance *logger.Logger) IWebhookController {
+	return &WebhookController{webhookService: webhookService, Logger: loggerInstance}
+}
+
+// NewWebhook
+// @Summary create webhook
+// @Description register an external webhook for selected event types, the secret is only returned here
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param book body NewWebhookRequest true "JSON Data"
+// @Success 200 {object} controllers.CommonResponseBuilder[ResponseWebhook]
+// @Router /v1/webhook [post]
+func (c *WebhookController) NewWebhook(ctx *gin.Context) {
+	c.Logger.Info("Creating new webhook")
+	var request NewWebhookRequest
+	if err := controllers.BindJSON(ctx, &request); err != nil {
+		c.Logger.Error("Error binding JSON for new webhook", zap.Error(err))
+		appError := domainErrors.NewAppError(err, domainErrors.ValidationError)
+		_ = ctx.Error(appError)
+		return
+	}
+	webhookModel, err := c.webhookService.Create(toUsecaseMapper(&request))
+	if err != nil {
+		c.Logger.Error("Error creating webhook", zap.Error(err), zap.String("name", request.Name))
+		_ = ctx.Error(err)
+		return
+	}
+	response := domainToResponseMapper(webhookModel)
+	response.Secret = webhookModel.Secret
+	c.Logger.Info("Webhook created successfully", zap.Int("id", webhookModel.ID))
+	ctx.JSON(http.StatusOK, controllers.NewCommonResponseBuilder[*ResponseWebhook]().
+		Data(response).
+		Message("success").
+		Status(0).
+		Build())
+}
+
+// GetAllWebhooks
+// @Summary get all webhooks
+// @Description get all webhooks
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Success 200 {object} domain.CommonResponse[[]ResponseWebhook]
+// @Router /v1/webhook [get]
+func (c *WebhookController) GetAllWebhooks(ctx *gin.Context) {
+	c.Logger.Info("Getting all webhooks")
+	webhooks, err := c.webhookService.GetAll()
+	if err != nil {
+		c.Logger.Error("Error getting all webhooks", zap.Error(err))
+		_ = ctx.Error(err)
+		return
+	}
+	c.Logger.Info("Successfully retrieved all webhooks", zap.Int("count", len(*webhooks)))
+	ctx.JSON(http.StatusOK, domain.CommonResponse[*[]ResponseWebhook]{
+		Data: arrayDomainToResponseMapper(webhooks),
+	})
+}
+
+// GetWebhookByID
+// @Summary get webhook
+// @Description get webhook by id
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Success 200 {object} ResponseWebhook
+// @Router /v1/webhook/{id} [get]
+func (c *WebhookController) GetWebhookByID(ctx *gin.Context) {
+	webhookID, ok := c.paramID(ctx)
+	if !ok {
+		return
+	}
+	webhook, err := c.webhookService.GetByID(webhookID)
+	if err != nil {
+		c.Logger.Error("Error getting webhook by ID", zap.Error(err), zap.Int("id", webhookID))
+		_ = ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, domainToResponseMapper(webhook))
+}
+
+// UpdateWebhook
+// @Summary update webhook
+// @Description update name, url, secret, event_types, active or description
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param book body map[string]any true "JSON Data"
+// @Success 200 {object} controllers.CommonResponseBuilder[ResponseWebhook]
+// @Router /v1/webhook/{id} [put]
+func (c *WebhookController) UpdateWebhook(ctx *gin.Context) {
+	webhookID, ok := c.paramID(ctx)
+	if !ok {
+		return
+	}
+	c.Logger.Info("Updating webhook", zap.Int("id", webhookID))
+	var requestMap map[string]any
+	if err := controllers.BindJSONMap(ctx, &requestMap); err != nil {
+		c.Logger.Error("Error binding JSON for webhook update", zap.Error(err), zap.Int("id", webhookID))
+		appError := domainErrors.NewAppError(err, domainErrors.ValidationError)
+		_ = ctx.Error(appError)
+		return
+	}
+	webhookUpdated, err := c.webhookService.Update(webhookID, requestMap)
+	if err != nil {
+		c.Logger.Error("Error updating webhook", zap.Error(err), zap.Int("id", webhookID))
+		_ = ctx.Error(err)
+		return
+	}
+	c.Logger.Info("Webhook updated successfully", zap.Int("id", webhookID))
+	ctx.JSON(http.StatusOK, controllers.NewCommonResponseBuilder[*ResponseWebhook]().
+		Data(domainToResponseMapper(webhookUpdated)).
+		Message("success").
+		Status(0).
+		Build())
+}
+
+// DeleteWebhook
+// @Summary delete webhook
+// @Description delete webhook by id
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Success 200 {object} domain.CommonResponse[int]
+// @Router /v1/webhook/{id} [delete]
+func (c *WebhookController) DeleteWebhook(ctx *gin.Context) {
+	webhookID, ok := c.paramID(ctx)
+	if !ok {
+		return
+	}
+	c.Logger.Info("Deleting webhook", zap.Int("id", webhookID))
+	if err := c.webhookService.Delete(webhookID); err != nil {
+		c.Logger.Error("Error deleting webhook", zap.Error(err), zap.Int("id", webhookID))
+		_ = ctx.Error(err)
+		return
+	}
+	c.Logger.Info("Webhook deleted successfully", zap.Int("id", webhookID))
+	ctx.JSON(http.StatusOK, domain.CommonResponse[int]{
+		Data:    webhookID,
+		Message: "resource deleted successfully",
+		Status:  0,
+	})
+}
+
+// GetDeliveries
+// @Summary get webhook deliveries
+// @Description recent delivery attempts of a webhook, newest first
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param limit query int false "max records, default 50"
+// @Success 200 {object} domain.CommonResponse[[]domainWebhook.WebhookDelivery]
+// @Router /v1/webhook/{id}/deliveries [get]
+func (c *WebhookController) GetDeliveries(ctx *gin.Context) {
+	webhookID, ok := c.paramID(ctx)
+	if !ok {
+		return
+	}
+	limit := 0
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		var err error
+		if limit, err = strconv.Atoi(limitStr); err != nil {
+			appError := domainErrors.NewAppError(errors.New("limit is invalid"), domainErrors.ValidationError)
+			_ = ctx.Error(appError)
+			return
+		}
+	}
+	deliveries, err := c.webhookService.GetDeliveries(webhookID, limit)
+	if err != nil {
+		c.Logger.Error("Error getting webhook deliveries", zap.Error(err), zap.Int("id", webhookID))
+		_ = ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, domain.CommonResponse[*[]domainWebhook.WebhookDelivery]{
+		Data: deliveries,
+	})
+}
+
+// SendTestEvent
+// @Summary send webhook test event
+// @Description deliver a WebhookTest event once and return the delivery result
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Success 200 {object} controllers.CommonResponseBuilder[domainWebhook.WebhookDelivery]
+// @Router /v1/webhook/{id}/test [post]
+func (c *WebhookController) SendTestEvent(ctx *gin.Context) {
+	webhookID, ok := c.paramID(ctx)
+	if !ok {
+		return
+	}
+	delivery, err := c.webhookService.SendTestEvent(webhookID)
+	if err != nil {
+		c.Logger.Error("Error sending webhook test event", zap.Error(err), zap.Int("id", webhookID))
+		_ = ctx.Error(err)
+		return
+	}
+	c.Logger.Info("Webhook test event sent", zap.Int("id", webhookID), zap.Bool("success", delivery.Success))
+	ctx.JSON(http.StatusOK, controllers.NewCommonResponseBuilder[*domainWebhook.WebhookDelivery]().
+		Data(delivery).
+		Message("success").
+		Status(0).
+		Build())
+}
+
+func (c *WebhookController) paramID(ctx *gin.Context) (int, bool) {
+	webhookID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		c.Logger.Error("Invalid webhook ID parameter", zap.Error(err), zap.String("id", ctx.Param("id")))
+		appError := domainErrors.NewAppError(errors.New("webhook id is invalid"), domainErrors.ValidationError)
+		_ = ctx.Error(appError)
+		return 0, false
+	}
+	return webhookID, true
+}
+
+func toUsecaseMapper(request *NewWebhookRequest) *domainWebhook.Webhook {
+	active := true
+	if request.Active != nil {
+		active = *request.Active
+	}
+	return &domainWebhook.Webhook{
+		Name:        request.Name,
+		URL:         request.URL,
+		Secret:      request.Secret,
+		EventTypes:  request.EventTypes,
+		Active:      active,
+		Description: request.Description,
+	}
+}
+
+func domainToResponseMapper(webhook *domainWebhook.Webhook) *ResponseWebhook {
+	return &ResponseWebhook{
+		ID:          webhook.ID,
+		Name:        webhook.Name,
+		URL:         webhook.URL,
+		EventTypes:  webhook.EventTypes,
+		Active:      webhook.Active,
+		Description: webhook.Description,
+		CreatedAt:   domain.CustomTime{Time: webhook.CreatedAt},
+		UpdatedAt:   domain.CustomTime{Time: webhook.UpdatedAt},
+	}
+}
+
+func arrayDomainToResponseMapper(webhooks *[]domainWebhook.Webhook) *[]ResponseWebhook {
+	responses := make([]ResponseWebhook, len(*webhooks))
+	for i := range *webhooks {
+		responses[i] = *domainToResponseMapper(&(*webhooks)[i])
+	}
+	return &responses
+}
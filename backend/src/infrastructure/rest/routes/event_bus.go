@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/gbrayhan/microservices-go/src/infrastructure/di"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/rest/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+func EventBusRouters(router *gin.RouterGroup, appContext *di.ApplicationContext) {
+	controller := appContext.EventBusModule.Controller
+	u := router.Group("/event_bus")
+	u.Use(appContext.MiddlewareProvider.AuthJWTMiddleware())
+	u.Use(middlewares.CasbinMiddleware(appContext.Enforcer))
+	{
+		u.GET("/dead-letters", controller.ListDeadLetters)
+		u.POST("/dead-letters/replay", controller.ReplayDeadLetters)
+	}
+}
@@ -40,4 +40,6 @@ func ApplicationRouter(router *gin.Engine, appContext *di.ApplicationContext) {
 	TaskExecutionLogRouters(v1, appContext)
 	EmailRouters(v1, appContext)
 	CaptchaRoutes(v1, appContext)
+	EventBusRouters(v1, appContext)
+	WebhookRouters(v1, appContext)
 }
@@ -20,9 +20,19 @@ func ScheduledTaskRouters(
 		u.DELETE("/:id", controller.DeleteScheduledTask)
 		u.GET("/search", controller.SearchPaginated)
 		u.GET("/search-property", controller.SearchByProperty)
+		u.GET("/dag", controller.GetTaskDAG)
+		u.GET("/dashboard", controller.GetDashboard)
+		u.GET("/functions", controller.GetFunctions)
+		u.GET("/types", controller.GetTaskTypes)
+		u.POST("/cron/preview", controller.PreviewCron)
 		u.POST("/delete-batch", controller.DeleteScheduledTasks)
 		u.POST("/enable/:id", controller.EnableTaskById)
 		u.POST("/disable/:id", controller.DisableTaskById)
 		u.POST("/reload", controller.ReloadAllTasks)
+		u.POST("/:id/cancel", controller.CancelTaskById)
+		u.POST("/:id/run", controller.RunTaskById)
+		u.POST("/:id/pause", controller.PauseTaskById)
+		u.POST("/:id/resume", controller.ResumeTaskById)
+		u.GET("/:id/stats", controller.GetTaskStats)
 	}
 }
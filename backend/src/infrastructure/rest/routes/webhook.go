@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/gbrayhan/microservices-go/src/infrastructure/di"
+	"github.com/gbrayhan/microservices-go/src/infrastructure/rest/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+func WebhookRouters(router *gin.RouterGroup, appContext *di.ApplicationContext) {
+	controller := appContext.WebhookModule.Controller
+	u := router.Group("/webhook")
+	u.Use(appContext.MiddlewareProvider.AuthJWTMiddleware())
+	u.Use(middlewares.CasbinMiddleware(appContext.Enforcer))
+	{
+		u.POST("", controller.NewWebhook)
+		u.GET("", controller.GetAllWebhooks)
+		u.GET("/:id", controller.GetWebhookByID)
+		u.PUT("/:id", controller.UpdateWebhook)
+		u.DELETE("/:id", controller.DeleteWebhook)
+		u.GET("/:id/deliveries", controller.GetDeliveries)
+		u.POST("/:id/test", controller.SendTestEvent)
+	}
+}
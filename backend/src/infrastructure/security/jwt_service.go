@@ -9,6 +9,7 @@ import (
 
 	domainErrors "github.com/gbrayhan/microservices-go/src/domain/errors"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 )
 
 const (
@@ -21,12 +22,17 @@ type AppToken struct {
 	Token          string    `json:"token"`
 	TokenType      string    `json:"type"`
 	ExpirationTime time.Time `json:"expirationTime"`
+	JTI            string    `json:"jti"`
+	FamilyID       string    `json:"familyId,omitempty"` // 刷新令牌和同一登录签发的访问令牌有
 }
 
+// Claims 每个令牌都带唯一的 jti；刷新令牌轮换时沿用 fid，用于识别同一登录产生的令牌族，
+// 访问令牌也带 fid，登出时据此吊销对应的令牌族
 type Claims struct {
-	ID     int64  `json:"id"`
-	RoleID int64  `json:"role_id"`
-	Type   string `json:"type"`
+	ID       int64  `json:"id"`
+	RoleID   int64  `json:"role_id"`
+	Type     string `json:"type"`
+	FamilyID string `json:"fid,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -42,6 +48,8 @@ type JWTConfig struct {
 // IJWTService defines the interface for JWT operations
 type IJWTService interface {
 	GenerateJWTToken(userID int64, roleID int64, tokenType string) (*AppToken, error)
+	GenerateRefreshToken(userID int64, roleID int64, familyID string) (*AppToken, error)
+	GenerateAccessToken(userID int64, roleID int64, familyID string) (*AppToken, error)
 	GetClaimsAndVerifyToken(tokenString string, tokenType string) (jwt.MapClaims, error)
 }
 
@@ -76,8 +84,30 @@ func loadJWTConfig() JWTConfig {
 	}
 }
 
-// GenerateJWTToken generates a JWT token for the given user ID and type
+// GenerateJWTToken generates a JWT token for the given user ID and type,
+// a refresh token starts a new token family
 func (s *JWTService) GenerateJWTToken(userID int64, roleID int64, tokenType string) (*AppToken, error) {
+	familyID := ""
+	if tokenType == Refresh {
+		familyID = uuid.New().String()
+	}
+	return s.generateToken(userID, roleID, tokenType, familyID)
+}
+
+// GenerateRefreshToken generates a refresh token in the given family, used when rotating
+func (s *JWTService) GenerateRefreshToken(userID int64, roleID int64, familyID string) (*AppToken, error) {
+	if familyID == "" {
+		return nil, errors.New("refresh token family is required")
+	}
+	return s.generateToken(userID, roleID, Refresh, familyID)
+}
+
+// GenerateAccessToken generates an access token bound to the refresh token family it was issued with
+func (s *JWTService) GenerateAccessToken(userID int64, roleID int64, familyID string) (*AppToken, error) {
+	return s.generateToken(userID, roleID, Access, familyID)
+}
+
+func (s *JWTService) generateToken(userID int64, roleID int64, tokenType string, familyID string) (*AppToken, error) {
 	var secretKey string
 	var duration time.Duration
 
@@ -98,9 +128,12 @@ func (s *JWTService) GenerateJWTToken(userID int64, roleID int64, tokenType stri
 	nowTime := time.Now()
 	expirationTokenTime := nowTime.Add(duration)
 
+	jti := uuid.New().String()
 	tokenClaims := &Claims{
-		Type: tokenType,
+		Type:     tokenType,
+		FamilyID: familyID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
 			ExpiresAt: jwt.NewNumericDate(expirationTokenTime),
 		},
 	}
@@ -122,6 +155,8 @@ func (s *JWTService) GenerateJWTToken(userID int64, roleID int64, tokenType stri
 		Token:          tokenStr,
 		TokenType:      tokenType,
 		ExpirationTime: expirationTokenTime,
+		JTI:            jti,
+		FamilyID:       familyID,
 	}, nil
 }
 
@@ -0,0 +1,45 @@
+package security
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefreshTokenCarriesJTIAndFamily(t *testing.T) {
+	service := NewJWTServiceWithConfig(JWTConfig{
+		AccessSecret:  "access",
+		RefreshSecret: "refresh",
+		AccessTime:    1,
+		RefreshTime:   1,
+	})
+
+	first, err := service.GenerateJWTToken(1, 2, Refresh)
+	require.NoError(t, err)
+	require.NotEmpty(t, first.FamilyID)
+	rotated, err := service.GenerateRefreshToken(1, 2, first.FamilyID)
+	require.NoError(t, err)
+	assert.Equal(t, first.FamilyID, rotated.FamilyID)
+	assert.NotEqual(t, first.JTI, rotated.JTI)
+
+	claims, err := service.GetClaimsAndVerifyToken(rotated.Token, Refresh)
+	require.NoError(t, err)
+	assert.Equal(t, rotated.JTI, claims["jti"])
+	assert.Equal(t, first.FamilyID, claims["fid"])
+	assert.Equal(t, float64(1), claims["id"])
+
+	access, err := service.GenerateJWTToken(1, 2, Access)
+	require.NoError(t, err)
+	assert.Empty(t, access.FamilyID)
+	assert.NotEmpty(t, access.JTI)
+
+	bound, err := service.GenerateAccessToken(1, 2, first.FamilyID)
+	require.NoError(t, err)
+	claims, err = service.GetClaimsAndVerifyToken(bound.Token, Access)
+	require.NoError(t, err)
+	assert.Equal(t, first.FamilyID, claims["fid"])
+
+	_, err = service.GenerateRefreshToken(1, 2, "")
+	assert.Error(t, err)
+}